@@ -0,0 +1,163 @@
+package content
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	feedbin "github.com/yourusername/feedbin-api/aider-claude-3.7"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"script", `<p>Hi<script>alert(1)</script></p>`, `<p>Hi</p>`},
+		{"event handler", `<img src="a.png" onerror="alert(1)">`, `<img src="https://example.com/posts/a.png">`},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, `x`},
+		{"relative link", `<a href="../about">about</a>`, `<a href="https://example.com/about">about</a>`},
+		{"utm params", `<a href="/p?utm_source=rss&amp;id=3&amp;utm_medium=feed">p</a>`, `<a href="https://example.com/p?id=3">p</a>`},
+		{"srcset", `<img srcset="a.png 1x, /b.png 2x, javascript:x 3x">`, `<img srcset="https://example.com/posts/a.png 1x, https://example.com/b.png 2x">`},
+		{"tracking pixel", `<p>Text<img src="https://example.com/p.gif" width="1" height="1"></p>`, `<p>Text</p>`},
+		{"feedburner", `<a href="https://feeds.feedburner.com/~ff/x?a=1"><img src="https://feeds.feedburner.com/~ff/x?d=1"></a>`, ``},
+		{"unknown element", `<font color="red">red</font>`, `red`},
+		{"style and class", `<div style="color:red" class="x">a</div>`, `<div>a</div>`},
+		{"unclosed", `<p>one<p>two <b>bold`, `<p>one</p><p>two <b>bold</b></p>`},
+		{"whitespace", "<p>a</p>\n\n  <p>b   c</p>", "<p>a</p>\n<p>b c</p>"},
+		{"escaping", `a < b & "c"`, `a &lt; b &amp; &#34;c&#34;`},
+	}
+
+	for _, tt := range tests {
+		got := Sanitize(tt.src, "https://example.com/posts/1", nil)
+		if got != tt.expected {
+			t.Errorf("%s: Expected '%s', got '%s'", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestSanitizeOptions(t *testing.T) {
+	opts := &Options{
+		Policy:             NewPolicy().AllowAttributes("a", "href").AllowAttributes("img", "src"),
+		KeepTrackingPixels: true,
+		KeepTrackingParams: true,
+	}
+
+	got := Sanitize(`<p><a href="/x?utm_source=a">x</a><img src="p.gif" width="1" height="1"></p>`, "https://example.com/", opts)
+	expected := `<a href="https://example.com/x?utm_source=a">x</a><img src="https://example.com/p.gif">`
+	if got != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestStripTrackingParams(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/a?utm_source=rss&b=2&UTM_Campaign=x#top": "https://example.com/a?b=2#top",
+		"https://example.com/a?utm_source=rss":                        "https://example.com/a",
+		"https://example.com/a?z=1&a=2":                               "https://example.com/a?z=1&a=2",
+		"https://example.com/a":                                       "https://example.com/a",
+	}
+
+	for input, expected := range tests {
+		if got := StripTrackingParams(input); got != expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", expected, input, got)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	src := `<h2>Title <em>here</em></h2>
+<p>Some <b> bold </b>text with a <a href="/x">link</a>.<br>Next line</p>
+<ul><li>one<li>two<ul><li>nested</ul></ul>
+<ol start="3"><li>three</ol>
+<blockquote><p>quote *not emphasis*</p></blockquote>
+<pre><code>if a &lt; b {
+}</code></pre>
+<table><tr><th>A<th>B<tr><td>1<td>2</table>
+<p>1. not a list, <code>a*b</code></p>`
+
+	expected := "## Title *here*\n\n" +
+		"Some **bold** text with a [link](https://example.com/x).\\\nNext line\n\n" +
+		"- one\n- two\n  - nested\n\n" +
+		"3. three\n\n" +
+		"> quote \\*not emphasis\\*\n\n" +
+		"```\nif a < b {\n}\n```\n\n" +
+		"| A | B |\n| --- | --- |\n| 1 | 2 |\n\n" +
+		"1\\. not a list, `a*b`"
+
+	doc := ProcessHTML(src, "https://example.com/", nil)
+	if doc.Markdown != expected {
+		t.Errorf("Expected Markdown:\n%s\ngot:\n%s", expected, doc.Markdown)
+	}
+}
+
+func TestText(t *testing.T) {
+	src := `<p>First   paragraph<br>with a break.</p><ul><li>one</li><li>two</li></ul><blockquote>Quoted</blockquote><img src="a.png" alt="image">`
+	expected := "First paragraph\nwith a break.\n\n- one\n- two\n\n  Quoted"
+
+	doc := ProcessHTML(src, "", nil)
+	if doc.Text != expected {
+		t.Errorf("Expected text:\n%s\ngot:\n%s", expected, doc.Text)
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	tests := map[string]int{
+		"":                          0,
+		"Hello, world!":             2,
+		"It's a well-known fact":    4,
+		"3.14 is pi":                3,
+		"中文字符":                      4,
+		"  spaced\n\nout   words  ": 3,
+	}
+
+	for input, expected := range tests {
+		if got := WordCount(input); got != expected {
+			t.Errorf("Expected %d words in '%s', got %d", expected, input, got)
+		}
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words    int
+		wpm      int
+		expected time.Duration
+	}{
+		{0, 0, 0},
+		{1, 0, time.Minute},
+		{DefaultWordsPerMinute, 0, time.Minute},
+		{DefaultWordsPerMinute + 1, 0, 2 * time.Minute},
+		{500, 100, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := ReadingTime(tt.words, tt.wpm); got != tt.expected {
+			t.Errorf("Expected %v for %d words at %d wpm, got %v", tt.expected, tt.words, tt.wpm, got)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	entry := feedbin.Entry{
+		URL:     "https://example.com/posts/1",
+		Content: `<p>` + strings.Repeat("word ", 300) + `<img src="cover.jpg"></p>`,
+	}
+
+	doc := Process(entry, nil)
+	if !strings.Contains(doc.HTML, `<img src="https://example.com/posts/cover.jpg">`) {
+		t.Errorf("Expected image resolved against the entry URL, got '%s'", doc.HTML)
+	}
+	if doc.WordCount != 300 {
+		t.Errorf("Expected 300 words, got %d", doc.WordCount)
+	}
+	if doc.ReadingTime != 2*time.Minute {
+		t.Errorf("Expected reading time of 2m, got %v", doc.ReadingTime)
+	}
+
+	summary := Process(feedbin.Entry{Summary: "a <b> summary"}, nil)
+	if summary.HTML != "a &lt;b&gt; summary" || summary.Text != "a <b> summary" {
+		t.Errorf("Expected the summary as text, got '%s' and '%s'", summary.HTML, summary.Text)
+	}
+}
@@ -0,0 +1,338 @@
+package content
+
+import (
+	"html"
+	"strings"
+)
+
+// rootName is the name of the node holding a parsed fragment
+const rootName = "#root"
+
+// node is an element or, when name is empty, a text node
+type node struct {
+	name     string
+	attrs    []attribute
+	text     string
+	parent   *node
+	children []*node
+}
+
+// attribute is an element attribute with its unescaped value
+type attribute struct {
+	name  string
+	value string
+}
+
+// attr returns the value of an attribute, or "" if it is not set
+func (n *node) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.name == name {
+			return a.value
+		}
+	}
+	return ""
+}
+
+// appendChild adds c as the last child of n, merging adjacent text nodes
+func (n *node) appendChild(c *node) {
+	if last := len(n.children) - 1; c.name == "" && last >= 0 && n.children[last].name == "" {
+		n.children[last].text += c.text
+		return
+	}
+	c.parent = n
+	n.children = append(n.children, c)
+}
+
+// voidElements have no content and no end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// blockElements start a new block and close an open paragraph
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"center": true, "details": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "header": true, "hr": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"summary": true, "table": true, "ul": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// impliedEnd lists, for elements whose start tag closes an open element,
+// the elements closed and the elements that stop the search
+var impliedEnd = map[string]struct{ closes, scope []string }{
+	"li":     {[]string{"li"}, []string{"ul", "ol", "table"}},
+	"dt":     {[]string{"dt", "dd"}, []string{"dl", "table"}},
+	"dd":     {[]string{"dt", "dd"}, []string{"dl", "table"}},
+	"td":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"tr":     {[]string{"tr"}, []string{"table"}},
+	"thead":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"tbody":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"tfoot":  {[]string{"thead", "tbody", "tfoot"}, []string{"table"}},
+	"option": {[]string{"option"}, []string{"select"}},
+}
+
+// paragraphScope stops the search for a paragraph closed by a block
+var paragraphScope = []string{"table", "td", "th", "caption", "button", "object", "template"}
+
+// parse builds a tree from an HTML fragment. Like a browser it never fails:
+// stray end tags are ignored, unclosed elements are closed at the end and
+// the usual optional end tags (p, li, td, ...) are implied.
+func parse(src string) *node {
+	root := &node{name: rootName}
+	stack := []*node{root}
+
+	for _, tok := range tokenize(src) {
+		switch tok.kind {
+		case tokenText:
+			stack[len(stack)-1].appendChild(&node{text: tok.text})
+
+		case tokenStart:
+			if blockElements[tok.name] {
+				stack = closeOpen(stack, []string{"p"}, paragraphScope)
+			}
+			if end, ok := impliedEnd[tok.name]; ok {
+				stack = closeOpen(stack, end.closes, end.scope)
+			}
+
+			el := &node{name: tok.name, attrs: tok.attrs}
+			stack[len(stack)-1].appendChild(el)
+			if !voidElements[tok.name] && !tok.selfClosing {
+				stack = append(stack, el)
+			}
+
+		case tokenEnd:
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == tok.name {
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+	}
+
+	return root
+}
+
+// closeOpen pops the innermost open element named in closes, unless an
+// element named in scope is found first
+func closeOpen(stack []*node, closes, scope []string) []*node {
+	for i := len(stack) - 1; i > 0; i-- {
+		name := stack[i].name
+		if contains(closes, name) {
+			return stack[:i]
+		}
+		if contains(scope, name) {
+			break
+		}
+	}
+	return stack
+}
+
+// contains reports whether names contains name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenKind identifies an HTML token
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenStart
+	tokenEnd
+)
+
+// token is a text run or a tag. Names are lowercase and text and attribute
+// values are unescaped.
+type token struct {
+	kind        tokenKind
+	name        string
+	attrs       []attribute
+	selfClosing bool
+	text        string
+}
+
+// rawTextElements contain text that is not markup
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+	"xmp": true, "iframe": true, "noembed": true, "noframes": true,
+	"noscript": true,
+}
+
+// tokenize splits HTML into tokens. It is lenient the way browsers are:
+// unquoted attributes, unclosed and stray tags are all accepted, and a "<"
+// that does not start a tag is text. Comments, doctypes and processing
+// instructions are dropped.
+func tokenize(s string) []token {
+	var tokens []token
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, token{kind: tokenText, text: html.UnescapeString(t)})
+		}
+	}
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			text(s)
+			break
+		}
+		text(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[4+end+3:]
+			continue
+		case strings.HasPrefix(s, "<![CDATA["):
+			end := strings.Index(s, "]]>")
+			if end < 0 {
+				tokens = append(tokens, token{kind: tokenText, text: s[9:]})
+				return tokens
+			}
+			tokens = append(tokens, token{kind: tokenText, text: s[9:end]})
+			s = s[end+3:]
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		closing := strings.HasPrefix(s, "</")
+		nameStart := 1
+		if closing {
+			nameStart = 2
+		}
+		nameEnd := nameStart
+		for nameEnd < len(s) && isNameByte(s[nameEnd]) {
+			nameEnd++
+		}
+		if nameEnd == nameStart || !isLetter(s[nameStart]) {
+			text("<")
+			s = s[1:]
+			continue
+		}
+		name := lowerASCII(s[nameStart:nameEnd])
+
+		attrs, selfClosing, rest := parseAttrs(s[nameEnd:])
+		s = rest
+		if closing {
+			tokens = append(tokens, token{kind: tokenEnd, name: name})
+			continue
+		}
+		tokens = append(tokens, token{kind: tokenStart, name: name, attrs: attrs, selfClosing: selfClosing})
+
+		if rawTextElements[name] && !selfClosing {
+			end := indexFold(s, "</"+name)
+			if end < 0 {
+				end = len(s)
+			}
+			text(s[:end])
+			s = s[end:]
+		}
+	}
+	return tokens
+}
+
+// parseAttrs reads attributes up to the end of a tag and returns them in
+// document order, whether the tag ends with "/>", and the input following
+// the tag
+func parseAttrs(s string) ([]attribute, bool, string) {
+	var attrs []attribute
+	for {
+		trimmed := strings.TrimLeft(s, " \t\r\n\f")
+		selfClosing := strings.HasPrefix(trimmed, "/")
+		s = strings.TrimLeft(trimmed, " \t\r\n\f/")
+		if s == "" {
+			return attrs, false, s
+		}
+		if s[0] == '>' {
+			return attrs, selfClosing, s[1:]
+		}
+
+		end := strings.IndexAny(s, " \t\r\n\f/=>")
+		if end < 0 {
+			end = len(s)
+		}
+		name := lowerASCII(s[:end])
+		s = strings.TrimLeft(s[end:], " \t\r\n\f")
+
+		value := ""
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\r\n\f")
+			if s != "" && (s[0] == '"' || s[0] == '\'') {
+				quote := s[0]
+				end := strings.IndexByte(s[1:], quote)
+				if end < 0 {
+					value, s = s[1:], ""
+				} else {
+					value, s = s[1:1+end], s[2+end:]
+				}
+			} else {
+				end := strings.IndexAny(s, " \t\r\n\f>")
+				if end < 0 {
+					end = len(s)
+				}
+				value, s = s[:end], s[end:]
+			}
+		}
+
+		duplicate := false
+		for _, a := range attrs {
+			duplicate = duplicate || a.name == name
+		}
+		if name != "" && !duplicate {
+			attrs = append(attrs, attribute{name: name, value: html.UnescapeString(value)})
+		}
+	}
+}
+
+// indexFold is strings.Index ignoring ASCII case
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lowerASCII lowercases the ASCII letters of s, leaving other bytes alone
+// so offsets into s stay valid
+func lowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isNameByte reports whether c can be part of a tag name
+func isNameByte(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
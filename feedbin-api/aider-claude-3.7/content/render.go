@@ -0,0 +1,537 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// renderHTML serializes the children of n
+func renderHTML(n *node) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		writeHTML(&b, c)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// writeHTML serializes n and its children
+func writeHTML(b *strings.Builder, n *node) {
+	if n.name == "" {
+		b.WriteString(html.EscapeString(n.text))
+		return
+	}
+
+	b.WriteString("<" + n.name)
+	for _, a := range n.attrs {
+		b.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+	}
+	b.WriteString(">")
+	if voidElements[n.name] {
+		return
+	}
+	for _, c := range n.children {
+		writeHTML(b, c)
+	}
+	b.WriteString("</" + n.name + ">")
+}
+
+// container is an open blockquote or list item that prefixes its lines
+type container struct {
+	first, rest string
+	started     bool
+}
+
+// list is an open ul or ol
+type list struct {
+	ordered bool
+	next    int
+	items   int
+}
+
+// textRenderer renders a sanitized tree as plain text or Markdown
+type textRenderer struct {
+	markdown bool
+
+	out       strings.Builder
+	inline    strings.Builder
+	tight     bool
+	pending   string
+	lineBreak bool
+	heading   int
+	pre       int
+	preText   strings.Builder
+	lists     []list
+	container []*container
+
+	// Table cells are collected per row
+	cells   []string
+	inCell  bool
+	rows    int
+	columns int
+}
+
+// renderText renders the children of n as plain text
+func renderText(n *node) string {
+	r := &textRenderer{}
+	r.children(n)
+	r.flush()
+	return r.out.String()
+}
+
+// renderMarkdown renders the children of n as CommonMark
+func renderMarkdown(n *node) string {
+	r := &textRenderer{markdown: true}
+	r.children(n)
+	r.flush()
+	return r.out.String()
+}
+
+// children renders the children of n
+func (r *textRenderer) children(n *node) {
+	for _, c := range n.children {
+		r.node(c)
+	}
+}
+
+// node renders n and its children
+func (r *textRenderer) node(n *node) {
+	if n.name == "" {
+		r.text(n.text)
+		return
+	}
+
+	switch n.name {
+	case "br":
+		if r.pre > 0 {
+			r.preText.WriteString("\n")
+		} else if r.inline.Len() > 0 {
+			// The break is written before the next content, so breaks at
+			// the end of a paragraph vanish
+			r.lineBreak = true
+		}
+		return
+
+	case "hr":
+		r.flush()
+		if r.markdown && !r.inCell {
+			r.block("---", false)
+		}
+		return
+
+	case "img":
+		if r.markdown && r.pre == 0 {
+			alt := escapeMarkdown(strings.Join(strings.Fields(n.attr("alt")), " "))
+			if src := n.attr("src"); src != "" {
+				r.raw("![" + alt + "](" + markdownURL(src) + ")")
+			}
+		}
+		return
+
+	case "pre":
+		r.flush()
+		r.pre++
+		r.children(n)
+		r.pre--
+		if r.pre == 0 {
+			r.writePre()
+		}
+		return
+
+	case "code", "kbd", "samp":
+		if r.pre > 0 || !r.markdown {
+			r.children(n)
+			return
+		}
+		// Code is rendered as plain text, without escapes or markup
+		var inner textRenderer
+		inner.children(n)
+		r.raw(codeSpan(inner.inline.String()))
+		return
+
+	case "ul", "ol":
+		r.flush()
+		l := list{ordered: n.name == "ol", next: 1}
+		if start, err := strconv.Atoi(n.attr("start")); err == nil {
+			l.next = start
+		}
+		r.lists = append(r.lists, l)
+		r.children(n)
+		r.flush()
+		r.lists = r.lists[:len(r.lists)-1]
+		r.tight = false
+		return
+
+	case "li":
+		r.flush()
+		marker := "- "
+		if len(r.lists) > 0 {
+			l := &r.lists[len(r.lists)-1]
+			if l.ordered {
+				marker = fmt.Sprintf("%d. ", l.next)
+				l.next++
+			}
+			// Nested lists continue the item they are in
+			r.tight = l.items > 0 || len(r.lists) > 1
+			l.items++
+		}
+		r.container = append(r.container, &container{first: marker, rest: strings.Repeat(" ", len(marker))})
+		r.children(n)
+		r.flush()
+		r.container = r.container[:len(r.container)-1]
+		return
+
+	case "blockquote":
+		r.flush()
+		prefix := "> "
+		if !r.markdown {
+			prefix = "  "
+		}
+		r.container = append(r.container, &container{first: prefix, rest: prefix})
+		r.children(n)
+		r.flush()
+		r.container = r.container[:len(r.container)-1]
+		return
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.flush()
+		r.heading = int(n.name[1] - '0')
+		r.children(n)
+		r.flush()
+		r.heading = 0
+		return
+
+	case "table":
+		r.flush()
+		cells, inCell, rows, columns := r.cells, r.inCell, r.rows, r.columns
+		r.cells, r.inCell, r.rows, r.columns = nil, false, 0, 0
+		r.children(n)
+		r.flush()
+		r.cells, r.inCell, r.rows, r.columns = cells, inCell, rows, columns
+		return
+
+	case "tr":
+		r.flush()
+		r.cells = nil
+		r.children(n)
+		r.flush()
+		r.endRow()
+		return
+
+	case "td", "th":
+		r.flush()
+		r.inCell = true
+		r.cells = append(r.cells, "")
+		r.children(n)
+		r.flush()
+		r.inCell = false
+		return
+	}
+
+	if r.markdown && r.pre == 0 {
+		switch n.name {
+		case "a":
+			if href := n.attr("href"); href != "" {
+				r.pending += "["
+				r.children(n)
+				r.closeLink(href)
+				return
+			}
+		case "b", "strong":
+			r.emphasis(n, "**")
+			return
+		case "i", "em", "cite", "dfn", "var":
+			r.emphasis(n, "*")
+			return
+		case "s", "strike", "del":
+			r.emphasis(n, "~~")
+			return
+		}
+	}
+
+	if blockElements[n.name] {
+		r.flush()
+		r.children(n)
+		r.flush()
+		return
+	}
+	r.children(n)
+}
+
+// emphasis renders n between markers
+func (r *textRenderer) emphasis(n *node, marker string) {
+	r.pending += marker
+	r.children(n)
+	if strings.HasSuffix(r.pending, marker) {
+		// Nothing was written since the opening marker
+		r.pending = strings.TrimSuffix(r.pending, marker)
+		return
+	}
+	r.closeMarker(marker)
+}
+
+// closeLink ends a Markdown link opened with "["
+func (r *textRenderer) closeLink(href string) {
+	if strings.HasSuffix(r.pending, "[") {
+		r.pending = strings.TrimSuffix(r.pending, "[")
+		r.raw("<" + href + ">")
+		return
+	}
+	r.closeMarker("](" + markdownURL(href) + ")")
+}
+
+// closeMarker writes a closing marker before trailing spaces
+func (r *textRenderer) closeMarker(marker string) {
+	s := r.inline.String()
+	trimmed := strings.TrimRight(s, " ")
+	r.inline.Reset()
+	r.inline.WriteString(trimmed + marker + s[len(trimmed):])
+}
+
+// text adds a text node
+func (r *textRenderer) text(s string) {
+	if r.pre > 0 {
+		r.preText.WriteString(s)
+		return
+	}
+
+	// Collapse whitespace the way a browser does, keeping non-breaking
+	// spaces
+	var b strings.Builder
+	space := r.inline.Len() == 0 || r.lineBreak || strings.HasSuffix(r.inline.String(), " ")
+	for _, c := range s {
+		if unicode.IsSpace(c) && c != '\u00a0' {
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+			continue
+		}
+		b.WriteRune(c)
+		space = false
+	}
+	collapsed := b.String()
+	if collapsed == "" {
+		return
+	}
+
+	// Opening markers go after leading space
+	if r.pending != "" && strings.HasPrefix(collapsed, " ") {
+		r.inline.WriteByte(' ')
+		collapsed = collapsed[1:]
+	}
+	if r.markdown {
+		collapsed = escapeMarkdown(collapsed)
+	}
+	r.raw(collapsed)
+}
+
+// raw adds inline content as is, after any pending line break and opening
+// markers
+func (r *textRenderer) raw(s string) {
+	if s == "" {
+		return
+	}
+	if r.lineBreak {
+		r.lineBreak = false
+		switch {
+		case r.inCell:
+			r.inline.WriteString(" ")
+		case r.markdown:
+			r.inline.WriteString("\\\n")
+		default:
+			r.inline.WriteString("\n")
+		}
+	}
+	r.inline.WriteString(r.pending + s)
+	r.pending = ""
+}
+
+// flush ends the current paragraph
+func (r *textRenderer) flush() {
+	r.pending = ""
+	r.lineBreak = false
+	text := strings.TrimSpace(r.inline.String())
+	r.inline.Reset()
+	if text == "" {
+		return
+	}
+
+	if r.inCell {
+		if len(r.cells) == 0 {
+			r.cells = append(r.cells, "")
+		}
+		cell := &r.cells[len(r.cells)-1]
+		if *cell != "" {
+			text = *cell + " " + text
+		}
+		*cell = strings.ReplaceAll(text, "\n", " ")
+		return
+	}
+
+	if r.markdown {
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = escapeLineStart(strings.TrimSpace(line))
+		}
+		text = strings.Join(lines, "\n")
+		if r.heading > 0 {
+			text = strings.Repeat("#", r.heading) + " " + strings.ReplaceAll(text, "\\\n", " ")
+		}
+	}
+	r.block(text, r.tight)
+	r.tight = false
+}
+
+// endRow writes a table row
+func (r *textRenderer) endRow() {
+	cells := r.cells
+	r.cells = nil
+	if len(cells) == 0 {
+		return
+	}
+
+	if !r.markdown {
+		r.block(strings.Join(cells, " | "), r.rows > 0)
+		r.rows++
+		return
+	}
+
+	if r.rows == 0 {
+		r.columns = len(cells)
+	}
+	for len(cells) < r.columns {
+		cells = append(cells, "")
+	}
+	for i, cell := range cells {
+		cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	row := "| " + strings.Join(cells, " | ") + " |"
+	if r.rows == 0 {
+		row += "\n|" + strings.Repeat(" --- |", len(cells))
+	}
+	r.block(row, r.rows > 0)
+	r.rows++
+}
+
+// writePre writes the collected preformatted text
+func (r *textRenderer) writePre() {
+	text := strings.Trim(r.preText.String(), "\n")
+	r.preText.Reset()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if r.inCell {
+		if r.markdown {
+			text = codeSpan(text)
+		}
+		r.raw(text)
+		return
+	}
+	if r.markdown {
+		fence := "```"
+		for strings.Contains(text, fence) {
+			fence += "`"
+		}
+		text = fence + "\n" + text + "\n" + fence
+	}
+	r.block(text, false)
+}
+
+// block writes a block of lines, prefixed by the open containers and
+// separated from the previous block by a blank line unless tight
+func (r *textRenderer) block(text string, tight bool) {
+	var separator, first, rest strings.Builder
+	for _, c := range r.container {
+		if c.started {
+			separator.WriteString(c.rest)
+		}
+	}
+	for _, c := range r.container {
+		if c.started {
+			first.WriteString(c.rest)
+		} else {
+			first.WriteString(c.first)
+			c.started = true
+		}
+		rest.WriteString(c.rest)
+	}
+
+	if r.out.Len() > 0 {
+		r.out.WriteString("\n")
+		if !tight {
+			r.out.WriteString(strings.TrimRight(separator.String(), " ") + "\n")
+		}
+	}
+	for i, line := range strings.Split(text, "\n") {
+		prefix := rest.String()
+		if i == 0 {
+			prefix = first.String()
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		if i > 0 {
+			r.out.WriteString("\n")
+		}
+		r.out.WriteString(prefix + line)
+	}
+}
+
+// markdownEscaper escapes characters that start Markdown inline markup
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `~`, `\~`,
+)
+
+// escapeMarkdown escapes text for use in Markdown
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeLineStart escapes characters that would start a Markdown block at
+// the beginning of a line
+func escapeLineStart(line string) string {
+	if line == "" {
+		return line
+	}
+	switch line[0] {
+	case '#', '-', '+', '=', '|':
+		return `\` + line
+	}
+
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return line
+}
+
+// markdownURLEscaper encodes characters that end a link destination
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+// markdownURL makes a URL safe to use as a Markdown link destination
+func markdownURL(u string) string {
+	return markdownURLEscaper.Replace(u)
+}
+
+// codeSpan wraps text in enough backticks to contain it
+func codeSpan(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\n", " "))
+	if text == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
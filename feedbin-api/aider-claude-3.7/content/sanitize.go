@@ -0,0 +1,422 @@
+package content
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Policy is an allow-list of elements and attributes. Elements that are
+// not allowed are removed but their content is kept, except for elements
+// like script and style that are dropped along with it. Attributes that are
+// not allowed are removed, and event handler attributes are never allowed.
+type Policy struct {
+	elements map[string]map[string]bool
+}
+
+// globalAttributes are allowed on every allowed element by DefaultPolicy
+var globalAttributes = []string{"title", "lang", "dir"}
+
+// droppedElements are removed with their content unless a policy allows them
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "svg": true, "math": true, "head": true,
+	"title": true, "meta": true, "link": true, "base": true, "form": true,
+	"input": true, "button": true, "select": true, "option": true,
+	"textarea": true, "xmp": true, "noembed": true, "noframes": true,
+}
+
+// keepEmpty are elements kept when they have no content
+var keepEmpty = map[string]bool{"td": true, "th": true, "video": true, "audio": true}
+
+// NewPolicy returns a policy that allows no elements, so only text is kept.
+func NewPolicy() *Policy {
+	return &Policy{elements: make(map[string]map[string]bool)}
+}
+
+// DefaultPolicy returns the policy used when Options.Policy is nil. It keeps
+// text formatting, headings, lists, quotes, code, tables, links, images,
+// audio and video, and drops scripts, styles, frames, embeds and forms.
+func DefaultPolicy() *Policy {
+	p := NewPolicy()
+	p.AllowElements(
+		"p", "div", "span", "br", "hr", "wbr",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+		"article", "section", "header", "footer", "aside", "main", "address",
+		"b", "strong", "i", "em", "u", "s", "strike", "small", "mark",
+		"sub", "sup", "cite", "dfn", "kbd", "samp", "var", "code", "pre",
+		"ul", "dl", "dt", "dd", "figure", "figcaption", "picture",
+		"details", "summary", "ruby", "rt", "rp", "bdi",
+		"table", "caption", "thead", "tbody", "tfoot", "tr", "colgroup",
+	)
+	p.AllowAttributes("a", "href")
+	p.AllowAttributes("img", "src", "srcset", "sizes", "alt", "width", "height")
+	p.AllowAttributes("source", "src", "srcset", "sizes", "type", "media")
+	p.AllowAttributes("video", "src", "poster", "controls", "width", "height")
+	p.AllowAttributes("audio", "src", "controls")
+	p.AllowAttributes("track", "src", "kind", "srclang", "label")
+	p.AllowAttributes("blockquote", "cite")
+	p.AllowAttributes("q", "cite")
+	p.AllowAttributes("del", "cite", "datetime")
+	p.AllowAttributes("ins", "cite", "datetime")
+	p.AllowAttributes("time", "datetime")
+	p.AllowAttributes("abbr")
+	p.AllowAttributes("bdo", "dir")
+	p.AllowAttributes("ol", "start", "reversed", "type")
+	p.AllowAttributes("li", "value")
+	p.AllowAttributes("td", "colspan", "rowspan")
+	p.AllowAttributes("th", "colspan", "rowspan", "scope")
+	p.AllowAttributes("col", "span")
+
+	for name, attrs := range p.elements {
+		for _, attr := range globalAttributes {
+			if name != "br" && name != "wbr" {
+				attrs[attr] = true
+			}
+		}
+	}
+	return p
+}
+
+// AllowElements allows elements without attributes and returns p.
+func (p *Policy) AllowElements(names ...string) *Policy {
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if p.elements[name] == nil {
+			p.elements[name] = make(map[string]bool)
+		}
+	}
+	return p
+}
+
+// AllowAttributes allows an element with the given attributes and returns
+// p. URL attributes (href, src, srcset, poster and cite) are resolved
+// against the base URL and only kept for http, https and, for href, mailto.
+func (p *Policy) AllowAttributes(element string, names ...string) *Policy {
+	element = strings.ToLower(element)
+	p.AllowElements(element)
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "on") {
+			p.elements[element][name] = true
+		}
+	}
+	return p
+}
+
+// sanitizer applies a policy to a parsed fragment
+type sanitizer struct {
+	policy *Policy
+	base   *url.URL
+
+	keepTrackingPixels bool
+	keepTrackingParams bool
+}
+
+// sanitize rewrites the children of n in place
+func (s *sanitizer) sanitize(n *node) {
+	var children []*node
+	for _, c := range n.children {
+		if c.name == "" {
+			children = append(children, c)
+			continue
+		}
+
+		allowed, ok := s.policy.elements[c.name]
+		if !ok {
+			if !droppedElements[c.name] {
+				// Keep the content of elements that are not allowed
+				s.sanitize(c)
+				for _, grandchild := range c.children {
+					grandchild.parent = n
+				}
+				children = append(children, c.children...)
+			}
+			continue
+		}
+
+		if c.name == "img" && !s.keepTrackingPixels && isTrackingPixel(c) {
+			continue
+		}
+		c.attrs = s.cleanAttrs(c, allowed)
+		s.sanitize(c)
+
+		if !s.keep(c) {
+			continue
+		}
+		if c.name == "a" && c.attr("href") == "" {
+			children = append(children, c.children...)
+			continue
+		}
+		children = append(children, c)
+	}
+
+	// Unwrapping can leave text nodes next to each other
+	n.children = nil
+	for _, c := range children {
+		n.appendChild(c)
+	}
+
+	// Runs of whitespace shrink to a single space or newline
+	if inPre(n) {
+		return
+	}
+	for _, c := range n.children {
+		if c.name == "" {
+			c.text = collapseSpace(c.text)
+		}
+	}
+}
+
+// collapseSpace replaces each run of whitespace with a newline if it
+// contains one, or a space otherwise. Non-breaking spaces are kept.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	run := ""
+	for _, c := range s {
+		if unicode.IsSpace(c) && c != '\u00a0' {
+			if run != "\n" {
+				run = " "
+				if c == '\n' {
+					run = "\n"
+				}
+			}
+			continue
+		}
+		b.WriteString(run)
+		b.WriteRune(c)
+		run = ""
+	}
+	b.WriteString(run)
+	return b.String()
+}
+
+// inPre reports whether n is a pre element or inside one
+func inPre(n *node) bool {
+	for ; n != nil; n = n.parent {
+		if n.name == "pre" {
+			return true
+		}
+	}
+	return false
+}
+
+// keep reports whether a sanitized element is worth keeping
+func (s *sanitizer) keep(n *node) bool {
+	switch n.name {
+	case "img":
+		return n.attr("src") != "" || n.attr("srcset") != ""
+	case "source", "track":
+		return n.attr("src") != "" || n.attr("srcset") != ""
+	case "video", "audio":
+		return n.attr("src") != "" || len(n.children) > 0
+	}
+	if voidElements[n.name] || keepEmpty[n.name] {
+		return true
+	}
+	return len(n.children) > 0
+}
+
+// cleanAttrs returns the attributes of n that the policy allows, with URLs
+// resolved and checked
+func (s *sanitizer) cleanAttrs(n *node, allowed map[string]bool) []attribute {
+	var attrs []attribute
+	for _, a := range n.attrs {
+		if !allowed[a.name] || strings.HasPrefix(a.name, "on") {
+			continue
+		}
+
+		value := a.value
+		switch a.name {
+		case "href", "src", "poster", "cite":
+			var ok bool
+			value, ok = s.cleanURL(value, a.name == "href")
+			if !ok {
+				continue
+			}
+		case "srcset":
+			value = s.cleanSrcset(value)
+			if value == "" {
+				continue
+			}
+		}
+		attrs = append(attrs, attribute{name: a.name, value: value})
+	}
+	return attrs
+}
+
+// cleanURL resolves a URL against the base and strips tracking parameters.
+// It reports false for URLs with schemes other than http and https, and
+// mailto for links.
+func (s *sanitizer) cleanURL(raw string, link bool) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if s.base != nil {
+		u = s.base.ResolveReference(u)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "":
+	case "mailto":
+		if !link {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	if !s.keepTrackingParams {
+		u.RawQuery = stripTrackingQuery(u.RawQuery)
+		u.ForceQuery = false
+	}
+	return u.String(), true
+}
+
+// cleanSrcset cleans each candidate URL of a srcset attribute
+func (s *sanitizer) cleanSrcset(srcset string) string {
+	var candidates []string
+	rest := srcset
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n\f,")
+		if rest == "" {
+			break
+		}
+
+		end := strings.IndexAny(rest, " \t\r\n\f")
+		if end < 0 {
+			end = len(rest)
+		}
+		raw, descriptor := rest[:end], ""
+		rest = rest[end:]
+
+		// A URL ending in a comma has no descriptor
+		if strings.HasSuffix(raw, ",") {
+			raw = strings.TrimRight(raw, ",")
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			descriptor, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+
+		if u, ok := s.cleanURL(raw, false); ok {
+			if descriptor != "" {
+				u += " " + descriptor
+			}
+			candidates = append(candidates, u)
+		}
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// trackerHosts serve tracking pixels. Subdomains match too.
+var trackerHosts = []string{
+	"pixel.wp.com",
+	"stats.wordpress.com",
+	"google-analytics.com",
+	"doubleclick.net",
+	"scorecardresearch.com",
+	"quantserve.com",
+	"feedblitz.com",
+	"pixel.mathtag.com",
+	"analytics.twitter.com",
+	"ct.pinterest.com",
+	"pixel.facebook.com",
+}
+
+// trackerPaths are URL prefixes of feed proxies' tracking images and
+// sharing buttons
+var trackerPaths = []string{
+	"feeds.feedburner.com/~r/",
+	"feeds.feedburner.com/~ff/",
+	"feedproxy.google.com/~r/",
+	"feedproxy.google.com/~ff/",
+	"www.facebook.com/tr",
+}
+
+// isTrackingPixel reports whether an image is a tracking pixel: an image
+// of at most one pixel, a hidden image, or one served by a known tracker
+func isTrackingPixel(img *node) bool {
+	width, widthOK := pixels(img.attr("width"))
+	height, heightOK := pixels(img.attr("height"))
+	if widthOK && heightOK && width <= 1 && height <= 1 {
+		return true
+	}
+	if widthOK && width == 0 || heightOK && height == 0 {
+		return true
+	}
+
+	style := strings.ToLower(strings.ReplaceAll(img.attr("style"), " ", ""))
+	if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+		return true
+	}
+	if (strings.Contains(style, "width:1px") || strings.Contains(style, "width:0")) &&
+		(strings.Contains(style, "height:1px") || strings.Contains(style, "height:0")) {
+		return true
+	}
+
+	u, err := url.Parse(strings.TrimSpace(img.attr("src")))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, tracker := range trackerHosts {
+		if host == tracker || strings.HasSuffix(host, "."+tracker) {
+			return true
+		}
+	}
+	hostPath := host + u.Path
+	for _, prefix := range trackerPaths {
+		if strings.HasPrefix(hostPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pixels parses a width or height attribute like "1" or "1px"
+func pixels(value string) (int, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	n, err := strconv.Atoi(value)
+	return n, err == nil
+}
+
+// StripTrackingParams removes utm_* query parameters from a URL, keeping
+// the order and encoding of the other parameters. URLs that cannot be
+// parsed are returned unchanged.
+func StripTrackingParams(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	u.RawQuery = stripTrackingQuery(u.RawQuery)
+	return u.String()
+}
+
+// stripTrackingQuery removes utm_* parameters from a raw query
+func stripTrackingQuery(query string) string {
+	if query == "" {
+		return query
+	}
+
+	var kept []string
+	for _, param := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if !strings.HasPrefix(strings.ToLower(key), "utm_") {
+			kept = append(kept, param)
+		}
+	}
+	return strings.Join(kept, "&")
+}
@@ -0,0 +1,50 @@
+package content
+
+import (
+	"time"
+	"unicode"
+)
+
+// DefaultWordsPerMinute is the reading speed used for reading time
+// estimates, the average silent reading speed of adults for non-fiction
+const DefaultWordsPerMinute = 238
+
+// WordCount counts the words in text. Runs of letters and digits count as
+// one word, except in Chinese and Japanese where every character counts as
+// a word since words are not separated by spaces.
+func WordCount(text string) int {
+	count := 0
+	inWord := false
+	for _, c := range text {
+		switch {
+		case unicode.In(c, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			count++
+			inWord = false
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsMark(c):
+			if !inWord {
+				count++
+				inWord = true
+			}
+		case inWord && (c == '\'' || c == '’' || c == '-' || c == '.' || c == ','):
+			// Apostrophes, hyphens and separators in numbers join the
+			// parts of a word
+		default:
+			inWord = false
+		}
+	}
+	return count
+}
+
+// ReadingTime estimates how long it takes to read words at wordsPerMinute,
+// rounded up to whole minutes. Zero or negative speeds use
+// DefaultWordsPerMinute.
+func ReadingTime(words, wordsPerMinute int) time.Duration {
+	if words <= 0 {
+		return 0
+	}
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = DefaultWordsPerMinute
+	}
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	return time.Duration(minutes) * time.Minute
+}
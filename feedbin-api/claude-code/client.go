@@ -26,6 +26,9 @@ type Client struct {
 	// HTTP client used to communicate with the API
 	client *http.Client
 
+	// Observer notified of every request, if set
+	observer RequestObserver
+
 	// Base URL for API requests
 	BaseURL *url.URL
 
@@ -42,6 +45,7 @@ type Client struct {
 	Tags           *TagsService
 	Taggings       *TaggingsService
 	SavedSearches  *SavedSearchesService
+	Imports        *ImportsService
 }
 
 // ClientOption allows customizing the Feedbin client
@@ -64,6 +68,14 @@ func WithBaseURL(baseURL string) ClientOption {
 	}
 }
 
+// WithRequestObserver sets an observer that is notified of every request
+// the client makes, e.g. to record metrics
+func WithRequestObserver(observer RequestObserver) ClientOption {
+	return func(c *Client) {
+		c.observer = observer
+	}
+}
+
 // NewClient creates a new Feedbin API client.
 func NewClient(username, password string, options ...ClientOption) *Client {
 	baseURL, _ := url.Parse(DefaultBaseURL)
@@ -88,6 +100,7 @@ func NewClient(username, password string, options ...ClientOption) *Client {
 	c.Tags = &TagsService{client: c}
 	c.Taggings = &TaggingsService{client: c}
 	c.SavedSearches = &SavedSearchesService{client: c}
+	c.Imports = &ImportsService{client: c}
 
 	return c
 }
@@ -131,7 +144,11 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	start := time.Now()
 	resp, err := c.client.Do(req)
+	if c.observer != nil {
+		c.observeRequest(req, resp, time.Since(start), err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +168,57 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	return resp, nil
 }
 
+// RequestObserver is notified after each request the client sends
+type RequestObserver interface {
+	ObserveRequest(stats *RequestStats)
+}
+
+// RequestStats describes a completed request
+type RequestStats struct {
+	Method string
+	// Endpoint is the request path relative to the BaseURL with numeric
+	// IDs replaced by ":id", e.g. "feeds/:id/entries.json"
+	Endpoint string
+	// StatusCode is zero if no response was received
+	StatusCode int
+	Duration   time.Duration
+	// Pagination holds the Link header information of paginated responses
+	Pagination *PaginationInfo
+	Err        error
+}
+
+// observeRequest reports a request to the observer
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, d time.Duration, err error) {
+	stats := &RequestStats{
+		Method:   req.Method,
+		Endpoint: endpointName(c.BaseURL, req.URL),
+		Duration: d,
+		Err:      err,
+	}
+	if resp != nil {
+		stats.StatusCode = resp.StatusCode
+		stats.Pagination = c.GetPagination(resp)
+	}
+	c.observer.ObserveRequest(stats)
+}
+
+// endpointName returns the path of u relative to base with numeric path
+// segments replaced, so requests for different IDs share a name
+func endpointName(base, u *url.URL) string {
+	path := strings.TrimPrefix(u.Path, base.Path)
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		name, ext, _ := strings.Cut(segment, ".")
+		if _, err := strconv.Atoi(name); err == nil {
+			segments[i] = ":id"
+			if ext != "" {
+				segments[i] += "." + ext
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 // PaginationInfo represents pagination information from Link headers
 type PaginationInfo struct {
 	NextPage     *int
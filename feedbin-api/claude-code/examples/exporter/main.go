@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	feedbin "github.com/feedbin/client"
+)
+
+func main() {
+	// Parse command line flags
+	username := flag.String("username", os.Getenv("FEEDBIN_USERNAME"), "Feedbin API username (email)")
+	password := flag.String("password", os.Getenv("FEEDBIN_PASSWORD"), "Feedbin API password")
+	listen := flag.String("listen", ":9732", "Address to serve /metrics on")
+	interval := flag.Duration("interval", 5*time.Minute, "How often to refresh the account")
+	flag.Parse()
+
+	if *username == "" || *password == "" {
+		log.Fatal("Error: No credentials provided; use -username and -password or FEEDBIN_USERNAME and FEEDBIN_PASSWORD")
+	}
+	if *interval <= 0 {
+		log.Fatal("Error: -interval must be positive")
+	}
+
+	// Record request metrics for every API call the client makes
+	metrics := feedbin.NewRequestMetrics()
+	client := feedbin.NewClient(*username, *password, feedbin.WithRequestObserver(metrics))
+	exporter := feedbin.NewExporter(client, metrics, *interval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go func() {
+		exporter.Run(ctx)
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter)
+	server := &http.Server{Addr: *listen, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving metrics on %s/metrics, refreshing every %s", *listen, *interval)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Error serving metrics: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package feedbin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// entryLookupBatchSize is the most entry IDs the entries endpoint accepts
+// in one request
+const entryLookupBatchSize = 100
+
+// AccountMetrics is a snapshot of the state of a Feedbin account
+type AccountMetrics struct {
+	Unread             int
+	Starred            int
+	Subscriptions      int
+	PendingImportItems int
+
+	// UnreadByFeed counts unread entries per feed ID. Every subscribed feed
+	// is included, even without unread entries.
+	UnreadByFeed map[int]int
+	// UnreadByTag counts unread entries per tag. Entries of a feed with
+	// several tags are counted under each of them.
+	UnreadByTag map[string]int
+	// FeedTitles maps feed IDs to subscription titles
+	FeedTitles map[int]string
+}
+
+// Exporter serves account health metrics in the Prometheus text format.
+// The account is polled by a refresh loop, so scrapes are answered from
+// the last snapshot without calling the API.
+type Exporter struct {
+	client   *Client
+	requests *RequestMetrics
+	interval time.Duration
+
+	// refreshMu serializes refreshes, which share the entry feed cache
+	refreshMu  sync.Mutex
+	entryFeeds map[int]int
+
+	mu              sync.RWMutex
+	account         *AccountMetrics
+	lastErr         error
+	lastSuccess     time.Time
+	refreshDuration time.Duration
+	refreshes       int
+	refreshErrors   int
+}
+
+// NewExporter creates an exporter that refreshes the account every interval.
+// requests may be nil; otherwise it should be the RequestMetrics the client
+// was created with, so that request latencies are exported too.
+func NewExporter(client *Client, requests *RequestMetrics, interval time.Duration) *Exporter {
+	return &Exporter{
+		client:     client,
+		requests:   requests,
+		interval:   interval,
+		entryFeeds: make(map[int]int),
+	}
+}
+
+// Run refreshes the account immediately and then every interval until ctx
+// is cancelled. Refresh errors are exported rather than returned.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.Refresh()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches a new snapshot of the account. On error the previous
+// snapshot is kept and the error is counted.
+func (e *Exporter) Refresh() error {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+
+	start := time.Now()
+	account, err := e.collect()
+	duration := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.refreshes++
+	e.refreshDuration = duration
+	e.lastErr = err
+	if err != nil {
+		e.refreshErrors++
+		return err
+	}
+	e.account = account
+	e.lastSuccess = time.Now()
+	return nil
+}
+
+// Account returns the last snapshot, or nil before the first successful
+// refresh
+func (e *Exporter) Account() *AccountMetrics {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.account
+}
+
+// collect fetches the account state from the API
+func (e *Exporter) collect() (*AccountMetrics, error) {
+	unread, _, err := e.client.UnreadEntries.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing unread entries: %w", err)
+	}
+	starred, _, err := e.client.StarredEntries.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing starred entries: %w", err)
+	}
+	subscriptions, _, err := e.client.Subscriptions.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing subscriptions: %w", err)
+	}
+	taggings, _, err := e.client.Taggings.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing taggings: %w", err)
+	}
+	pending, err := e.pendingImportItems()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.lookupEntryFeeds(unread); err != nil {
+		return nil, err
+	}
+
+	account := &AccountMetrics{
+		Unread:             len(unread),
+		Starred:            len(starred),
+		Subscriptions:      len(subscriptions),
+		PendingImportItems: pending,
+		UnreadByFeed:       make(map[int]int),
+		UnreadByTag:        make(map[string]int),
+		FeedTitles:         make(map[int]string),
+	}
+	for _, sub := range subscriptions {
+		account.UnreadByFeed[sub.FeedID] = 0
+		account.FeedTitles[sub.FeedID] = sub.Title
+	}
+	for _, id := range unread {
+		// Entries deleted since they were listed have no feed
+		if feedID, ok := e.entryFeeds[id]; ok {
+			account.UnreadByFeed[feedID]++
+		}
+	}
+	for _, tagging := range taggings {
+		account.UnreadByTag[tagging.Name] += account.UnreadByFeed[tagging.FeedID]
+	}
+
+	return account, nil
+}
+
+// pendingImportItems counts the pending items of incomplete imports
+func (e *Exporter) pendingImportItems() (int, error) {
+	imports, _, err := e.client.Imports.List()
+	if err != nil {
+		return 0, fmt.Errorf("listing imports: %w", err)
+	}
+
+	pending := 0
+	for _, imp := range imports {
+		if imp.Complete {
+			continue
+		}
+		details, _, err := e.client.Imports.Get(imp.ID)
+		if err != nil {
+			return 0, fmt.Errorf("getting import %d: %w", imp.ID, err)
+		}
+		for _, item := range details.ImportItems {
+			if item.Status == ImportItemPending {
+				pending++
+			}
+		}
+	}
+	return pending, nil
+}
+
+// lookupEntryFeeds fetches the feed IDs of unread entries that are not
+// cached yet, and drops entries that are no longer unread from the cache
+func (e *Exporter) lookupEntryFeeds(unread []int) error {
+	isUnread := make(map[int]bool, len(unread))
+	var missing []int
+	for _, id := range unread {
+		isUnread[id] = true
+		if _, ok := e.entryFeeds[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	for id := range e.entryFeeds {
+		if !isUnread[id] {
+			delete(e.entryFeeds, id)
+		}
+	}
+
+	for len(missing) > 0 {
+		batch := missing
+		if len(batch) > entryLookupBatchSize {
+			batch = batch[:entryLookupBatchSize]
+		}
+		missing = missing[len(batch):]
+
+		entries, _, _, err := e.client.Entries.List(&EntryListOptions{IDs: batch, PerPage: len(batch)})
+		if err != nil {
+			return fmt.Errorf("getting unread entries: %w", err)
+		}
+		for _, entry := range entries {
+			e.entryFeeds[entry.ID] = entry.FeedID
+		}
+	}
+	return nil
+}
+
+// ServeHTTP writes the metrics of the last refresh
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	mw := &metricWriter{w: &buf}
+	e.write(mw)
+	if e.requests != nil {
+		e.requests.write(mw)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// write writes the exporter and account metrics
+func (e *Exporter) write(w *metricWriter) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	up := 0.0
+	if e.refreshes > 0 && e.lastErr == nil {
+		up = 1
+	}
+	w.header("feedbin_up", "Whether the last refresh of the account succeeded.", "gauge")
+	w.sample("feedbin_up", up)
+	w.header("feedbin_refreshes_total", "Number of account refreshes.", "counter")
+	w.sample("feedbin_refreshes_total", float64(e.refreshes))
+	w.header("feedbin_refresh_errors_total", "Number of account refreshes that failed.", "counter")
+	w.sample("feedbin_refresh_errors_total", float64(e.refreshErrors))
+	w.header("feedbin_refresh_duration_seconds", "Duration of the last account refresh.", "gauge")
+	w.sample("feedbin_refresh_duration_seconds", e.refreshDuration.Seconds())
+
+	account := e.account
+	if account == nil {
+		return
+	}
+	w.header("feedbin_last_refresh_timestamp_seconds", "Time of the last successful account refresh.", "gauge")
+	w.sample("feedbin_last_refresh_timestamp_seconds", float64(e.lastSuccess.UnixNano())/1e9)
+	w.header("feedbin_unread_entries", "Number of unread entries.", "gauge")
+	w.sample("feedbin_unread_entries", float64(account.Unread))
+	w.header("feedbin_starred_entries", "Number of starred entries.", "gauge")
+	w.sample("feedbin_starred_entries", float64(account.Starred))
+	w.header("feedbin_subscriptions", "Number of subscriptions.", "gauge")
+	w.sample("feedbin_subscriptions", float64(account.Subscriptions))
+	w.header("feedbin_pending_import_items", "Number of feeds waiting to be imported.", "gauge")
+	w.sample("feedbin_pending_import_items", float64(account.PendingImportItems))
+
+	feedIDs := make([]int, 0, len(account.UnreadByFeed))
+	for id := range account.UnreadByFeed {
+		feedIDs = append(feedIDs, id)
+	}
+	sort.Ints(feedIDs)
+	w.header("feedbin_feed_unread_entries", "Number of unread entries per feed.", "gauge")
+	for _, id := range feedIDs {
+		w.sample("feedbin_feed_unread_entries", float64(account.UnreadByFeed[id]),
+			"feed_id", strconv.Itoa(id), "title", account.FeedTitles[id])
+	}
+
+	tags := make([]string, 0, len(account.UnreadByTag))
+	for tag := range account.UnreadByTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	w.header("feedbin_tag_unread_entries", "Number of unread entries per tag.", "gauge")
+	for _, tag := range tags {
+		w.sample("feedbin_tag_unread_entries", float64(account.UnreadByTag[tag]), "tag", tag)
+	}
+}
@@ -0,0 +1,127 @@
+package feedbin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestEndpointName(t *testing.T) {
+	base, _ := url.Parse("https://api.feedbin.com/v2/")
+	tests := map[string]string{
+		"https://api.feedbin.com/v2/entries.json?ids=1,2":      "entries.json",
+		"https://api.feedbin.com/v2/feeds/42/entries.json":     "feeds/:id/entries.json",
+		"https://api.feedbin.com/v2/imports/6.json":            "imports/:id.json",
+		"https://api.feedbin.com/v2/saved_searches/7.json?x=1": "saved_searches/:id.json",
+	}
+	for in, want := range tests {
+		u, _ := url.Parse(in)
+		if got := endpointName(base, u); got != want {
+			t.Errorf("endpointName(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestExporter(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	metrics := NewRequestMetrics()
+	client := NewClient("user", "pass", WithBaseURL(server.URL+"/"), WithRequestObserver(metrics))
+
+	var entryRequests []string
+	mux.HandleFunc("/unread_entries.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[1, 2, 3]`))
+	})
+	mux.HandleFunc("/starred_entries.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[3]`))
+	})
+	mux.HandleFunc("/subscriptions.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "feed_id": 10, "title": "Daily \"News\""}, {"id": 2, "feed_id": 20, "title": "Quiet"}]`))
+	})
+	mux.HandleFunc("/taggings.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "feed_id": 10, "name": "News"}, {"id": 2, "feed_id": 20, "name": "News"}, {"id": 3, "feed_id": 20, "name": "Slow"}]`))
+	})
+	mux.HandleFunc("/imports.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1, "complete": true}, {"id": 2, "complete": false}]`))
+	})
+	mux.HandleFunc("/imports/2.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 2, "complete": false, "import_items": [{"status": "pending"}, {"status": "complete"}, {"status": "pending"}]}`))
+	})
+	mux.HandleFunc("/entries.json", func(w http.ResponseWriter, r *http.Request) {
+		entryRequests = append(entryRequests, r.URL.Query().Get("ids"))
+		w.Header().Set("Link", `<`+server.URL+`/entries.json?page=2>; rel="next", <`+server.URL+`/entries.json?page=3>; rel="last"`)
+		w.Header().Set("X-Feedbin-Record-Count", "3")
+		w.Write([]byte(`[{"id": 1, "feed_id": 10}, {"id": 2, "feed_id": 10}, {"id": 3, "feed_id": 20}]`))
+	})
+
+	exporter := NewExporter(client, metrics, 0)
+	if err := exporter.Refresh(); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+
+	account := exporter.Account()
+	if account.Unread != 3 || account.Starred != 1 || account.Subscriptions != 2 || account.PendingImportItems != 2 {
+		t.Errorf("Unexpected account metrics: %+v", account)
+	}
+	if account.UnreadByFeed[10] != 2 || account.UnreadByTag["News"] != 3 || account.UnreadByTag["Slow"] != 1 {
+		t.Errorf("Unexpected unread counts: %v, %v", account.UnreadByFeed, account.UnreadByTag)
+	}
+
+	// Cached entries are not looked up again
+	if err := exporter.Refresh(); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if len(entryRequests) != 1 || entryRequests[0] != "1,2,3" {
+		t.Errorf("Expected one entry lookup of 1,2,3, got %v", entryRequests)
+	}
+
+	rec := httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"feedbin_up 1\n",
+		"feedbin_unread_entries 3\n",
+		"feedbin_pending_import_items 2\n",
+		`feedbin_feed_unread_entries{feed_id="10",title="Daily \"News\""} 2` + "\n",
+		`feedbin_tag_unread_entries{tag="News"} 3` + "\n",
+		`feedbin_client_request_duration_seconds_count{endpoint="imports/:id.json",method="GET",code="200"} 2` + "\n",
+		`feedbin_client_request_duration_seconds_bucket{endpoint="entries.json",method="GET",code="200",le="+Inf"} 1` + "\n",
+		`feedbin_client_pagination_pages{endpoint="entries.json"} 3` + "\n",
+		`feedbin_client_pagination_records{endpoint="entries.json"} 3` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q:\n%s", want, body)
+		}
+	}
+
+	// A failed refresh keeps the last snapshot
+	client.BaseURL, _ = url.Parse(server.URL + "/missing/")
+	if err := exporter.Refresh(); err == nil {
+		t.Fatal("Expected Refresh to fail")
+	}
+	rec = httptest.NewRecorder()
+	exporter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body = rec.Body.String()
+	for _, want := range []string{"feedbin_up 0\n", "feedbin_refresh_errors_total 1\n", "feedbin_unread_entries 3\n", `code="404"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRequestMetrics_TransportError(t *testing.T) {
+	metrics := NewRequestMetrics(1)
+	metrics.ObserveRequest(&RequestStats{Method: http.MethodGet, Endpoint: "entries.json", Err: errors.New("refused")})
+
+	var b strings.Builder
+	metrics.write(&metricWriter{w: &b})
+	want := `feedbin_client_request_duration_seconds_bucket{endpoint="entries.json",method="GET",code="error",le="1"} 1`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("Expected metrics to contain %q:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,45 @@
+package feedbin
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ImportsService handles communication with the imports related
+// endpoints of the Feedbin API
+type ImportsService struct {
+	client *Client
+}
+
+// List returns all imports for the authenticated user
+func (s *ImportsService) List() ([]*Import, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "imports.json", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var imports []*Import
+	resp, err := s.client.Do(req, &imports)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return imports, resp, nil
+}
+
+// Get returns an import along with the status of each of its items
+func (s *ImportsService) Get(id int) (*Import, *http.Response, error) {
+	url := fmt.Sprintf("imports/%d.json", id)
+	req, err := s.client.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	imp := new(Import)
+	resp, err := s.client.Do(req, imp)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return imp, resp, nil
+}
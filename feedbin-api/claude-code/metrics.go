@@ -0,0 +1,193 @@
+package feedbin
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request
+// latency histogram buckets
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RequestMetrics records client-side request latencies by endpoint, method
+// and status code, and the page counts of paginated responses. Pass it to
+// NewClient with WithRequestObserver.
+type RequestMetrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	requests map[requestKey]*histogram
+	pages    map[string]*PaginationInfo
+}
+
+type requestKey struct {
+	endpoint, method, code string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewRequestMetrics creates request metrics with the given latency buckets,
+// or DefaultLatencyBuckets if none are given
+func NewRequestMetrics(buckets ...float64) *RequestMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &RequestMetrics{
+		buckets:  buckets,
+		requests: make(map[requestKey]*histogram),
+		pages:    make(map[string]*PaginationInfo),
+	}
+}
+
+// ObserveRequest records a completed request
+func (m *RequestMetrics) ObserveRequest(stats *RequestStats) {
+	code := "error"
+	if stats.StatusCode != 0 {
+		code = strconv.Itoa(stats.StatusCode)
+	}
+	key := requestKey{endpoint: stats.Endpoint, method: stats.Method, code: code}
+	seconds := stats.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := m.requests[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.requests[key] = h
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if stats.Pagination != nil {
+		m.pages[stats.Endpoint] = stats.Pagination
+	}
+}
+
+// write writes the metrics in the Prometheus text format
+func (m *RequestMetrics) write(w *metricWriter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.code < b.code
+	})
+
+	w.header("feedbin_client_request_duration_seconds", "Latency of Feedbin API requests by endpoint, method and status code.", "histogram")
+	for _, key := range keys {
+		h := m.requests[key]
+		labels := []string{"endpoint", key.endpoint, "method", key.method, "code", key.code}
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += h.counts[i]
+			w.sample("feedbin_client_request_duration_seconds_bucket", float64(cumulative), append(labels, "le", formatFloat(bound))...)
+		}
+		w.sample("feedbin_client_request_duration_seconds_bucket", float64(h.count), append(labels, "le", "+Inf")...)
+		w.sample("feedbin_client_request_duration_seconds_sum", h.sum, labels...)
+		w.sample("feedbin_client_request_duration_seconds_count", float64(h.count), labels...)
+	}
+
+	endpoints := make([]string, 0, len(m.pages))
+	for endpoint := range m.pages {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	w.header("feedbin_client_pagination_pages", "Number of pages reported by the last paginated response of an endpoint.", "gauge")
+	for _, endpoint := range endpoints {
+		if pages := pageCount(m.pages[endpoint]); pages > 0 {
+			w.sample("feedbin_client_pagination_pages", float64(pages), "endpoint", endpoint)
+		}
+	}
+	w.header("feedbin_client_pagination_records", "Number of records reported by the last paginated response of an endpoint.", "gauge")
+	for _, endpoint := range endpoints {
+		w.sample("feedbin_client_pagination_records", float64(m.pages[endpoint].TotalCount), "endpoint", endpoint)
+	}
+}
+
+// pageCount returns the number of pages described by a Link header. The
+// last page has no "last" link, so its number is taken from the "prev" link.
+func pageCount(info *PaginationInfo) int {
+	switch {
+	case info.LastPage != nil:
+		return *info.LastPage
+	case info.PreviousPage != nil:
+		return *info.PreviousPage + 1
+	case info.FirstPage != nil:
+		return *info.FirstPage
+	}
+	return 0
+}
+
+// metricWriter writes metrics in the Prometheus text exposition format
+type metricWriter struct {
+	w   io.Writer
+	err error
+}
+
+// header writes the HELP and TYPE lines of a metric
+func (w *metricWriter) header(name, help, typ string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a single sample; labels are given as name, value pairs
+func (w *metricWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i])
+			b.WriteString(`="`)
+			b.WriteString(labelEscaper.Replace(labels[i+1]))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	w.printf("%s %s\n", b.String(), formatFloat(value))
+}
+
+func (w *metricWriter) printf(format string, args ...interface{}) {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.w, format, args...)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
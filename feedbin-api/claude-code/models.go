@@ -101,3 +101,25 @@ type SavedSearch struct {
 	Name  string `json:"name"`
 	Query string `json:"query"`
 }
+
+// Import represents an OPML import
+type Import struct {
+	ID          int           `json:"id"`
+	Complete    bool          `json:"complete"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ImportItems []*ImportItem `json:"import_items,omitempty"` // Only when getting a single import
+}
+
+// Import item statuses
+const (
+	ImportItemPending  = "pending"
+	ImportItemComplete = "complete"
+	ImportItemFailed   = "failed"
+)
+
+// ImportItem represents the status of a single feed in an import
+type ImportItem struct {
+	Title   string `json:"title"`
+	FeedURL string `json:"feed_url"`
+	Status  string `json:"status"`
+}
@@ -18,6 +18,9 @@ type SavedSearchesService struct {
 type SavedSearchCreateOptions struct {
 	Name  string `json:"name"`
 	Query string `json:"query"`
+
+	// SkipValidation sends the query without checking its syntax
+	SkipValidation bool `json:"-"`
 }
 
 // SavedSearchUpdateOptions specifies the parameters to the
@@ -25,6 +28,9 @@ type SavedSearchCreateOptions struct {
 type SavedSearchUpdateOptions struct {
 	Name  string `json:"name,omitempty"`
 	Query string `json:"query,omitempty"`
+
+	// SkipValidation sends the query without checking its syntax
+	SkipValidation bool `json:"-"`
 }
 
 // SavedSearchGetOptions specifies the optional parameters to the
@@ -92,8 +98,16 @@ func (s *SavedSearchesService) Get(id int, opts *SavedSearchGetOptions) (interfa
 	}
 }
 
-// Create creates a new saved search
+// Create creates a new saved search. The query is checked with
+// ValidateSearchQuery first, and a *SearchSyntaxError is returned without
+// sending a request if it is invalid.
 func (s *SavedSearchesService) Create(opts *SavedSearchCreateOptions) (*SavedSearch, *http.Response, error) {
+	if opts != nil && !opts.SkipValidation {
+		if err := ValidateSearchQuery(opts.Query); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	req, err := s.client.NewRequest(http.MethodPost, "saved_searches.json", opts)
 	if err != nil {
 		return nil, nil, err
@@ -119,8 +133,12 @@ func (s *SavedSearchesService) Delete(id int) (*http.Response, error) {
 	return s.client.Do(req, nil)
 }
 
-// Update updates a saved search
+// Update updates a saved search. A new query is checked like in Create.
 func (s *SavedSearchesService) Update(id int, opts *SavedSearchUpdateOptions) (*SavedSearch, *http.Response, error) {
+	if err := validateSavedSearchUpdate(opts); err != nil {
+		return nil, nil, err
+	}
+
 	url := fmt.Sprintf("saved_searches/%d.json", id)
 	req, err := s.client.NewRequest(http.MethodPatch, url, opts)
 	if err != nil {
@@ -139,6 +157,10 @@ func (s *SavedSearchesService) Update(id int, opts *SavedSearchUpdateOptions) (*
 // UpdateWithPost updates a saved search using POST instead of PATCH
 // Some proxies may block PATCH requests
 func (s *SavedSearchesService) UpdateWithPost(id int, opts *SavedSearchUpdateOptions) (*SavedSearch, *http.Response, error) {
+	if err := validateSavedSearchUpdate(opts); err != nil {
+		return nil, nil, err
+	}
+
 	url := fmt.Sprintf("saved_searches/%d/update.json", id)
 	req, err := s.client.NewRequest(http.MethodPost, url, opts)
 	if err != nil {
@@ -153,3 +175,16 @@ func (s *SavedSearchesService) UpdateWithPost(id int, opts *SavedSearchUpdateOpt
 
 	return savedSearch, resp, nil
 }
+
+// validateSavedSearchUpdate checks the query of an update, if it has one
+func validateSavedSearchUpdate(opts *SavedSearchUpdateOptions) error {
+	if opts == nil || opts.SkipValidation || opts.Query == "" {
+		return nil
+	}
+	return ValidateSearchQuery(opts.Query)
+}
+
+// ParseQuery parses the query of a saved search, for matching entries locally
+func (s *SavedSearch) ParseQuery() (*SearchQuery, error) {
+	return ParseSearchQuery(s.Query)
+}
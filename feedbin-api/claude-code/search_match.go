@@ -0,0 +1,237 @@
+package feedbin
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchContext holds the entry state that search queries match besides
+// the entry itself
+type SearchContext struct {
+	// Unread and Starred are sets of entry IDs
+	Unread  map[int]bool
+	Starred map[int]bool
+
+	// FeedTags are the tag names of each feed, by feed ID
+	FeedTags map[int][]string
+}
+
+// NewSearchContext creates a SearchContext from the results of
+// UnreadEntriesService.List, StarredEntriesService.List and TaggingsService.List
+func NewSearchContext(unreadEntryIDs, starredEntryIDs []int, taggings []*Tagging) *SearchContext {
+	ctx := &SearchContext{
+		Unread:   make(map[int]bool, len(unreadEntryIDs)),
+		Starred:  make(map[int]bool, len(starredEntryIDs)),
+		FeedTags: make(map[int][]string),
+	}
+	for _, id := range unreadEntryIDs {
+		ctx.Unread[id] = true
+	}
+	for _, id := range starredEntryIDs {
+		ctx.Starred[id] = true
+	}
+	for _, tagging := range taggings {
+		ctx.FeedTags[tagging.FeedID] = append(ctx.FeedTags[tagging.FeedID], tagging.Name)
+	}
+	return ctx
+}
+
+// Match reports whether an entry matches the query. A nil ctx treats every
+// entry as read, unstarred and untagged.
+//
+// Text is matched by whole words, ignoring case and HTML markup. Unlike the
+// Feedbin server, words are not stemmed, so "run" does not match "running"
+// unless written as run*.
+func (q *SearchQuery) Match(entry *Entry, ctx *SearchContext) bool {
+	if ctx == nil {
+		ctx = &SearchContext{}
+	}
+	doc := &searchDocument{entry: entry}
+	return doc.match(q.Root, ctx)
+}
+
+// Filter returns the entries that match the query, in their original order
+func (q *SearchQuery) Filter(entries []*Entry, ctx *SearchContext) []*Entry {
+	var matches []*Entry
+	for _, entry := range entries {
+		if q.Match(entry, ctx) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// searchDocument holds the words of an entry, split when first needed
+type searchDocument struct {
+	entry  *Entry
+	fields map[string][]string
+}
+
+// match evaluates a node against the entry
+func (d *searchDocument) match(node SearchNode, ctx *SearchContext) bool {
+	switch n := node.(type) {
+	case *SearchAnd:
+		for _, child := range n.Nodes {
+			if !d.match(child, ctx) {
+				return false
+			}
+		}
+		return true
+	case *SearchOr:
+		for _, child := range n.Nodes {
+			if d.match(child, ctx) {
+				return true
+			}
+		}
+		return false
+	case *SearchNot:
+		return !d.match(n.Node, ctx)
+	case *SearchIs:
+		switch n.State {
+		case "unread":
+			return ctx.Unread[d.entry.ID]
+		case "read":
+			return !ctx.Unread[d.entry.ID]
+		case "starred":
+			return ctx.Starred[d.entry.ID]
+		case "unstarred":
+			return !ctx.Starred[d.entry.ID]
+		}
+		return false
+	case *SearchTag:
+		for _, name := range ctx.FeedTags[d.entry.FeedID] {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(n.Name)) {
+				return true
+			}
+		}
+		return false
+	case *SearchFeedID:
+		return d.entry.FeedID == n.FeedID
+	case *SearchTerm:
+		fields := []string{n.Field}
+		if n.Field == "" {
+			fields = []string{"title", "author", "content"}
+		}
+		terms := searchWords(n.Text)
+		for _, field := range fields {
+			if containsSearchWords(d.words(field), terms, n.Prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// words returns the lowercased words of a field of the entry
+func (d *searchDocument) words(field string) []string {
+	if words, ok := d.fields[field]; ok {
+		return words
+	}
+
+	var text string
+	switch field {
+	case "title":
+		text = stringValue(d.entry.Title)
+	case "author":
+		text = stringValue(d.entry.Author)
+	case "content":
+		text = stringValue(d.entry.Content)
+		if strings.TrimSpace(text) == "" {
+			text = stringValue(d.entry.Summary)
+		}
+		text = htmlSearchText(text)
+	case "url":
+		text = d.entry.URL
+	}
+
+	if d.fields == nil {
+		d.fields = make(map[string][]string)
+	}
+	words := searchWords(text)
+	d.fields[field] = words
+	return words
+}
+
+// containsSearchWords reports whether words contains terms in sequence. With
+// prefix the last term only needs to start a word.
+func containsSearchWords(words, terms []string, prefix bool) bool {
+	if len(terms) == 0 {
+		return false
+	}
+
+	last := len(terms) - 1
+	for start := 0; start+len(terms) <= len(words); start++ {
+		matched := true
+		for i, term := range terms {
+			word := words[start+i]
+			if word != term && !(prefix && i == last && strings.HasPrefix(word, term)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// searchWords splits text into lowercased words of letters and digits
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isSearchWordRune(r) && !unicode.IsMark(r)
+	})
+}
+
+// htmlSearchText returns the text of HTML content without markup, scripts
+// and styles
+func htmlSearchText(content string) string {
+	var text strings.Builder
+	skip := ""
+	for content != "" {
+		lt := strings.IndexByte(content, '<')
+		if lt < 0 {
+			if skip == "" {
+				text.WriteString(content)
+			}
+			break
+		}
+		if skip == "" {
+			text.WriteString(content[:lt])
+		}
+
+		gt := strings.IndexByte(content[lt:], '>')
+		if gt < 0 {
+			break
+		}
+		tag := strings.ToLower(strings.TrimLeft(content[lt+1:lt+gt], "/"))
+		if i := strings.IndexAny(tag, " \t\r\n/"); i >= 0 {
+			tag = tag[:i]
+		}
+		closing := strings.HasPrefix(content[lt+1:], "/")
+		content = content[lt+gt+1:]
+
+		switch {
+		case tag == "script" || tag == "style":
+			if closing && skip == tag {
+				skip = ""
+			} else if !closing {
+				skip = tag
+			}
+		case skip == "":
+			// Tags separate words, as in <p>one</p><p>two</p>
+			text.WriteByte(' ')
+		}
+	}
+	return html.UnescapeString(text.String())
+}
+
+// stringValue returns the value of s, or "" if s is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
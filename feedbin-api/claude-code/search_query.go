@@ -0,0 +1,599 @@
+package feedbin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchNode is a node of a parsed search query. It is one of *SearchAnd,
+// *SearchOr, *SearchNot, *SearchTerm, *SearchIs, *SearchTag and *SearchFeedID.
+type SearchNode interface {
+	// String formats the node in Feedbin search syntax
+	String() string
+
+	searchNode()
+}
+
+// SearchAnd matches entries that match all of its nodes
+type SearchAnd struct {
+	Nodes []SearchNode
+}
+
+// SearchOr matches entries that match any of its nodes
+type SearchOr struct {
+	Nodes []SearchNode
+}
+
+// SearchNot matches entries that do not match its node
+type SearchNot struct {
+	Node SearchNode
+}
+
+// SearchTerm matches a word or phrase in the text of an entry
+type SearchTerm struct {
+	// Field is "title", "author", "content" or "url", or empty to search
+	// the title, author and content
+	Field string
+
+	// Text is the word or phrase without quotes or wildcard
+	Text string
+
+	// Phrase is set for quoted terms
+	Phrase bool
+
+	// Prefix is set for terms ending in *, which match words starting with Text
+	Prefix bool
+}
+
+// SearchIs matches the read or starred state of an entry
+type SearchIs struct {
+	// State is "unread", "read", "starred" or "unstarred"
+	State string
+}
+
+// SearchTag matches entries of feeds with a tag
+type SearchTag struct {
+	Name string
+}
+
+// SearchFeedID matches entries of a feed
+type SearchFeedID struct {
+	FeedID int
+}
+
+func (*SearchAnd) searchNode()    {}
+func (*SearchOr) searchNode()     {}
+func (*SearchNot) searchNode()    {}
+func (*SearchTerm) searchNode()   {}
+func (*SearchIs) searchNode()     {}
+func (*SearchTag) searchNode()    {}
+func (*SearchFeedID) searchNode() {}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchAnd) String() string {
+	parts := make([]string, len(n.Nodes))
+	for i, node := range n.Nodes {
+		parts[i] = node.String()
+		if _, ok := node.(*SearchOr); ok {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchOr) String() string {
+	parts := make([]string, len(n.Nodes))
+	for i, node := range n.Nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, " OR ")
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchNot) String() string {
+	switch n.Node.(type) {
+	case *SearchAnd, *SearchOr:
+		return "-(" + n.Node.String() + ")"
+	}
+	return "-" + n.Node.String()
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchTerm) String() string {
+	text := n.Text
+	if n.Phrase {
+		text = quoteSearchValue(text)
+	}
+	if n.Prefix {
+		text += "*"
+	}
+	if n.Field != "" {
+		return n.Field + ":" + text
+	}
+	return text
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchIs) String() string {
+	return "is:" + n.State
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchTag) String() string {
+	if strings.ContainsFunc(n.Name, isSearchSpecial) || n.Name == "" {
+		return "tag:" + quoteSearchValue(n.Name)
+	}
+	return "tag:" + n.Name
+}
+
+// String formats the node in Feedbin search syntax
+func (n *SearchFeedID) String() string {
+	return "feed_id:" + strconv.Itoa(n.FeedID)
+}
+
+// SearchQuery is a parsed Feedbin search query
+type SearchQuery struct {
+	// Raw is the query as it was parsed
+	Raw string
+
+	// Root is the root node of the query
+	Root SearchNode
+}
+
+// String formats the query in Feedbin search syntax
+func (q *SearchQuery) String() string {
+	return q.Root.String()
+}
+
+// SearchSyntaxError describes an invalid search query
+type SearchSyntaxError struct {
+	Query   string
+	Offset  int // Byte offset of the error in Query
+	Message string
+}
+
+// Error implements the error interface
+func (e *SearchSyntaxError) Error() string {
+	return fmt.Sprintf("invalid search query at offset %d: %s", e.Offset, e.Message)
+}
+
+// searchStates are the values accepted by is:
+var searchStates = map[string]bool{
+	"unread":    true,
+	"read":      true,
+	"starred":   true,
+	"unstarred": true,
+}
+
+// searchTextFields are the fields that match entry text
+var searchTextFields = map[string]bool{
+	"title":   true,
+	"author":  true,
+	"content": true,
+	"url":     true,
+}
+
+// ParseSearchQuery parses a query in Feedbin search syntax.
+//
+// Words and quoted phrases match the title, author and content of entries,
+// and words ending in * match words that start with them. Fields restrict
+// a term: title:, author:, content: and url: match text, is:unread, is:read,
+// is:starred and is:unstarred match the entry state, tag: matches the tags
+// of the entry's feed and feed_id: matches its feed. A field can also apply
+// to a group, as in title:(go OR rust).
+//
+// Terms are combined with AND unless joined with OR, and AND binds tighter
+// than OR. NOT, - and ! negate the following term or group, and parentheses
+// group terms. Operators must be written in upper case; lower case "and",
+// "or" and "not" are search words.
+//
+// Errors are returned as *SearchSyntaxError.
+func ParseSearchQuery(query string) (*SearchQuery, error) {
+	p := &searchParser{query: query}
+	p.next()
+
+	if p.tok.kind == searchEOF {
+		return nil, p.errorf(0, "query is empty")
+	}
+
+	root, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.tok.kind {
+	case searchEOF:
+	case searchRParen:
+		return nil, p.errorf(p.tok.pos, "unexpected )")
+	default:
+		return nil, p.errorf(p.tok.pos, "unexpected %s", p.tok.text)
+	}
+
+	return &SearchQuery{Raw: query, Root: root}, nil
+}
+
+// ValidateSearchQuery reports whether query is valid Feedbin search syntax,
+// returning a *SearchSyntaxError if it is not
+func ValidateSearchQuery(query string) error {
+	_, err := ParseSearchQuery(query)
+	return err
+}
+
+// searchTokenKind is the kind of a searchToken
+type searchTokenKind int
+
+const (
+	searchEOF searchTokenKind = iota
+	searchWord
+	searchPhrase
+	searchField // A field name followed by a colon, Text is the name
+	searchLParen
+	searchRParen
+	searchAndOp
+	searchOrOp
+	searchNotOp
+	searchMinus // A - or ! directly before a term
+)
+
+// searchToken is a token of a search query
+type searchToken struct {
+	kind searchTokenKind
+	text string
+	pos  int
+	err  string // Set for invalid tokens
+}
+
+// searchParser is a recursive descent parser of search queries
+type searchParser struct {
+	query string
+	pos   int
+	tok   searchToken
+}
+
+// next reads the next token into p.tok
+func (p *searchParser) next() {
+	q := p.query
+	for p.pos < len(q) {
+		r, size := utf8.DecodeRuneInString(q[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.pos += size
+	}
+
+	start := p.pos
+	if start >= len(q) {
+		p.tok = searchToken{kind: searchEOF, pos: start, text: "end of query"}
+		return
+	}
+
+	switch c := q[start]; {
+	case c == '(':
+		p.pos++
+		p.tok = searchToken{kind: searchLParen, text: "(", pos: start}
+		return
+	case c == ')':
+		p.pos++
+		p.tok = searchToken{kind: searchRParen, text: ")", pos: start}
+		return
+	case c == '"':
+		end := strings.IndexByte(q[start+1:], '"')
+		if end < 0 {
+			p.pos = len(q)
+			p.tok = searchToken{kind: searchPhrase, pos: start, err: "unterminated quote"}
+			return
+		}
+		p.pos = start + 1 + end + 1
+		text := q[start+1 : start+1+end]
+		// A trailing * after the closing quote makes a prefix phrase
+		if p.pos < len(q) && q[p.pos] == '*' {
+			p.pos++
+			text += "*"
+		}
+		p.tok = searchToken{kind: searchPhrase, text: text, pos: start}
+		return
+	case (c == '-' || c == '!') && start+1 < len(q) && !isSearchSpace(q[start+1]):
+		p.pos++
+		p.tok = searchToken{kind: searchMinus, text: string(c), pos: start}
+		return
+	case c == '&' && strings.HasPrefix(q[start:], "&&"):
+		p.pos += 2
+		p.tok = searchToken{kind: searchAndOp, text: "&&", pos: start}
+		return
+	case c == '|' && strings.HasPrefix(q[start:], "||"):
+		p.pos += 2
+		p.tok = searchToken{kind: searchOrOp, text: "||", pos: start}
+		return
+	}
+
+	// Read a word up to whitespace, a parenthesis or a quote
+	end := start
+	for end < len(q) {
+		r, size := utf8.DecodeRuneInString(q[end:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		// A colon after a known field name ends the name unless it is part
+		// of a URL. Other names are plain words, as in "Breaking: news".
+		if r == ':' && end > start && !strings.HasPrefix(q[end:], "://") && isSearchField(q[start:end]) {
+			p.pos = end + 1
+			p.tok = searchToken{kind: searchField, text: strings.ToLower(q[start:end]), pos: start}
+			return
+		}
+		end += size
+	}
+	p.pos = end
+
+	word := q[start:end]
+	switch word {
+	case "AND":
+		p.tok = searchToken{kind: searchAndOp, text: word, pos: start}
+	case "OR":
+		p.tok = searchToken{kind: searchOrOp, text: word, pos: start}
+	case "NOT":
+		p.tok = searchToken{kind: searchNotOp, text: word, pos: start}
+	default:
+		p.tok = searchToken{kind: searchWord, text: word, pos: start}
+	}
+}
+
+// parseOr parses terms joined by OR
+func (p *searchParser) parseOr(field string) (SearchNode, error) {
+	node, err := p.parseAnd(field)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []SearchNode{node}
+	for p.tok.kind == searchOrOp {
+		op := p.tok
+		p.next()
+		if !p.startsTerm() {
+			return nil, p.errorf(op.pos, "%s must be followed by a term", op.text)
+		}
+		node, err := p.parseAnd(field)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &SearchOr{Nodes: flattenSearchOr(nodes)}, nil
+}
+
+// parseAnd parses terms joined by AND or by nothing
+func (p *searchParser) parseAnd(field string) (SearchNode, error) {
+	if !p.startsTerm() {
+		return nil, p.unexpected()
+	}
+
+	var nodes []SearchNode
+	for {
+		node, err := p.parseUnary(field)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		if p.tok.kind == searchAndOp {
+			op := p.tok
+			p.next()
+			if !p.startsTerm() {
+				return nil, p.errorf(op.pos, "%s must be followed by a term", op.text)
+			}
+			continue
+		}
+		if !p.startsTerm() {
+			break
+		}
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &SearchAnd{Nodes: flattenSearchAnd(nodes)}, nil
+}
+
+// parseUnary parses a term or group with optional negation
+func (p *searchParser) parseUnary(field string) (SearchNode, error) {
+	if p.tok.kind == searchNotOp || p.tok.kind == searchMinus {
+		op := p.tok
+		p.next()
+		if !p.startsTerm() {
+			return nil, p.errorf(op.pos, "%s must be followed by a term", op.text)
+		}
+		node, err := p.parseUnary(field)
+		if err != nil {
+			return nil, err
+		}
+		// Double negation cancels out
+		if not, ok := node.(*SearchNot); ok {
+			return not.Node, nil
+		}
+		return &SearchNot{Node: node}, nil
+	}
+	return p.parsePrimary(field)
+}
+
+// parsePrimary parses a group, a field or a term
+func (p *searchParser) parsePrimary(field string) (SearchNode, error) {
+	tok := p.tok
+	switch tok.kind {
+	case searchLParen:
+		p.next()
+		if p.tok.kind == searchRParen {
+			return nil, p.errorf(tok.pos, "empty group")
+		}
+		node, err := p.parseOr(field)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != searchRParen {
+			return nil, p.errorf(tok.pos, "missing ) for this (")
+		}
+		p.next()
+		return node, nil
+
+	case searchField:
+		if field != "" {
+			return nil, p.errorf(tok.pos, "field %s: inside a %s: group", tok.text, field)
+		}
+		p.next()
+		switch p.tok.kind {
+		case searchLParen:
+			return p.parsePrimary(tok.text)
+		case searchWord, searchPhrase:
+			if p.tok.pos != tok.pos+len(tok.text)+1 {
+				return nil, p.errorf(tok.pos, "missing value for %s:", tok.text)
+			}
+			return p.parsePrimary(tok.text)
+		case searchMinus:
+			// title:-x excludes x from the field
+			if p.tok.pos != tok.pos+len(tok.text)+1 {
+				return nil, p.errorf(tok.pos, "missing value for %s:", tok.text)
+			}
+			return p.parseUnary(tok.text)
+		default:
+			return nil, p.errorf(tok.pos, "missing value for %s:", tok.text)
+		}
+
+	case searchWord, searchPhrase:
+		if tok.err != "" {
+			return nil, p.errorf(tok.pos, "%s", tok.err)
+		}
+		p.next()
+		return p.fieldNode(field, tok)
+	}
+
+	return nil, p.unexpected()
+}
+
+// fieldNode creates the node for a word or phrase in a field
+func (p *searchParser) fieldNode(field string, tok searchToken) (SearchNode, error) {
+	text := tok.text
+	prefix := false
+	if strings.HasSuffix(text, "*") && text != "*" {
+		prefix = true
+		text = strings.TrimRight(text, "*")
+	}
+
+	switch field {
+	case "is":
+		state := strings.ToLower(text)
+		if !searchStates[state] || prefix {
+			return nil, p.errorf(tok.pos, "unknown state is:%s, expected unread, read, starred or unstarred", tok.text)
+		}
+		return &SearchIs{State: state}, nil
+
+	case "tag":
+		if strings.TrimSpace(text) == "" || prefix {
+			return nil, p.errorf(tok.pos, "invalid tag name %q", tok.text)
+		}
+		return &SearchTag{Name: text}, nil
+
+	case "feed_id":
+		id, err := strconv.Atoi(text)
+		if err != nil || id <= 0 || prefix {
+			return nil, p.errorf(tok.pos, "feed_id: must be a number, got %q", tok.text)
+		}
+		return &SearchFeedID{FeedID: id}, nil
+	}
+
+	if !tok.isPhrase() && !strings.ContainsFunc(text, isSearchWordRune) {
+		return nil, p.errorf(tok.pos, "%q is not a search term", tok.text)
+	}
+	if tok.isPhrase() && len(searchWords(text)) == 0 {
+		return nil, p.errorf(tok.pos, "empty phrase")
+	}
+	return &SearchTerm{Field: field, Text: text, Phrase: tok.isPhrase(), Prefix: prefix}, nil
+}
+
+// isPhrase reports whether the token is a quoted phrase
+func (t searchToken) isPhrase() bool {
+	return t.kind == searchPhrase
+}
+
+// startsTerm reports whether the current token can start a term
+func (p *searchParser) startsTerm() bool {
+	switch p.tok.kind {
+	case searchWord, searchPhrase, searchField, searchLParen, searchNotOp, searchMinus:
+		return true
+	}
+	return false
+}
+
+// unexpected returns an error for the current token
+func (p *searchParser) unexpected() error {
+	switch p.tok.kind {
+	case searchEOF:
+		return p.errorf(p.tok.pos, "unexpected end of query")
+	case searchAndOp, searchOrOp:
+		return p.errorf(p.tok.pos, "%s must be between two terms", p.tok.text)
+	}
+	return p.errorf(p.tok.pos, "unexpected %s", p.tok.text)
+}
+
+// errorf returns a *SearchSyntaxError at offset
+func (p *searchParser) errorf(offset int, format string, args ...interface{}) error {
+	return &SearchSyntaxError{Query: p.query, Offset: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// flattenSearchAnd merges nested AND nodes into their parent
+func flattenSearchAnd(nodes []SearchNode) []SearchNode {
+	var flat []SearchNode
+	for _, node := range nodes {
+		if and, ok := node.(*SearchAnd); ok {
+			flat = append(flat, and.Nodes...)
+		} else {
+			flat = append(flat, node)
+		}
+	}
+	return flat
+}
+
+// flattenSearchOr merges nested OR nodes into their parent
+func flattenSearchOr(nodes []SearchNode) []SearchNode {
+	var flat []SearchNode
+	for _, node := range nodes {
+		if or, ok := node.(*SearchOr); ok {
+			flat = append(flat, or.Nodes...)
+		} else {
+			flat = append(flat, node)
+		}
+	}
+	return flat
+}
+
+// isSearchField reports whether s is the name of a search field
+func isSearchField(s string) bool {
+	name := strings.ToLower(s)
+	return searchTextFields[name] || name == "is" || name == "tag" || name == "feed_id"
+}
+
+// isSearchSpace reports whether c is an ASCII space
+func isSearchSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// isSearchSpecial reports whether r has a meaning in search syntax
+func isSearchSpecial(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' || r == ':' || r == '*'
+}
+
+// isSearchWordRune reports whether r is part of a word in search text
+func isSearchWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// quoteSearchValue quotes a value, removing any quotes in it since the
+// syntax has no escapes
+func quoteSearchValue(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+}
@@ -0,0 +1,177 @@
+package feedbin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"golang", "golang"},
+		{"go rust", "go rust"},
+		{"go AND rust", "go rust"},
+		{"go && rust", "go rust"},
+		{"go OR rust", "go OR rust"},
+		{"go rust OR zig", "go rust OR zig"},
+		{"go (rust OR zig)", "go (rust OR zig)"},
+		{`"hello world"`, `"hello world"`},
+		{"NOT go", "-go"},
+		{"-go", "-go"},
+		{"!go", "-go"},
+		{"-(go OR rust)", "-(go OR rust)"},
+		{"NOT -go", "go"},
+		{"go* is:unread", "go* is:unread"},
+		{"is:Starred tag:Tech feed_id:42", "is:starred tag:Tech feed_id:42"},
+		{`tag:"Web Dev"`, `tag:"Web Dev"`},
+		{`title:"release notes"`, `title:"release notes"`},
+		{"title:(go OR rust)", "title:go OR title:rust"},
+		{"TITLE:go", "title:go"},
+		{"https://example.com/path", "https://example.com/path"},
+		{"e-mail and or", "e-mail and or"},
+		{"Breaking: news", "Breaking: news"},
+		{"Update: release", "Update: release"},
+		{"titel:go", "titel:go"},
+		{"title:-x", "-title:x"},
+		{"go title:-(x OR y)", "go -(title:x OR title:y)"},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseSearchQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) error = %v", tt.query, err)
+			continue
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("ParseSearchQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query  string
+		offset int
+	}{
+		{"", 0},
+		{"   ", 0},
+		{"go AND", 3},
+		{"OR go", 0},
+		{"go OR OR rust", 3},
+		{"(go", 0},
+		{"go)", 2},
+		{"()", 0},
+		{`"unterminated`, 0},
+		{"is:unknown", 3},
+		{"feed_id:abc", 8},
+		{"title:", 0},
+		{"title: go", 0},
+		{"is:foo", 3},
+		{"title: -x", 0},
+		{"NOT", 0},
+		{"go -", 3},
+		{"title:(tag:go)", 7},
+		{`""`, 0},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseSearchQuery(tt.query)
+		var syntaxErr *SearchSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseSearchQuery(%q) error = %v, want *SearchSyntaxError", tt.query, err)
+			continue
+		}
+		if syntaxErr.Offset != tt.offset {
+			t.Errorf("ParseSearchQuery(%q) error offset = %d, want %d (%v)", tt.query, syntaxErr.Offset, tt.offset, err)
+		}
+	}
+}
+
+func TestSearchQuery_Match(t *testing.T) {
+	entry := &Entry{
+		ID:      1,
+		FeedID:  10,
+		Title:   String("Go 1.22 Release Notes"),
+		Author:  String("The Go Team"),
+		Content: String(`<p>Range over <b>integers</b> is now supported.</p><script>var hidden = 1;</script>`),
+		URL:     "https://go.dev/blog/go1.22",
+	}
+	ctx := NewSearchContext([]int{1}, nil, []*Tagging{{FeedID: 10, Name: "Programming"}})
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"release", true},
+		{"RELEASE notes", true},
+		{"release python", false},
+		{"release OR python", true},
+		{"-python", true},
+		{"NOT release", false},
+		{`"range over integers"`, true},
+		{`"over range"`, false},
+		{"integ*", true},
+		{"integ", false},
+		{"hidden", false},
+		{"title:integers", false},
+		{"content:integers", true},
+		{"author:team", true},
+		{"url:blog", true},
+		{"is:unread", true},
+		{"is:read", false},
+		{"is:starred", false},
+		{"is:unstarred", true},
+		{"tag:programming", true},
+		{"tag:news", false},
+		{"feed_id:10", true},
+		{"feed_id:11", false},
+		{"is:unread (feed_id:11 OR tag:Programming) -title:python", true},
+		{"title:(python OR rust)", false},
+		{"title:-python", true},
+		{"title:-release", false},
+		{"Release: notes", true},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseSearchQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) error = %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(entry, ctx); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSavedSearchesService_CreateValidatesQuery(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "name": "Go", "query": "go AND"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("user", "pass")
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+
+	_, _, err := c.SavedSearches.Create(&SavedSearchCreateOptions{Name: "Go", Query: "go AND"})
+	var syntaxErr *SearchSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Create error = %v, want *SearchSyntaxError", err)
+	}
+	if requests != 0 {
+		t.Errorf("Create sent %d requests for an invalid query, want 0", requests)
+	}
+
+	_, _, err = c.SavedSearches.Create(&SavedSearchCreateOptions{Name: "Go", Query: "go AND", SkipValidation: true})
+	if err != nil || requests != 1 {
+		t.Errorf("Create with SkipValidation error = %v, requests = %d", err, requests)
+	}
+}
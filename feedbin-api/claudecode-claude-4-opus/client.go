@@ -1,10 +1,9 @@
 package feedbin
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -24,30 +23,41 @@ type Client struct {
 	Email      string
 	Password   string
 	HTTPClient *http.Client
+
+	// RetryPolicy controls retries of failed requests; nil disables them
+	RetryPolicy *RetryPolicy
 }
 
 // NewClient creates a new Feedbin API client
 func NewClient(email, password string) *Client {
 	return &Client{
-		BaseURL:    defaultBaseURL,
-		Email:      email,
-		Password:   password,
-		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		BaseURL:     defaultBaseURL,
+		Email:       email,
+		Password:    password,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+		RetryPolicy: DefaultRetryPolicy(),
 	}
 }
 
 // NewClientWithHTTP creates a new Feedbin API client with a custom HTTP client
 func NewClientWithHTTP(email, password string, httpClient *http.Client) *Client {
 	return &Client{
-		BaseURL:    defaultBaseURL,
-		Email:      email,
-		Password:   password,
-		HTTPClient: httpClient,
+		BaseURL:     defaultBaseURL,
+		Email:       email,
+		Password:    password,
+		HTTPClient:  httpClient,
+		RetryPolicy: DefaultRetryPolicy(),
 	}
 }
 
 // request performs an HTTP request with authentication
 func (c *Client) request(method, path string, body interface{}, query url.Values) (*http.Response, error) {
+	return c.requestContext(context.Background(), method, path, body, query)
+}
+
+// requestContext performs an HTTP request with authentication that is
+// cancelled with ctx
+func (c *Client) requestContext(ctx context.Context, method, path string, body interface{}, query url.Values) (*http.Response, error) {
 	// Build URL
 	u, err := url.Parse(c.BaseURL)
 	if err != nil {
@@ -59,48 +69,25 @@ func (c *Client) request(method, path string, body interface{}, query url.Values
 	}
 
 	// Prepare body
-	var bodyReader io.Reader
+	var payload []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		payload, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonBody)
 	}
 
-	// Create request
-	req, err := http.NewRequest(method, u.String(), bodyReader)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set headers
-	req.SetBasicAuth(c.Email, c.Password)
-	req.Header.Set("User-Agent", userAgent)
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Accept", "application/json")
-
-	// Execute request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, newAPIError(resp, string(bodyBytes))
-	}
-
-	return resp, nil
+	return c.doContext(ctx, method, u.String(), payload, "application/json")
 }
 
 // get performs a GET request
 func (c *Client) get(path string, query url.Values, result interface{}) error {
-	resp, err := c.request(http.MethodGet, path, nil, query)
+	return c.getContext(context.Background(), path, query, result)
+}
+
+// getContext performs a GET request that is cancelled with ctx
+func (c *Client) getContext(ctx context.Context, path string, query url.Values, result interface{}) error {
+	resp, err := c.requestContext(ctx, http.MethodGet, path, nil, query)
 	if err != nil {
 		return err
 	}
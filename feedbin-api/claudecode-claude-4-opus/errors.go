@@ -3,6 +3,7 @@ package feedbin
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // APIError represents an error returned by the Feedbin API
@@ -87,10 +88,7 @@ func newAPIError(resp *http.Response, message string) error {
 	case http.StatusBadRequest:
 		return &ValidationError{APIError: apiErr}
 	case http.StatusTooManyRequests:
-		retryAfter := 0
-		if val := resp.Header.Get("Retry-After"); val != "" {
-			fmt.Sscanf(val, "%d", &retryAfter)
-		}
+		retryAfter := int(parseRetryAfter(resp.Header) / time.Second)
 		return &RateLimitError{APIError: apiErr, RetryAfter: retryAfter}
 	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
 		return &ServerError{APIError: apiErr}
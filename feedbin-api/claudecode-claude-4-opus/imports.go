@@ -1,7 +1,7 @@
 package feedbin
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -36,32 +36,7 @@ func (c *Client) requestXML(method, path string, xmlData []byte) (*http.Response
 	}
 	u.Path = fmt.Sprintf("/%s%s", apiVersion, path)
 
-	// Create request
-	req, err := http.NewRequest(method, u.String(), bytes.NewReader(xmlData))
-	if err != nil {
-		return nil, err
-	}
-
-	// Set headers
-	req.SetBasicAuth(c.Email, c.Password)
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Content-Type", "text/xml")
-	req.Header.Set("Accept", "application/json")
-
-	// Execute request
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-
-	// Check for errors
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, newAPIError(resp, string(bodyBytes))
-	}
-
-	return resp, nil
+	return c.do(method, u.String(), xmlData, "text/xml")
 }
 
 // CreateImportFromReader imports an OPML file from an io.Reader
@@ -91,19 +66,192 @@ func (c *Client) GetImports() ([]Import, error) {
 	return imports, err
 }
 
-// WaitForImport polls the import status until it's complete
-func (c *Client) WaitForImport(id int) (*Import, error) {
+// GetImportContext retrieves the status of an import, cancelling the request with ctx
+func (c *Client) GetImportContext(ctx context.Context, id int) (*Import, error) {
+	var importResult Import
+	path := fmt.Sprintf("/imports/%d.json", id)
+	err := c.getContext(ctx, path, nil, &importResult)
+	if err != nil {
+		return nil, err
+	}
+	return &importResult, nil
+}
+
+// Import item statuses reported by the API
+const (
+	ImportItemPending  = "pending"
+	ImportItemComplete = "complete"
+	ImportItemFailed   = "failed"
+)
+
+// ImportBackoff controls how often WatchImport polls an import. The interval
+// grows while nothing changes and goes back to Initial whenever an item
+// changes status.
+type ImportBackoff struct {
+	// Initial is the wait after the first poll and after every poll that
+	// saw progress
+	Initial time.Duration
+
+	// Max caps the wait between polls
+	Max time.Duration
+
+	// Multiplier grows the wait after every poll without progress
+	Multiplier float64
+}
+
+// DefaultImportBackoff returns the backoff used when WatchImport gets nil
+func DefaultImportBackoff() *ImportBackoff {
+	return &ImportBackoff{
+		Initial:    time.Second,
+		Max:        30 * time.Second,
+		Multiplier: 1.5,
+	}
+}
+
+// next returns the wait that follows wait when a poll saw no progress
+func (b *ImportBackoff) next(wait time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait = time.Duration(float64(wait) * multiplier)
+	if b.Max > 0 && wait > b.Max {
+		wait = b.Max
+	}
+	return wait
+}
+
+// ImportEvent is sent by WatchImport when an import item is first seen or
+// changes status. The last event on the channel has Done set.
+type ImportEvent struct {
+	// Item is the import item in its new status
+	Item ImportItem
+
+	// PreviousStatus is the status of the item on the previous poll, or
+	// empty the first time the item is seen
+	PreviousStatus string
+
+	// Done is set on the final event, which carries Summary and Err
+	// instead of an item
+	Done bool
+
+	// Summary describes the import as of the last successful poll
+	Summary *ImportSummary
+
+	// Err is why the watch stopped before the import completed, such as a
+	// cancelled context or a failed request
+	Err error
+}
+
+// ImportSummary describes the outcome of an import
+type ImportSummary struct {
+	Import *Import
+
+	Completed int
+	Failed    int
+	Pending   int
+
+	// FailedURLs are the feed URLs of the items that failed to import
+	FailedURLs []string
+}
+
+// WatchImport polls an import until it completes and returns a channel of
+// per-item events. The channel ends with an event that has Done set and is
+// then closed. Cancelling ctx stops the watch; the final event then carries
+// ctx.Err() and the summary of the last poll.
+//
+// The caller must receive from the channel until it is closed.
+func (c *Client) WatchImport(ctx context.Context, id int, backoff *ImportBackoff) <-chan ImportEvent {
+	if backoff == nil {
+		backoff = DefaultImportBackoff()
+	}
+
+	events := make(chan ImportEvent)
+	go func() {
+		defer close(events)
+
+		summary, err := c.watchImport(ctx, id, backoff, events)
+		events <- ImportEvent{Done: true, Summary: summary, Err: err}
+	}()
+
+	return events
+}
+
+// watchImport runs the polling loop of WatchImport
+func (c *Client) watchImport(ctx context.Context, id int, backoff *ImportBackoff, events chan<- ImportEvent) (*ImportSummary, error) {
+	// Import items have no ID, so they are told apart by feed URL
+	statuses := make(map[string]string)
+	var summary *ImportSummary
+	wait := backoff.Initial
+
 	for {
-		importResult, err := c.GetImport(id)
+		importResult, err := c.GetImportContext(ctx, id)
 		if err != nil {
-			return nil, err
+			return summary, err
+		}
+		summary = summarizeImport(importResult)
+
+		progress := false
+		for _, item := range importResult.ImportItems {
+			previous, seen := statuses[item.FeedURL]
+			if seen && previous == item.Status {
+				continue
+			}
+			statuses[item.FeedURL] = item.Status
+			progress = progress || seen
+
+			select {
+			case events <- ImportEvent{Item: item, PreviousStatus: previous}:
+			case <-ctx.Done():
+				return summary, ctx.Err()
+			}
 		}
 
 		if importResult.Complete {
-			return importResult, nil
+			return summary, nil
 		}
 
-		// Wait before polling again
-		time.Sleep(2 * time.Second)
+		if progress {
+			wait = backoff.Initial
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return summary, ctx.Err()
+		case <-timer.C:
+		}
+		wait = backoff.next(wait)
+	}
+}
+
+// summarizeImport counts the items of an import by status
+func summarizeImport(importResult *Import) *ImportSummary {
+	summary := &ImportSummary{Import: importResult}
+	for _, item := range importResult.ImportItems {
+		switch item.Status {
+		case ImportItemComplete:
+			summary.Completed++
+		case ImportItemFailed:
+			summary.Failed++
+			summary.FailedURLs = append(summary.FailedURLs, item.FeedURL)
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
+
+// WaitForImport watches an import until it completes or ctx is done and
+// returns its summary, discarding the per-item events. A nil backoff uses
+// DefaultImportBackoff.
+func (c *Client) WaitForImport(ctx context.Context, id int, backoff *ImportBackoff) (*ImportSummary, error) {
+	for event := range c.WatchImport(ctx, id, backoff) {
+		if event.Done {
+			return event.Summary, event.Err
+		}
 	}
+	return nil, ctx.Err()
 }
@@ -0,0 +1,82 @@
+package feedbin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestWatchImport polls spec-shaped import bodies, whose items have no ID,
+// and checks the per-feed events and the summary
+func TestWatchImport(t *testing.T) {
+	polls := []string{
+		`{"id": 6, "complete": false, "import_items": [
+			{"title": "Daring Fireball", "feed_url": "http://daringfireball.net/feeds/main", "status": "pending"},
+			{"title": "inessential.com", "feed_url": "http://inessential.com/xml/rss.xml", "status": "pending"},
+			{"title": "kottke.org", "feed_url": "http://feeds.kottke.org/main", "status": "pending"}]}`,
+		`{"id": 6, "complete": false, "import_items": [
+			{"title": "Daring Fireball", "feed_url": "http://daringfireball.net/feeds/main", "status": "complete"},
+			{"title": "inessential.com", "feed_url": "http://inessential.com/xml/rss.xml", "status": "pending"},
+			{"title": "kottke.org", "feed_url": "http://feeds.kottke.org/main", "status": "failed"}]}`,
+		`{"id": 6, "complete": false, "import_items": [
+			{"title": "Daring Fireball", "feed_url": "http://daringfireball.net/feeds/main", "status": "complete"},
+			{"title": "inessential.com", "feed_url": "http://inessential.com/xml/rss.xml", "status": "pending"},
+			{"title": "kottke.org", "feed_url": "http://feeds.kottke.org/main", "status": "failed"}]}`,
+		`{"id": 6, "complete": true, "import_items": [
+			{"title": "Daring Fireball", "feed_url": "http://daringfireball.net/feeds/main", "status": "complete"},
+			{"title": "inessential.com", "feed_url": "http://inessential.com/xml/rss.xml", "status": "complete"},
+			{"title": "kottke.org", "feed_url": "http://feeds.kottke.org/main", "status": "failed"}]}`,
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/imports/6.json" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(polls[requests]))
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass")
+	client.BaseURL = server.URL
+
+	backoff := &ImportBackoff{Initial: time.Millisecond, Max: time.Millisecond, Multiplier: 2}
+	var got []string
+	var summary *ImportSummary
+	for event := range client.WatchImport(context.Background(), 6, backoff) {
+		if event.Done {
+			if event.Err != nil {
+				t.Fatalf("WatchImport returned error: %v", event.Err)
+			}
+			summary = event.Summary
+			continue
+		}
+		got = append(got, event.Item.FeedURL+" "+event.PreviousStatus+"->"+event.Item.Status)
+	}
+
+	want := []string{
+		"http://daringfireball.net/feeds/main ->pending",
+		"http://inessential.com/xml/rss.xml ->pending",
+		"http://feeds.kottke.org/main ->pending",
+		"http://daringfireball.net/feeds/main pending->complete",
+		"http://feeds.kottke.org/main pending->failed",
+		"http://inessential.com/xml/rss.xml pending->complete",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WatchImport events = %v, want %v", got, want)
+	}
+	if requests != len(polls) {
+		t.Errorf("WatchImport polled %d times, want %d", requests, len(polls))
+	}
+	if summary == nil || summary.Completed != 2 || summary.Failed != 1 || summary.Pending != 0 {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+	if !reflect.DeepEqual(summary.FailedURLs, []string{"http://feeds.kottke.org/main"}) {
+		t.Errorf("FailedURLs = %v", summary.FailedURLs)
+	}
+}
@@ -107,16 +107,11 @@ type Import struct {
 	ImportItems []ImportItem `json:"import_items"`
 }
 
-// ImportItem represents a single item in an import
+// ImportItem represents a single feed in an import
 type ImportItem struct {
-	ID            int     `json:"id"`
-	ImportID      int     `json:"import_id"`
-	Details       string  `json:"details"`
-	FeedID        *int    `json:"feed_id,omitempty"`
-	Status        string  `json:"status"`
-	OriginalTitle string  `json:"original_title"`
-	OriginalURL   string  `json:"original_url"`
-	Message       *string `json:"message,omitempty"`
+	Title   string `json:"title"`
+	FeedURL string `json:"feed_url"`
+	Status  string `json:"status"`
 }
 
 // Page represents a saved web page
@@ -0,0 +1,208 @@
+package feedbin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that fail with a
+// network error, a 429 Too Many Requests or a 5xx response
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the exponential backoff between attempts
+	MaxBackoff time.Duration
+
+	// Multiplier grows the backoff after every attempt
+	Multiplier float64
+
+	// Jitter is the fraction of each backoff that is randomized, from 0 to 1
+	Jitter float64
+
+	// MaxRetryAfter is the longest server-requested Retry-After the client
+	// will wait for. Longer waits fail immediately. Zero means no limit.
+	MaxRetryAfter time.Duration
+
+	// RetryNonIdempotent allows retrying POST and PATCH requests, such as
+	// StarEntries or MarkEntriesUnread. Request bodies are always buffered
+	// so that they can be replayed.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxRetryAfter:  2 * time.Minute,
+	}
+}
+
+// SetRetryPolicy replaces the client's retry policy. A nil policy disables retries.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.RetryPolicy = policy
+}
+
+// allowsMethod reports whether requests with the given method may be retried
+func (p *RetryPolicy) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return p.RetryNonIdempotent
+	}
+}
+
+// backoff returns the jittered wait before the given retry (1 for the first retry)
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	if jitter > 0 {
+		wait -= wait * jitter * rand.Float64()
+	}
+
+	return time.Duration(wait)
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests ||
+		(code >= 500 && code != http.StatusNotImplemented)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date. It returns zero when the header is missing or invalid.
+func parseRetryAfter(header http.Header) time.Duration {
+	val := strings.TrimSpace(header.Get("Retry-After"))
+	if val == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(val); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(val); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// do sends an authenticated request, retrying it according to the client's
+// retry policy. The body is kept in memory and replayed on every attempt.
+// Responses with a status of 400 or above are returned as errors.
+func (c *Client) do(method, rawURL string, body []byte, contentType string) (*http.Response, error) {
+	return c.doContext(context.Background(), method, rawURL, body, contentType)
+}
+
+// doContext is do with a context that cancels the request and the waits
+// between attempts
+func (c *Client) doContext(ctx context.Context, method, rawURL string, body []byte, contentType string) (*http.Response, error) {
+	policy := c.RetryPolicy
+	attempts := 1
+	if policy != nil && policy.MaxAttempts > 1 && policy.allowsMethod(method) {
+		attempts = policy.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		req, err := c.newRequest(ctx, method, rawURL, body, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		resp, err := c.HTTPClient.Do(req)
+		switch {
+		case err != nil:
+			lastErr = fmt.Errorf("request failed: %w", err)
+		case resp.StatusCode >= 400:
+			retryAfter = parseRetryAfter(resp.Header)
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = newAPIError(resp, string(bodyBytes))
+			if !isRetryableStatus(resp.StatusCode) {
+				return nil, lastErr
+			}
+		default:
+			return resp, nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		wait := policy.backoff(attempt)
+		if retryAfter > 0 {
+			if policy.MaxRetryAfter > 0 && retryAfter > policy.MaxRetryAfter {
+				return nil, lastErr
+			}
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
+
+// newRequest creates an authenticated request with a fresh reader over body
+func (c *Client) newRequest(ctx context.Context, method, rawURL string, body []byte, contentType string) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(c.Email, c.Password)
+	req.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
@@ -0,0 +1,224 @@
+package feedbin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first len(failures) requests with the given status
+// codes and Retry-After headers, then answers with body. It records the
+// request body of every attempt.
+type flakyServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures []flakyFailure
+	bodies   []string
+}
+
+type flakyFailure struct {
+	status     int
+	retryAfter string
+}
+
+func newFlakyServer(t *testing.T, body string, failures ...flakyFailure) *flakyServer {
+	s := &flakyServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		attempt := len(s.bodies)
+		s.bodies = append(s.bodies, string(data))
+		s.mu.Unlock()
+
+		if attempt < len(s.failures) {
+			failure := s.failures[attempt]
+			if failure.retryAfter != "" {
+				w.Header().Set("Retry-After", failure.retryAfter)
+			}
+			w.WriteHeader(failure.status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *flakyServer) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func newRetryTestClient(url string, policy *RetryPolicy) *Client {
+	client := NewClient("user", "pass")
+	client.BaseURL = url
+	client.SetRetryPolicy(policy)
+	return client
+}
+
+func fastRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+func TestRetry_FailsThenSucceeds(t *testing.T) {
+	server := newFlakyServer(t, `[{"id": 1, "feed_id": 10}]`,
+		flakyFailure{status: http.StatusTooManyRequests},
+		flakyFailure{status: http.StatusServiceUnavailable},
+	)
+	client := newRetryTestClient(server.URL, fastRetryPolicy())
+
+	subscriptions, err := client.GetSubscriptions()
+	if err != nil {
+		t.Fatalf("GetSubscriptions returned error: %v", err)
+	}
+	if len(subscriptions) != 1 || subscriptions[0].FeedID != 10 {
+		t.Errorf("unexpected subscriptions %+v", subscriptions)
+	}
+	if n := server.attempts(); n != 3 {
+		t.Errorf("sent %d attempts, want 3", n)
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	failure := flakyFailure{status: http.StatusBadGateway}
+	server := newFlakyServer(t, `[]`, failure, failure, failure, failure, failure)
+	client := newRetryTestClient(server.URL, fastRetryPolicy())
+
+	_, err := client.GetSubscriptions()
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || serverErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("error = %v, want a 502 *ServerError", err)
+	}
+	if n := server.attempts(); n != 4 {
+		t.Errorf("sent %d attempts, want MaxAttempts = 4", n)
+	}
+}
+
+func TestRetry_NotFoundIsNotRetried(t *testing.T) {
+	server := newFlakyServer(t, `[]`, flakyFailure{status: http.StatusNotFound})
+	client := newRetryTestClient(server.URL, fastRetryPolicy())
+
+	if _, err := client.GetSubscriptions(); err == nil {
+		t.Error("Expected an error for a 404")
+	}
+	if n := server.attempts(); n != 1 {
+		t.Errorf("sent %d attempts, want 1", n)
+	}
+}
+
+func TestRetry_RetryAfterSeconds(t *testing.T) {
+	server := newFlakyServer(t, `[]`, flakyFailure{status: http.StatusTooManyRequests, retryAfter: "1"})
+	client := newRetryTestClient(server.URL, fastRetryPolicy())
+
+	start := time.Now()
+	if _, err := client.GetSubscriptions(); err != nil {
+		t.Fatalf("GetSubscriptions returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want the 1s Retry-After", elapsed)
+	}
+	if n := server.attempts(); n != 2 {
+		t.Errorf("sent %d attempts, want 2", n)
+	}
+}
+
+func TestRetry_RetryAfterTooLong(t *testing.T) {
+	server := newFlakyServer(t, `[]`, flakyFailure{status: http.StatusServiceUnavailable, retryAfter: "3600"})
+	policy := fastRetryPolicy()
+	policy.MaxRetryAfter = time.Minute
+	client := newRetryTestClient(server.URL, policy)
+
+	if _, err := client.GetSubscriptions(); err == nil {
+		t.Error("Expected an error when Retry-After exceeds MaxRetryAfter")
+	}
+	if n := server.attempts(); n != 1 {
+		t.Errorf("sent %d attempts, want 1", n)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	header := func(v string) http.Header {
+		h := http.Header{}
+		if v != "" {
+			h.Set("Retry-After", v)
+		}
+		return h
+	}
+
+	if got := parseRetryAfter(header("120")); got != 2*time.Minute {
+		t.Errorf("parseRetryAfter(120) = %v, want 2m", got)
+	}
+	for _, v := range []string{"", "-5", "soon", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)} {
+		if got := parseRetryAfter(header(v)); got != 0 {
+			t.Errorf("parseRetryAfter(%q) = %v, want 0", v, got)
+		}
+	}
+
+	date := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(header(date)); got < 88*time.Second || got > 90*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want about 90s", date, got)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	for retry, want := range []time.Duration{100, 200, 300, 300} {
+		if got := policy.backoff(retry + 1); got != want*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", retry+1, got, want*time.Millisecond)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.backoff(2); got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("jittered backoff(2) = %v, want between 100ms and 200ms", got)
+		}
+	}
+}
+
+func TestRetry_NonIdempotent(t *testing.T) {
+	failure := flakyFailure{status: http.StatusServiceUnavailable}
+
+	// POST is not retried unless the policy opts in
+	server := newFlakyServer(t, `[1, 2, 3]`, failure, failure)
+	client := newRetryTestClient(server.URL, fastRetryPolicy())
+	if err := client.StarEntries([]int{1, 2, 3}); err == nil {
+		t.Error("Expected StarEntries to fail without retries")
+	}
+	if n := server.attempts(); n != 1 {
+		t.Errorf("sent %d attempts without the opt-in, want 1", n)
+	}
+
+	server = newFlakyServer(t, `[1, 2, 3]`, failure, failure)
+	policy := fastRetryPolicy()
+	policy.RetryNonIdempotent = true
+	client = newRetryTestClient(server.URL, policy)
+	if err := client.StarEntries([]int{1, 2, 3}); err != nil {
+		t.Fatalf("StarEntries returned error: %v", err)
+	}
+	if n := server.attempts(); n != 3 {
+		t.Fatalf("sent %d attempts, want 3", n)
+	}
+
+	// The body is replayed in full on every attempt
+	want := `{"starred_entries":[1,2,3]}`
+	for i, body := range server.bodies {
+		if body != want {
+			t.Errorf("attempt %d body = %s, want %s", i+1, strconv.Quote(body), want)
+		}
+	}
+}
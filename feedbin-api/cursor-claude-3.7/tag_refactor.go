@@ -0,0 +1,433 @@
+package feedbin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TagOp is the kind of a TagStep
+type TagOp string
+
+const (
+	// TagCreate adds a feed to a tag
+	TagCreate TagOp = "create"
+	// TagDelete removes a feed from a tag
+	TagDelete TagOp = "delete"
+)
+
+// TagStep is a tagging to create or delete
+type TagStep struct {
+	Op     TagOp  `json:"op"`
+	FeedID int    `json:"feed_id"`
+	Name   string `json:"name"`
+
+	// TaggingID is the tagging to delete, or the tagging that was created
+	// once a create step has been applied
+	TaggingID int `json:"tagging_id,omitempty"`
+}
+
+// String formats the step for a preview
+func (s TagStep) String() string {
+	if s.Op == TagDelete {
+		return fmt.Sprintf("- feed %d from %q (tagging %d)", s.FeedID, s.Name, s.TaggingID)
+	}
+	return fmt.Sprintf("+ feed %d to %q", s.FeedID, s.Name)
+}
+
+// TagPlan is a diff of taggings to create and delete, computed against the
+// current taggings. Creates come before deletes, so that a feed moved
+// between tags is never left untagged if applying the plan fails midway.
+type TagPlan struct {
+	Steps []TagStep
+}
+
+// Empty reports whether the plan changes nothing
+func (p *TagPlan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String formats the plan as a preview, one step per line
+func (p *TagPlan) String() string {
+	var b strings.Builder
+	for _, step := range p.Steps {
+		b.WriteString(step.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// TagSplitRule moves the feeds whose feed URL matches Pattern to Tag
+type TagSplitRule struct {
+	Pattern *regexp.Regexp
+	Tag     string
+}
+
+// TagProgress reports the progress of TagsService.Apply
+type TagProgress struct {
+	Done  int // Number of steps applied, including Step
+	Total int
+	Step  TagStep
+}
+
+// TagApplyOptions specifies the optional parameters to the
+// TagsService.Apply method
+type TagApplyOptions struct {
+	// Progress is called after each applied step
+	Progress func(TagProgress)
+
+	// Log receives each applied step as a line of JSON as soon as it is
+	// applied, so that the changes can be rolled back with ReadTagLog even
+	// if the process does not finish
+	Log io.Writer
+}
+
+// TagApplyResult is the rollback log of TagsService.Apply
+type TagApplyResult struct {
+	// Applied are the steps that were applied, in order. Create steps have
+	// the ID of the created tagging.
+	Applied []TagStep
+}
+
+// RollbackPlan returns the plan that undoes the applied steps, in reverse order
+func (r *TagApplyResult) RollbackPlan() *TagPlan {
+	plan := &TagPlan{}
+	for i := len(r.Applied) - 1; i >= 0; i-- {
+		step := r.Applied[i]
+		if step.Op == TagCreate {
+			plan.Steps = append(plan.Steps, TagStep{Op: TagDelete, FeedID: step.FeedID, Name: step.Name, TaggingID: step.TaggingID})
+		} else {
+			plan.Steps = append(plan.Steps, TagStep{Op: TagCreate, FeedID: step.FeedID, Name: step.Name})
+		}
+	}
+	return plan
+}
+
+// TagStepError is returned by TagsService.Apply when a step fails
+type TagStepError struct {
+	Step TagStep
+	Err  error
+}
+
+func (e *TagStepError) Error() string {
+	return fmt.Sprintf("tag step %s failed: %v", strings.TrimSpace(e.Step.String()), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TagStepError) Unwrap() error {
+	return e.Err
+}
+
+// ReadTagLog reads a log written through TagApplyOptions.Log
+func ReadTagLog(r io.Reader) (*TagApplyResult, error) {
+	result := &TagApplyResult{}
+	decoder := json.NewDecoder(r)
+	for {
+		var step TagStep
+		err := decoder.Decode(&step)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, fmt.Errorf("reading tag log: %w", err)
+		}
+		result.Applied = append(result.Applied, step)
+	}
+}
+
+// PlanMerge plans merging the source tags into target. Every feed tagged
+// with a source tag is tagged with target and the source taggings are
+// removed. target may be one of the sources or a new tag.
+func (s *TagsService) PlanMerge(sources []string, target string) (*TagPlan, error) {
+	if strings.TrimSpace(target) == "" {
+		return nil, fmt.Errorf("target tag name must be provided")
+	}
+
+	taggings, _, err := s.client.Taggings.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return planMerge(taggings, sources, target), nil
+}
+
+// PlanSplit plans splitting a tag by feed URL. Feeds tagged with tag whose
+// feed URL matches a rule move to the rule's tag; the first matching rule
+// wins and feeds matching no rule keep the tag.
+func (s *TagsService) PlanSplit(tag string, rules []TagSplitRule) (*TagPlan, error) {
+	for _, rule := range rules {
+		if rule.Pattern == nil || strings.TrimSpace(rule.Tag) == "" {
+			return nil, fmt.Errorf("split rules need a pattern and a tag name")
+		}
+	}
+
+	taggings, _, err := s.client.Taggings.List()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, _, err := s.client.Subscriptions.List(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return planSplit(taggings, subscriptions, tag, rules), nil
+}
+
+// PlanMove plans moving feeds from one tag to another. If from is empty the
+// feeds are removed from all their tags, and if to is empty they are only
+// removed.
+func (s *TagsService) PlanMove(feedIDs []int, from, to string) (*TagPlan, error) {
+	taggings, _, err := s.client.Taggings.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return planMove(taggings, feedIDs, from, to), nil
+}
+
+// PlanDropEmpty plans removing the tags that have no subscribed feeds left.
+// Their remaining taggings, which refer to feeds that were unsubscribed,
+// are deleted.
+func (s *TagsService) PlanDropEmpty() (*TagPlan, error) {
+	taggings, _, err := s.client.Taggings.List()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, _, err := s.client.Subscriptions.List(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return planDropEmpty(taggings, subscriptions), nil
+}
+
+// Apply applies a plan one step at a time. If a step fails, the steps
+// applied so far are returned together with a *TagStepError, and
+// result.RollbackPlan undoes them.
+func (s *TagsService) Apply(plan *TagPlan, opts *TagApplyOptions) (*TagApplyResult, error) {
+	if opts == nil {
+		opts = &TagApplyOptions{}
+	}
+
+	result := &TagApplyResult{}
+	for i, step := range plan.Steps {
+		switch step.Op {
+		case TagCreate:
+			tagging, _, err := s.client.Taggings.Create(step.FeedID, 0, step.Name)
+			if err != nil {
+				return result, &TagStepError{Step: step, Err: err}
+			}
+			step.TaggingID = tagging.ID
+		case TagDelete:
+			resp, err := s.client.Taggings.Delete(step.TaggingID)
+			// A tagging that is already gone needs no deleting
+			if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+				return result, &TagStepError{Step: step, Err: err}
+			}
+		default:
+			return result, &TagStepError{Step: step, Err: fmt.Errorf("unknown operation %q", step.Op)}
+		}
+
+		result.Applied = append(result.Applied, step)
+
+		if opts.Log != nil {
+			if err := json.NewEncoder(opts.Log).Encode(step); err != nil {
+				return result, fmt.Errorf("writing tag log: %w", err)
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(TagProgress{Done: i + 1, Total: len(plan.Steps), Step: step})
+		}
+	}
+
+	return result, nil
+}
+
+// Rollback undoes the steps in a rollback log
+func (s *TagsService) Rollback(log *TagApplyResult, opts *TagApplyOptions) (*TagApplyResult, error) {
+	return s.Apply(log.RollbackPlan(), opts)
+}
+
+// tagPlanner builds a TagPlan from a snapshot of the taggings
+type tagPlanner struct {
+	byFeed  map[int]map[string]*Tagging
+	created map[int]map[string]bool
+	deleted map[int]bool
+	creates []TagStep
+	deletes []TagStep
+}
+
+// newTagPlanner indexes taggings by feed and tag name
+func newTagPlanner(taggings []*Tagging) *tagPlanner {
+	p := &tagPlanner{
+		byFeed:  make(map[int]map[string]*Tagging),
+		created: make(map[int]map[string]bool),
+		deleted: make(map[int]bool),
+	}
+	for _, tagging := range taggings {
+		if p.byFeed[tagging.FeedID] == nil {
+			p.byFeed[tagging.FeedID] = make(map[string]*Tagging)
+		}
+		p.byFeed[tagging.FeedID][tagging.Name] = tagging
+	}
+	return p
+}
+
+// has reports whether a feed is tagged with name after the planned steps
+func (p *tagPlanner) has(feedID int, name string) bool {
+	if p.created[feedID][name] {
+		return true
+	}
+	tagging, ok := p.byFeed[feedID][name]
+	return ok && !p.deleted[tagging.ID]
+}
+
+// tag plans tagging a feed with name unless it already is
+func (p *tagPlanner) tag(feedID int, name string) {
+	if name == "" || p.has(feedID, name) {
+		return
+	}
+	if p.created[feedID] == nil {
+		p.created[feedID] = make(map[string]bool)
+	}
+	p.created[feedID][name] = true
+	p.creates = append(p.creates, TagStep{Op: TagCreate, FeedID: feedID, Name: name})
+}
+
+// untag plans removing name from a feed if it is tagged with it
+func (p *tagPlanner) untag(feedID int, name string) {
+	tagging, ok := p.byFeed[feedID][name]
+	if !ok || p.deleted[tagging.ID] {
+		return
+	}
+	p.deleted[tagging.ID] = true
+	p.deletes = append(p.deletes, TagStep{Op: TagDelete, FeedID: feedID, Name: name, TaggingID: tagging.ID})
+}
+
+// feedsTagged returns the IDs of the feeds tagged with name, in ascending order
+func (p *tagPlanner) feedsTagged(name string) []int {
+	var feedIDs []int
+	for feedID, tags := range p.byFeed {
+		if _, ok := tags[name]; ok {
+			feedIDs = append(feedIDs, feedID)
+		}
+	}
+	sort.Ints(feedIDs)
+	return feedIDs
+}
+
+// plan returns the planned steps, creates first
+func (p *tagPlanner) plan() *TagPlan {
+	return &TagPlan{Steps: append(p.creates, p.deletes...)}
+}
+
+// planMerge plans merging sources into target
+func planMerge(taggings []*Tagging, sources []string, target string) *TagPlan {
+	p := newTagPlanner(taggings)
+	for _, source := range sources {
+		if source == target {
+			continue
+		}
+		for _, feedID := range p.feedsTagged(source) {
+			p.tag(feedID, target)
+			p.untag(feedID, source)
+		}
+	}
+	return p.plan()
+}
+
+// planSplit plans splitting tag by feed URL
+func planSplit(taggings []*Tagging, subscriptions []*Subscription, tag string, rules []TagSplitRule) *TagPlan {
+	feedURLs := make(map[int]string, len(subscriptions))
+	for _, subscription := range subscriptions {
+		feedURLs[subscription.FeedID] = subscription.FeedURL
+	}
+
+	p := newTagPlanner(taggings)
+	for _, feedID := range p.feedsTagged(tag) {
+		feedURL, ok := feedURLs[feedID]
+		if !ok {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.Pattern.MatchString(feedURL) {
+				continue
+			}
+			if rule.Tag != tag {
+				p.tag(feedID, rule.Tag)
+				p.untag(feedID, tag)
+			}
+			break
+		}
+	}
+	return p.plan()
+}
+
+// planMove plans moving feeds from one tag to another
+func planMove(taggings []*Tagging, feedIDs []int, from, to string) *TagPlan {
+	p := newTagPlanner(taggings)
+	for _, feedID := range feedIDs {
+		p.tag(feedID, to)
+
+		if from != "" {
+			if from != to {
+				p.untag(feedID, from)
+			}
+			continue
+		}
+
+		names := make([]string, 0, len(p.byFeed[feedID]))
+		for name := range p.byFeed[feedID] {
+			if name != to {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p.untag(feedID, name)
+		}
+	}
+	return p.plan()
+}
+
+// planDropEmpty plans deleting the taggings of tags without subscribed feeds
+func planDropEmpty(taggings []*Tagging, subscriptions []*Subscription) *TagPlan {
+	subscribed := make(map[int]bool, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscribed[subscription.FeedID] = true
+	}
+
+	byName := make(map[string][]*Tagging)
+	var names []string
+	for _, tagging := range taggings {
+		if _, ok := byName[tagging.Name]; !ok {
+			names = append(names, tagging.Name)
+		}
+		byName[tagging.Name] = append(byName[tagging.Name], tagging)
+	}
+	sort.Strings(names)
+
+	p := newTagPlanner(taggings)
+	for _, name := range names {
+		empty := true
+		for _, tagging := range byName[name] {
+			if subscribed[tagging.FeedID] {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			continue
+		}
+		for _, tagging := range byName[name] {
+			p.untag(tagging.FeedID, name)
+		}
+	}
+	return p.plan()
+}
@@ -0,0 +1,174 @@
+package feedbin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// testTaggings are the taggings the planners are tested against. Feed 5
+// is no longer subscribed.
+func testTaggings() []*Tagging {
+	return []*Tagging{
+		{ID: 1, FeedID: 1, Name: "Go"},
+		{ID: 2, FeedID: 2, Name: "Golang"},
+		{ID: 3, FeedID: 2, Name: "Go"},
+		{ID: 4, FeedID: 3, Name: "Golang"},
+		{ID: 5, FeedID: 4, Name: "Rust"},
+		{ID: 6, FeedID: 5, Name: "Old"},
+	}
+}
+
+func testSubscriptions() []*Subscription {
+	return []*Subscription{
+		{FeedID: 1, FeedURL: "https://go.dev/blog/feed.atom"},
+		{FeedID: 2, FeedURL: "https://github.com/golang/go/releases.atom"},
+		{FeedID: 3, FeedURL: "https://github.com/golang/tools/releases.atom"},
+		{FeedID: 4, FeedURL: "https://blog.rust-lang.org/feed.xml"},
+	}
+}
+
+func checkPlan(t *testing.T, plan *TagPlan, want []TagStep) {
+	t.Helper()
+	if !reflect.DeepEqual(plan.Steps, want) {
+		t.Errorf("plan =\n%s\nwant\n%s", plan, &TagPlan{Steps: want})
+	}
+	deleting := false
+	for _, step := range plan.Steps {
+		if step.Op == TagDelete {
+			deleting = true
+		} else if deleting {
+			t.Errorf("create step %s comes after a delete", step)
+		}
+	}
+}
+
+func TestPlanMerge(t *testing.T) {
+	plan := planMerge(testTaggings(), []string{"Golang", "Go"}, "Go")
+	checkPlan(t, plan, []TagStep{
+		{Op: TagCreate, FeedID: 3, Name: "Go"},
+		{Op: TagDelete, FeedID: 2, Name: "Golang", TaggingID: 2},
+		{Op: TagDelete, FeedID: 3, Name: "Golang", TaggingID: 4},
+	})
+
+	if plan := planMerge(testTaggings(), []string{"Go"}, "Go"); !plan.Empty() {
+		t.Errorf("merging a tag into itself planned %s", plan)
+	}
+}
+
+func TestPlanSplit(t *testing.T) {
+	rules := []TagSplitRule{
+		{Pattern: regexp.MustCompile(`/golang/tools/`), Tag: "Tools"},
+		{Pattern: regexp.MustCompile(`github\.com`), Tag: "Releases"},
+		{Pattern: regexp.MustCompile(`go\.dev`), Tag: "Go"},
+	}
+	plan := planSplit(testTaggings(), testSubscriptions(), "Go", rules)
+	checkPlan(t, plan, []TagStep{
+		{Op: TagCreate, FeedID: 2, Name: "Releases"},
+		{Op: TagDelete, FeedID: 2, Name: "Go", TaggingID: 3},
+	})
+
+	// The first matching rule wins
+	plan = planSplit(testTaggings(), testSubscriptions(), "Golang", rules)
+	checkPlan(t, plan, []TagStep{
+		{Op: TagCreate, FeedID: 2, Name: "Releases"},
+		{Op: TagCreate, FeedID: 3, Name: "Tools"},
+		{Op: TagDelete, FeedID: 2, Name: "Golang", TaggingID: 2},
+		{Op: TagDelete, FeedID: 3, Name: "Golang", TaggingID: 4},
+	})
+}
+
+func TestPlanMove(t *testing.T) {
+	plan := planMove(testTaggings(), []int{1, 3}, "Go", "Golang")
+	checkPlan(t, plan, []TagStep{
+		{Op: TagCreate, FeedID: 1, Name: "Golang"},
+		{Op: TagDelete, FeedID: 1, Name: "Go", TaggingID: 1},
+	})
+
+	// Without from, the feed leaves all its tags
+	plan = planMove(testTaggings(), []int{2}, "", "Misc")
+	checkPlan(t, plan, []TagStep{
+		{Op: TagCreate, FeedID: 2, Name: "Misc"},
+		{Op: TagDelete, FeedID: 2, Name: "Go", TaggingID: 3},
+		{Op: TagDelete, FeedID: 2, Name: "Golang", TaggingID: 2},
+	})
+
+	// Without to, the feed is only removed
+	plan = planMove(testTaggings(), []int{4}, "Rust", "")
+	checkPlan(t, plan, []TagStep{
+		{Op: TagDelete, FeedID: 4, Name: "Rust", TaggingID: 5},
+	})
+}
+
+func TestPlanDropEmpty(t *testing.T) {
+	plan := planDropEmpty(testTaggings(), testSubscriptions())
+	checkPlan(t, plan, []TagStep{
+		{Op: TagDelete, FeedID: 5, Name: "Old", TaggingID: 6},
+	})
+}
+
+func TestTagsService_ApplyRollback(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	nextID := 100
+	mux.HandleFunc("/taggings.json", func(w http.ResponseWriter, r *http.Request) {
+		var req CreateTaggingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": %d, "feed_id": %d, "name": %q}`, nextID, req.FeedID, req.Name)
+		nextID++
+	})
+	mux.HandleFunc("/taggings/2.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/taggings/4.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	client := NewClient("user", "pass")
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	plan := planMerge(testTaggings(), []string{"Golang"}, "Go")
+	var log bytes.Buffer
+	var progress []TagProgress
+	result, err := client.Tags.Apply(plan, &TagApplyOptions{
+		Log:      &log,
+		Progress: func(p TagProgress) { progress = append(progress, p) },
+	})
+
+	var stepErr *TagStepError
+	if !errors.As(err, &stepErr) || stepErr.Step.TaggingID != 4 {
+		t.Fatalf("Apply error = %v, want a *TagStepError for tagging 4", err)
+	}
+	if len(progress) != 2 || progress[1].Done != 2 || progress[1].Total != 3 {
+		t.Errorf("progress = %+v", progress)
+	}
+
+	// Only the two applied steps are undone, in reverse order
+	checkRollback := func(result *TagApplyResult) {
+		t.Helper()
+		want := []TagStep{
+			{Op: TagCreate, FeedID: 2, Name: "Golang"},
+			{Op: TagDelete, FeedID: 3, Name: "Go", TaggingID: 100},
+		}
+		if got := result.RollbackPlan().Steps; !reflect.DeepEqual(got, want) {
+			t.Errorf("RollbackPlan = %+v, want %+v", got, want)
+		}
+	}
+	checkRollback(result)
+
+	logged, err := ReadTagLog(&log)
+	if err != nil {
+		t.Fatalf("ReadTagLog returned error: %v", err)
+	}
+	checkRollback(logged)
+}
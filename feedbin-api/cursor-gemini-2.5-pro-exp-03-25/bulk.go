@@ -0,0 +1,144 @@
+package feedbin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// maxEntryIDsPerRequest is the API limit for unread and starred entry IDs per request.
+	maxEntryIDsPerRequest = 1000
+	// defaultBulkConcurrency is the number of batches sent in parallel by default.
+	defaultBulkConcurrency = 4
+)
+
+// BulkResult is the combined result of a bulk entry-ID operation that was
+// split into batches of at most 1000 IDs.
+type BulkResult struct {
+	// Acknowledged lists the IDs the API reported as updated, in batch order.
+	Acknowledged []int64
+	// Errors holds one BatchError for every batch that failed.
+	Errors []*BatchError
+}
+
+// BatchError describes a batch of entry IDs that could not be sent.
+type BatchError struct {
+	EntryIDs []int64
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch of %d entry IDs failed: %v", len(e.EntryIDs), e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// FailedIDs returns the IDs of every failed batch, so they can be retried.
+func (r *BulkResult) FailedIDs() []int64 {
+	var ids []int64
+	for _, batchErr := range r.Errors {
+		ids = append(ids, batchErr.EntryIDs...)
+	}
+	return ids
+}
+
+// Err returns the batch errors joined together, or nil if every batch succeeded.
+func (r *BulkResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Errors))
+	for i, batchErr := range r.Errors {
+		errs[i] = batchErr
+	}
+	return errors.Join(errs...)
+}
+
+// SetBulkConcurrency sets how many batches MarkEntriesAsRead, MarkEntriesAsUnread,
+// StarEntries and UnstarEntries send in parallel. Values below 1 are treated as 1.
+func (c *Client) SetBulkConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.bulkConcurrency = n
+}
+
+// chunkEntryIDs splits entryIDs into consecutive batches of at most size IDs.
+func chunkEntryIDs(entryIDs []int64, size int) [][]int64 {
+	var batches [][]int64
+	for start := 0; start < len(entryIDs); start += size {
+		end := start + size
+		if end > len(entryIDs) {
+			end = len(entryIDs)
+		}
+		batches = append(batches, entryIDs[start:end])
+	}
+	return batches
+}
+
+// bulkEntryIDs sends entryIDs to path in batches of at most 1000 IDs, using up to
+// c.bulkConcurrency requests at a time. The body of every request is
+// {key: [ids...]}. The returned error is the joined batch errors, if any; the
+// result is always non-nil and lists what was acknowledged.
+func (c *Client) bulkEntryIDs(ctx context.Context, method, path, key string, entryIDs []int64) (*BulkResult, error) {
+	batches := chunkEntryIDs(entryIDs, maxEntryIDsPerRequest)
+
+	concurrency := c.bulkConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	acknowledged := make([][]int64, len(batches))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, batch []int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			acknowledged[i], errs[i] = c.sendEntryIDs(ctx, method, path, key, batch)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	result := &BulkResult{Acknowledged: []int64{}}
+	for i, batch := range batches {
+		if errs[i] != nil {
+			result.Errors = append(result.Errors, &BatchError{EntryIDs: batch, Err: errs[i]})
+			continue
+		}
+		result.Acknowledged = append(result.Acknowledged, acknowledged[i]...)
+	}
+	return result, result.Err()
+}
+
+// sendEntryIDs sends a single batch of entry IDs and returns the IDs the API acknowledged.
+func (c *Client) sendEntryIDs(ctx context.Context, method, path, key string, entryIDs []int64) ([]int64, error) {
+	body := map[string][]int64{key: entryIDs}
+	resp, err := c.doRequest(ctx, method, path, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultIDs []int64
+	// Expect 200 OK with a body containing the IDs that were updated
+	_, err = c.handleResponse(resp, &resultIDs)
+	if err != nil {
+		return nil, err
+	}
+	return resultIDs, nil
+}
@@ -0,0 +1,182 @@
+package feedbin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBulkTestClient returns a client for a server that acknowledges every
+// ID it is sent, after calling handle with the decoded IDs. handle may
+// return a status code to fail the batch with.
+func newBulkTestClient(t *testing.T, handle func(r *http.Request, ids []int64) int) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]int64
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		var ids []int64
+		for _, v := range body {
+			ids = v
+		}
+		if status := handle(r, ids); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ids)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient("user", "pass", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.baseURL, _ = url.Parse(server.URL + "/")
+	return c
+}
+
+func entryIDRange(from, to int64) []int64 {
+	var ids []int64
+	for id := from; id <= to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestChunkEntryIDs(t *testing.T) {
+	tests := []struct {
+		n     int64
+		sizes []int
+	}{
+		{1, []int{1}},
+		{1000, []int{1000}},
+		{1001, []int{1000, 1}},
+		{2000, []int{1000, 1000}},
+		{2500, []int{1000, 1000, 500}},
+	}
+	for _, tt := range tests {
+		batches := chunkEntryIDs(entryIDRange(1, tt.n), maxEntryIDsPerRequest)
+		if len(batches) != len(tt.sizes) {
+			t.Errorf("chunkEntryIDs(%d IDs) = %d batches, want %d", tt.n, len(batches), len(tt.sizes))
+			continue
+		}
+		for i, batch := range batches {
+			if len(batch) != tt.sizes[i] {
+				t.Errorf("chunkEntryIDs(%d IDs) batch %d has %d IDs, want %d", tt.n, i, len(batch), tt.sizes[i])
+			}
+		}
+	}
+}
+
+func TestMarkEntriesAsRead_Batches(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	c := newBulkTestClient(t, func(r *http.Request, ids []int64) int {
+		if r.Method != http.MethodDelete || r.URL.Path != "/unread_entries.json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		mu.Lock()
+		sizes = append(sizes, len(ids))
+		mu.Unlock()
+		return 0
+	})
+
+	result, err := c.MarkEntriesAsRead(context.Background(), entryIDRange(1, 1001))
+	if err != nil {
+		t.Fatalf("MarkEntriesAsRead returned error: %v", err)
+	}
+	if len(result.Acknowledged) != 1001 || result.Acknowledged[0] != 1 || result.Acknowledged[1000] != 1001 {
+		t.Errorf("Acknowledged %d IDs, want 1..1001 in order", len(result.Acknowledged))
+	}
+	if len(sizes) != 2 || sizes[0]+sizes[1] != 1001 {
+		t.Errorf("batch sizes = %v, want 1000 and 1", sizes)
+	}
+}
+
+func TestAltEndpoints_Batches(t *testing.T) {
+	var requests int32
+	c := newBulkTestClient(t, func(r *http.Request, ids []int64) int {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		atomic.AddInt32(&requests, 1)
+		return 0
+	})
+
+	result, err := c.MarkEntriesAsReadAlt(context.Background(), entryIDRange(1, 1001))
+	if err != nil || len(result.Acknowledged) != 1001 {
+		t.Errorf("MarkEntriesAsReadAlt = %d IDs, %v", len(result.Acknowledged), err)
+	}
+	result, err = c.UnstarEntriesAlt(context.Background(), entryIDRange(1, 2000))
+	if err != nil || len(result.Acknowledged) != 2000 {
+		t.Errorf("UnstarEntriesAlt = %d IDs, %v", len(result.Acknowledged), err)
+	}
+	if requests != 4 {
+		t.Errorf("sent %d requests, want 4", requests)
+	}
+}
+
+func TestBulkEntryIDs_Concurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	c := newBulkTestClient(t, func(r *http.Request, ids []int64) int {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return 0
+	})
+	c.SetBulkConcurrency(3)
+
+	result, err := c.StarEntries(context.Background(), entryIDRange(1, 10000))
+	if err != nil {
+		t.Fatalf("StarEntries returned error: %v", err)
+	}
+	if len(result.Acknowledged) != 10000 {
+		t.Errorf("Acknowledged %d IDs, want 10000", len(result.Acknowledged))
+	}
+	if maxInFlight > 3 {
+		t.Errorf("%d batches were in flight at once, want at most 3", maxInFlight)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("batches were not sent in parallel")
+	}
+}
+
+func TestBulkEntryIDs_PartialFailure(t *testing.T) {
+	c := newBulkTestClient(t, func(r *http.Request, ids []int64) int {
+		if ids[0] == 1001 {
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+
+	result, err := c.UnstarEntries(context.Background(), entryIDRange(1, 2500))
+	if err == nil {
+		t.Fatal("Expected an error for the failed batch")
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.EntryIDs) != 1000 {
+		t.Errorf("error = %v, want a *BatchError for 1000 IDs", err)
+	}
+	if len(result.Acknowledged) != 1500 {
+		t.Errorf("Acknowledged %d IDs, want 1500", len(result.Acknowledged))
+	}
+	failed := result.FailedIDs()
+	if len(failed) != 1000 || failed[0] != 1001 || failed[999] != 2000 {
+		t.Errorf("FailedIDs = %d IDs, want 1001..2000", len(failed))
+	}
+}
@@ -26,6 +26,8 @@ type Client struct {
 	httpClient *http.Client
 	username   string
 	password   string
+
+	bulkConcurrency int
 }
 
 // NewClient creates a new Feedbin API client.
@@ -49,6 +51,8 @@ func NewClient(username, password string, httpClient *http.Client) (*Client, err
 		httpClient: httpClient,
 		username:   username,
 		password:   password,
+
+		bulkConcurrency: defaultBulkConcurrency,
 	}, nil
 }
 
@@ -545,80 +549,35 @@ func (c *Client) ListUnreadEntries(ctx context.Context) ([]int64, error) {
 }
 
 // MarkEntriesAsUnread marks the specified entry IDs as unread.
-// Limit: 1000 IDs per request.
-// Returns the list of IDs successfully marked as unread.
-func (c *Client) MarkEntriesAsUnread(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// The IDs are sent in batches of 1000 (the API limit), several batches at a time.
+// The result lists the IDs acknowledged by the API and the batches that failed,
+// so callers can retry only those; the error is non-nil if any batch failed.
+func (c *Client) MarkEntriesAsUnread(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"unread_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodPost, "unread_entries.json", nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	var resultIDs []int64
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodPost, "unread_entries.json", "unread_entries", entryIDs)
 }
 
 // MarkEntriesAsRead marks the specified entry IDs as read.
-// Limit: 1000 IDs per request.
-// Returns the list of IDs successfully marked as read.
-func (c *Client) MarkEntriesAsRead(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// The IDs are sent in batches of 1000 (the API limit), several batches at a time.
+// The result lists the IDs acknowledged by the API and the batches that failed,
+// so callers can retry only those; the error is non-nil if any batch failed.
+func (c *Client) MarkEntriesAsRead(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"unread_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodDelete, "unread_entries.json", nil, body)
-	if err != nil {
-		// Check for alternative POST endpoint if DELETE with body fails (though stdlib handles it)
-		// Consider if a client option is needed to force POST alternative.
-		return nil, err
-	}
-
-	var resultIDs []int64
-	// Expect 200 OK with a body containing the IDs successfully marked
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodDelete, "unread_entries.json", "unread_entries", entryIDs)
 }
 
 // MarkEntriesAsReadAlt uses the alternative POST endpoint to mark entries as read.
 // Useful for clients that have issues with DELETE requests containing a body.
-func (c *Client) MarkEntriesAsReadAlt(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// Like the DELETE variant, the IDs are sent in batches of 1000, several batches at a time.
+func (c *Client) MarkEntriesAsReadAlt(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"unread_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodPost, "unread_entries/delete.json", nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	var resultIDs []int64
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodPost, "unread_entries/delete.json", "unread_entries", entryIDs)
 }
 
 // --- Starred Entries ---
@@ -640,78 +599,35 @@ func (c *Client) ListStarredEntries(ctx context.Context) ([]int64, error) {
 }
 
 // StarEntries marks the specified entry IDs as starred.
-// Limit: 1000 IDs per request.
-// Returns the list of IDs successfully starred.
-func (c *Client) StarEntries(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// The IDs are sent in batches of 1000 (the API limit), several batches at a time.
+// The result lists the IDs acknowledged by the API and the batches that failed,
+// so callers can retry only those; the error is non-nil if any batch failed.
+func (c *Client) StarEntries(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"starred_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodPost, "starred_entries.json", nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	var resultIDs []int64
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodPost, "starred_entries.json", "starred_entries", entryIDs)
 }
 
 // UnstarEntries removes the star from the specified entry IDs.
-// Limit: 1000 IDs per request.
-// Returns the list of IDs successfully unstarred.
-func (c *Client) UnstarEntries(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// The IDs are sent in batches of 1000 (the API limit), several batches at a time.
+// The result lists the IDs acknowledged by the API and the batches that failed,
+// so callers can retry only those; the error is non-nil if any batch failed.
+func (c *Client) UnstarEntries(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"starred_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodDelete, "starred_entries.json", nil, body)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-
-	var resultIDs []int64
-	// Expect 200 OK with a body containing the IDs successfully unstarred
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
-	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodDelete, "starred_entries.json", "starred_entries", entryIDs)
 }
 
 // UnstarEntriesAlt uses the alternative POST endpoint to unstar entries.
 // Useful for clients that have issues with DELETE requests containing a body.
-func (c *Client) UnstarEntriesAlt(ctx context.Context, entryIDs []int64) ([]int64, error) {
-	if len(entryIDs) > 1000 {
-		return nil, fmt.Errorf("maximum of 1000 entry IDs allowed per request")
-	}
+// Like the DELETE variant, the IDs are sent in batches of 1000, several batches at a time.
+func (c *Client) UnstarEntriesAlt(ctx context.Context, entryIDs []int64) (*BulkResult, error) {
 	if len(entryIDs) == 0 {
-		return []int64{}, nil // No-op
-	}
-
-	body := map[string][]int64{"starred_entries": entryIDs}
-	resp, err := c.doRequest(ctx, http.MethodPost, "starred_entries/delete.json", nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	var resultIDs []int64
-	_, err = c.handleResponse(resp, &resultIDs)
-	if err != nil {
-		return nil, err
+		return &BulkResult{Acknowledged: []int64{}}, nil // No-op
 	}
-	return resultIDs, nil
+	return c.bulkEntryIDs(ctx, http.MethodPost, "starred_entries/delete.json", "starred_entries", entryIDs)
 }
 
 // --- Taggings ---
@@ -0,0 +1,597 @@
+package feedbin
+
+import (
+	"hash/fnv"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultTitleThreshold is the word similarity at which two titles are
+	// considered the same article.
+	DefaultTitleThreshold = 0.8
+	// DefaultContentThreshold is the shingle similarity at which two entry
+	// bodies are considered the same article.
+	DefaultContentThreshold = 0.6
+	// DefaultShingleSize is the number of words in a content shingle.
+	DefaultShingleSize = 4
+	// DefaultMinTitleWords is the number of words a title needs before it is
+	// compared; shorter titles such as "Links" are too common to mean anything.
+	DefaultMinTitleWords = 4
+	// DefaultMinShingles is the number of shingles content needs before it is
+	// compared.
+	DefaultMinShingles = 8
+
+	markAsReadBatchSize = 1000
+)
+
+// trackingParams are query parameters that only identify where a click came
+// from. Parameters starting with "utm_" are always removed as well.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"mkt_tok": true,
+	"ref_src": true,
+	"ref_url": true,
+	"cmpid":   true,
+}
+
+// DuplicateReason describes why entries were grouped together.
+type DuplicateReason string
+
+const (
+	DuplicateURL     DuplicateReason = "url"
+	DuplicateTitle   DuplicateReason = "title"
+	DuplicateContent DuplicateReason = "content"
+)
+
+// DedupeOptions configures duplicate detection. The zero value uses the
+// defaults above and only groups entries from different feeds.
+type DedupeOptions struct {
+	TitleThreshold   float64
+	ContentThreshold float64
+	ShingleSize      int
+	MinTitleWords    int
+	MinShingles      int
+
+	// IncludeSameFeed also groups duplicates posted by the same feed.
+	IncludeSameFeed bool
+
+	// FeedPriority lists feed IDs from most to least preferred. The copy from
+	// the highest priority feed is kept; feeds that are not listed come last,
+	// and ties go to the earliest published entry.
+	FeedPriority []int64
+}
+
+// EntryFingerprint holds the normalized forms of an entry that duplicates
+// are detected by.
+type EntryFingerprint struct {
+	EntryID    int64
+	FeedID     int64
+	URL        string
+	TitleWords []string
+	Shingles   []uint64
+}
+
+// DuplicateCluster is a group of entries that are copies of one article.
+type DuplicateCluster struct {
+	// Entries are ordered by preference, so the first one is the copy to keep.
+	Entries []Entry
+	Reasons []DuplicateReason
+}
+
+// Preferred returns the copy of the article to keep.
+func (c DuplicateCluster) Preferred() Entry {
+	return c.Entries[0]
+}
+
+// Duplicates returns every copy except the preferred one.
+func (c DuplicateCluster) Duplicates() []Entry {
+	return c.Entries[1:]
+}
+
+// NormalizeURL returns a form of an entry URL that is the same for copies of
+// a page: the scheme, a leading "www.", default ports, trailing slashes,
+// fragments and tracking parameters are removed, and the host and query are
+// put in a canonical form. URLs that cannot be parsed are returned trimmed.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	for _, index := range []string{"/index.html", "/index.htm", "/index.php"} {
+		path = strings.TrimSuffix(path, index)
+	}
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+
+	normalized := host + path
+	if len(query) > 0 {
+		// Encode sorts by key
+		normalized += "?" + query.Encode()
+	}
+	return normalized
+}
+
+// Fingerprint computes the fingerprint of an entry.
+func Fingerprint(entry Entry, opt *DedupeOptions) *EntryFingerprint {
+	opt = opt.withDefaults()
+
+	fp := &EntryFingerprint{
+		EntryID: entry.ID,
+		FeedID:  entry.FeedID,
+		URL:     NormalizeURL(entry.URL),
+	}
+	if entry.Title != nil {
+		// Some feeds escape their titles, others do not
+		fp.TitleWords = uniqueWords(dedupeWords(html.UnescapeString(*entry.Title)))
+	}
+
+	content := ""
+	if entry.Content != nil {
+		content = *entry.Content
+	}
+	if strings.TrimSpace(content) == "" {
+		content = entry.Summary
+	}
+	fp.Shingles = shingles(dedupeWords(htmlText(content)), opt.ShingleSize)
+	return fp
+}
+
+// FindDuplicates groups entries, such as those returned by GetEntries and
+// GetFeedEntries, into clusters of copies of the same article. Entries are
+// grouped when their normalized URLs are equal, their titles are similar,
+// or their contents share enough shingles; the grouping is transitive.
+// Entries without duplicates are left out.
+func FindDuplicates(entries []Entry, opt *DedupeOptions) []DuplicateCluster {
+	opt = opt.withDefaults()
+
+	fps := make([]*EntryFingerprint, len(entries))
+	for i, entry := range entries {
+		fps[i] = Fingerprint(entry, opt)
+	}
+
+	d := &deduper{
+		opt:     opt,
+		fps:     fps,
+		parent:  make([]int, len(entries)),
+		reasons: make(map[int]map[DuplicateReason]bool),
+	}
+	for i := range d.parent {
+		d.parent[i] = i
+	}
+	d.matchURLs()
+	d.matchSets(DuplicateTitle, opt.TitleThreshold, opt.MinTitleWords, func(fp *EntryFingerprint) []uint64 {
+		return hashWords(fp.TitleWords)
+	})
+	d.matchSets(DuplicateContent, opt.ContentThreshold, opt.MinShingles, func(fp *EntryFingerprint) []uint64 {
+		return fp.Shingles
+	})
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range entries {
+		root := d.find(i)
+		if groups[root] == nil {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	priority := make(map[int64]int, len(opt.FeedPriority))
+	for i, feedID := range opt.FeedPriority {
+		if _, ok := priority[feedID]; !ok {
+			priority[feedID] = i
+		}
+	}
+	rank := func(entry Entry) int {
+		if p, ok := priority[entry.FeedID]; ok {
+			return p
+		}
+		return len(opt.FeedPriority)
+	}
+
+	var clusters []DuplicateCluster
+	for _, root := range roots {
+		members := groups[root]
+		if len(members) < 2 {
+			continue
+		}
+		cluster := DuplicateCluster{}
+		for _, i := range members {
+			cluster.Entries = append(cluster.Entries, entries[i])
+		}
+		sort.SliceStable(cluster.Entries, func(i, j int) bool {
+			a, b := cluster.Entries[i], cluster.Entries[j]
+			if rank(a) != rank(b) {
+				return rank(a) < rank(b)
+			}
+			if !a.Published.Equal(b.Published) {
+				return publishedOrMax(a).Before(publishedOrMax(b))
+			}
+			return a.ID < b.ID
+		})
+		for _, reason := range []DuplicateReason{DuplicateURL, DuplicateTitle, DuplicateContent} {
+			if d.reasons[root][reason] {
+				cluster.Reasons = append(cluster.Reasons, reason)
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// EntryPageFunc fetches one page of entries, such as Client.GetEntries or
+// Client.GetFeedEntries for a single feed.
+type EntryPageFunc func(opt *GetEntriesOptions) ([]Entry, *Response, error)
+
+// FindDuplicateEntries fetches every page of entries matching opt and groups
+// them with FindDuplicates.
+func (c *Client) FindDuplicateEntries(opt *GetEntriesOptions, dedupe *DedupeOptions) ([]DuplicateCluster, error) {
+	return FindDuplicatesFrom(opt, dedupe, c.GetEntries)
+}
+
+// FindDuplicateFeedEntries fetches every page of entries matching opt from
+// each of the feeds and groups them with FindDuplicates.
+func (c *Client) FindDuplicateFeedEntries(feedIDs []int64, opt *GetEntriesOptions, dedupe *DedupeOptions) ([]DuplicateCluster, error) {
+	sources := make([]EntryPageFunc, len(feedIDs))
+	for i, feedID := range feedIDs {
+		feedID := feedID
+		sources[i] = func(opt *GetEntriesOptions) ([]Entry, *Response, error) {
+			return c.GetFeedEntries(feedID, opt)
+		}
+	}
+	return FindDuplicatesFrom(opt, dedupe, sources...)
+}
+
+// FindDuplicatesFrom fetches every page of entries matching opt from each
+// source and groups them with FindDuplicates. Entries returned by more than
+// one source are only counted once.
+func FindDuplicatesFrom(opt *GetEntriesOptions, dedupe *DedupeOptions, sources ...EntryPageFunc) ([]DuplicateCluster, error) {
+	var entries []Entry
+	seen := make(map[int64]bool)
+	for _, fetch := range sources {
+		pageOpt := GetEntriesOptions{}
+		if opt != nil {
+			pageOpt = *opt
+		}
+		if pageOpt.Page == 0 {
+			pageOpt.Page = 1
+		}
+
+		for {
+			page, resp, err := fetch(&pageOpt)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range page {
+				if !seen[entry.ID] {
+					seen[entry.ID] = true
+					entries = append(entries, entry)
+				}
+			}
+			if len(page) == 0 || resp == nil || resp.Links == nil || resp.Links.Next == "" {
+				break
+			}
+			pageOpt.Page++
+		}
+	}
+	return FindDuplicates(entries, dedupe), nil
+}
+
+// CollapseDuplicates marks every copy except the preferred one of each
+// cluster as read, in batches of up to 1,000 entries. It returns the IDs the
+// API reported as marked read.
+func (c *Client) CollapseDuplicates(clusters []DuplicateCluster, usePostAlternative ...bool) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, cluster := range clusters {
+		for _, entry := range cluster.Duplicates() {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				ids = append(ids, entry.ID)
+			}
+		}
+	}
+
+	var marked []int64
+	for start := 0; start < len(ids); start += markAsReadBatchSize {
+		end := start + markAsReadBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		read, err := c.MarkAsRead(ids[start:end], usePostAlternative...)
+		if err != nil {
+			return marked, err
+		}
+		marked = append(marked, read...)
+	}
+	return marked, nil
+}
+
+func (opt *DedupeOptions) withDefaults() *DedupeOptions {
+	o := DedupeOptions{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.TitleThreshold <= 0 {
+		o.TitleThreshold = DefaultTitleThreshold
+	}
+	if o.ContentThreshold <= 0 {
+		o.ContentThreshold = DefaultContentThreshold
+	}
+	if o.ShingleSize <= 0 {
+		o.ShingleSize = DefaultShingleSize
+	}
+	if o.MinTitleWords <= 0 {
+		o.MinTitleWords = DefaultMinTitleWords
+	}
+	if o.MinShingles <= 0 {
+		o.MinShingles = DefaultMinShingles
+	}
+	return &o
+}
+
+// deduper links entries into clusters with a union-find.
+type deduper struct {
+	opt     *DedupeOptions
+	fps     []*EntryFingerprint
+	parent  []int
+	reasons map[int]map[DuplicateReason]bool
+}
+
+func (d *deduper) find(i int) int {
+	for d.parent[i] != i {
+		d.parent[i] = d.parent[d.parent[i]]
+		i = d.parent[i]
+	}
+	return i
+}
+
+func (d *deduper) union(i, j int, reason DuplicateReason) {
+	if !d.opt.IncludeSameFeed && d.fps[i].FeedID == d.fps[j].FeedID {
+		return
+	}
+	ri, rj := d.find(i), d.find(j)
+	if ri != rj {
+		d.parent[rj] = ri
+		for r := range d.reasons[rj] {
+			d.addReason(ri, r)
+		}
+		delete(d.reasons, rj)
+	}
+	d.addReason(ri, reason)
+}
+
+func (d *deduper) addReason(root int, reason DuplicateReason) {
+	if d.reasons[root] == nil {
+		d.reasons[root] = make(map[DuplicateReason]bool)
+	}
+	d.reasons[root][reason] = true
+}
+
+func (d *deduper) matchURLs() {
+	byURL := make(map[string][]int)
+	for i, fp := range d.fps {
+		if fp.URL != "" {
+			byURL[fp.URL] = append(byURL[fp.URL], i)
+		}
+	}
+	for _, indexes := range byURL {
+		for _, j := range indexes[1:] {
+			for _, i := range indexes {
+				if i == j {
+					break
+				}
+				d.union(i, j, DuplicateURL)
+			}
+		}
+	}
+}
+
+// matchSets links entries whose sets have a Jaccard similarity of at least
+// threshold. Shared elements are counted through an inverted index, so only
+// entries that have something in common are compared.
+func (d *deduper) matchSets(reason DuplicateReason, threshold float64, minSize int, set func(*EntryFingerprint) []uint64) {
+	sets := make([][]uint64, len(d.fps))
+	for i, fp := range d.fps {
+		sets[i] = set(fp)
+	}
+
+	index := make(map[uint64][]int)
+	for j, elements := range sets {
+		if len(elements) < minSize {
+			continue
+		}
+
+		shared := make(map[int]int)
+		for _, e := range elements {
+			for _, i := range index[e] {
+				shared[i]++
+			}
+			index[e] = append(index[e], j)
+		}
+		for i, n := range shared {
+			union := len(sets[i]) + len(elements) - n
+			if float64(n)/float64(union) >= threshold {
+				d.union(i, j, reason)
+			}
+		}
+	}
+}
+
+// apostrophes removes the apostrophes of contractions, which syndicated
+// copies often typeset differently ("don't" and "don’t").
+var apostrophes = strings.NewReplacer("'", "", "\u2019", "")
+
+// dedupeWords splits text into lowercased words of letters and digits.
+func dedupeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(apostrophes.Replace(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	var unique []string
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func hashWords(words []string) []uint64 {
+	hashes := make([]uint64, len(words))
+	for i, w := range words {
+		hashes[i] = hashString(w)
+	}
+	return hashes
+}
+
+// shingles returns the distinct hashes of every run of size words.
+func shingles(words []string, size int) []uint64 {
+	if len(words) < size {
+		return nil
+	}
+	seen := make(map[uint64]bool)
+	var hashes []uint64
+	for i := 0; i+size <= len(words); i++ {
+		h := hashString(strings.Join(words[i:i+size], " "))
+		if !seen[h] {
+			seen[h] = true
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// wordBreakTags are the elements that separate the words around them. Other
+// tags, such as <a> or <em>, are dropped without a space, since copies of an
+// article often differ in inline markup: "<b>Go</b>pher" reads "Gopher".
+var wordBreakTags = map[string]bool{
+	"article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "footer": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"iframe": true, "img": true, "li": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+// htmlText returns the text of entry content without markup, comments,
+// scripts and styles, with entities such as &amp; and &nbsp; decoded. A "<"
+// that does not start a tag, as in "a < b" or "c<d", is kept as text.
+func htmlText(content string) string {
+	var text strings.Builder
+	skip := ""
+	for content != "" {
+		lt := strings.IndexByte(content, '<')
+		if lt < 0 {
+			if skip == "" {
+				text.WriteString(content)
+			}
+			break
+		}
+		if skip == "" {
+			text.WriteString(content[:lt])
+		}
+		content = content[lt:]
+
+		if strings.HasPrefix(content, "<!--") {
+			end := strings.Index(content, "-->")
+			if end < 0 {
+				break
+			}
+			content = content[end+3:]
+			continue
+		}
+
+		closing := strings.HasPrefix(content, "</")
+		start := 1
+		if closing {
+			start = 2
+		}
+		if len(content) <= start || !isTagStart(content[start]) {
+			if skip == "" {
+				text.WriteByte('<')
+			}
+			content = content[1:]
+			continue
+		}
+
+		gt := strings.IndexByte(content, '>')
+		if gt < 0 {
+			// An unterminated tag is text, as in "c<d"
+			if skip == "" {
+				text.WriteString(content)
+			}
+			break
+		}
+		tag := strings.ToLower(content[start:gt])
+		if i := strings.IndexAny(tag, " \t\r\n/"); i >= 0 {
+			tag = tag[:i]
+		}
+		content = content[gt+1:]
+
+		switch {
+		case tag == "script" || tag == "style":
+			if closing && skip == tag {
+				skip = ""
+			} else if !closing {
+				skip = tag
+			}
+		case skip == "" && wordBreakTags[tag]:
+			text.WriteByte(' ')
+		}
+	}
+	return html.UnescapeString(text.String())
+}
+
+// isTagStart reports whether c can start a tag name or a declaration.
+func isTagStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '!'
+}
+
+// publishedOrMax sorts entries without a published date last.
+func publishedOrMax(entry Entry) time.Time {
+	if entry.Published.IsZero() {
+		return time.Unix(1<<62, 0)
+	}
+	return entry.Published
+}
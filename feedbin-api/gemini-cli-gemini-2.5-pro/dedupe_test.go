@@ -0,0 +1,195 @@
+package feedbin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://www.Example.com/post/", "example.com/post"},
+		{"http://example.com:80/post#comments", "example.com/post"},
+		{"https://example.com:8443/post", "example.com:8443/post"},
+		{"https://example.com/blog/index.html", "example.com/blog"},
+		{"https://example.com/post?utm_source=rss&utm_medium=feed", "example.com/post"},
+		{"https://example.com/post?id=2&fbclid=abc&a=1", "example.com/post?a=1&id=2"},
+		{"https://example.com/post?ref_src=twsrc", "example.com/post"},
+		// ref often selects content, like a branch on GitHub
+		{"https://github.com/x/y?ref=main", "github.com/x/y?ref=main"},
+		{"  not a url  ", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeURL(tt.in); got != tt.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if NormalizeURL("https://github.com/x/y?ref=main") == NormalizeURL("https://github.com/x/y?ref=dev") {
+		t.Error("URLs with different ref parameters must not be equal")
+	}
+}
+
+func TestHTMLText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"<p>one</p><p>two</p>", []string{"one", "two"}},
+		{"first<br>second<br/>third", []string{"first", "second", "third"}},
+		{"<b>Go</b>pher and <a href=\"/x\">Ru</a>st", []string{"gopher", "and", "rust"}},
+		{"fish&nbsp;&amp;&nbsp;chips &lt;b&gt;", []string{"fish", "chips", "b"}},
+		{"don&rsquo;t stop, don't", []string{"dont", "stop", "dont"}},
+		{"a < b and c<d", []string{"a", "b", "and", "c", "d"}},
+		{"keep<!-- <p>hidden</p> -->ing", []string{"keeping"}},
+		{"<p>text</p><script>var s = '<p>';</script><style>p {}</style><p>more</p>", []string{"text", "more"}},
+		{"caf\u00e9 <img src=x.png>photo", []string{"café", "photo"}},
+	}
+	for _, tt := range tests {
+		if got := dedupeWords(htmlText(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("dedupeWords(htmlText(%q)) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint_Markup(t *testing.T) {
+	// Copies that differ only in inline markup, entities and apostrophes
+	a := dedupeEntry(1, 10, "Fish &amp; chips don't", "https://a.example/1",
+		"<p>The <em>best</em> fish &amp; chips don&#8217;t need vinegar or a lot of salt at all</p>")
+	b := dedupeEntry(2, 20, "Fish & chips don’t", "https://b.example/2",
+		"<div>The best fish & chips don’t need <a href=\"/v\">vinegar</a> or a lot of salt at all</div>")
+
+	fa, fb := Fingerprint(a, nil), Fingerprint(b, nil)
+	if !reflect.DeepEqual(fa.TitleWords, fb.TitleWords) {
+		t.Errorf("title words differ: %q and %q", fa.TitleWords, fb.TitleWords)
+	}
+	if !reflect.DeepEqual(fa.Shingles, fb.Shingles) || len(fa.Shingles) == 0 {
+		t.Errorf("shingles differ: %d and %d", len(fa.Shingles), len(fb.Shingles))
+	}
+}
+
+func dedupeEntry(id, feedID int64, title, rawURL, content string) Entry {
+	return Entry{ID: id, FeedID: feedID, Title: &title, URL: rawURL, Content: &content,
+		Published: time.Date(2024, 5, 1, 0, 0, int(id), 0, time.UTC)}
+}
+
+const article = `<p>The quick brown fox jumps over the lazy dog while the
+cat watches from the window and wonders why anyone would jump at all</p>`
+
+func TestFindDuplicates(t *testing.T) {
+	entries := []Entry{
+		dedupeEntry(1, 10, "Go 1.23 is released today", "https://go.dev/blog/go1.23?utm_source=rss", "Release notes"),
+		dedupeEntry(2, 20, "Go 1.23 released", "https://www.go.dev/blog/go1.23/", "Aggregated"),
+		dedupeEntry(3, 30, "Go 1.23 is released today!", "https://news.example.com/1", "Other words"),
+		dedupeEntry(4, 10, "A fox story", "https://a.example.com/fox", article),
+		dedupeEntry(5, 40, "Quoted elsewhere", "https://b.example.com/quoted", "<blockquote>"+article+"</blockquote>"),
+		dedupeEntry(6, 50, "Branch main", "https://github.com/x/y?ref=main", "main"),
+		dedupeEntry(7, 60, "Branch dev", "https://github.com/x/y?ref=dev", "dev"),
+		// Copies within one feed are left alone by default
+		dedupeEntry(8, 70, "Repost", "https://example.org/repost", "first"),
+		dedupeEntry(9, 70, "Repost again", "https://example.org/repost/", "second"),
+	}
+
+	clusters := FindDuplicates(entries, &DedupeOptions{FeedPriority: []int64{20}})
+
+	var got []string
+	for _, cluster := range clusters {
+		var ids []string
+		for _, entry := range cluster.Entries {
+			ids = append(ids, fmt.Sprint(entry.ID))
+		}
+		got = append(got, fmt.Sprintf("%s %v", strings.Join(ids, ","), cluster.Reasons))
+	}
+	want := []string{
+		// Feed 20 has priority, then the earliest entry
+		"2,1,3 [url title]",
+		"4,5 [content]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clusters = %v, want %v", got, want)
+	}
+	if len(clusters) > 0 && clusters[0].Preferred().ID != 2 {
+		t.Errorf("Preferred = %d, want 2", clusters[0].Preferred().ID)
+	}
+
+	clusters = FindDuplicates(entries, &DedupeOptions{IncludeSameFeed: true})
+	if len(clusters) != 3 || clusters[2].Entries[0].ID != 8 || clusters[2].Entries[1].ID != 9 {
+		t.Errorf("IncludeSameFeed clusters = %+v, want entries 8 and 9 grouped", clusters)
+	}
+}
+
+func TestFindDuplicatesFrom(t *testing.T) {
+	pages := map[int][]Entry{
+		1: {dedupeEntry(1, 10, "One", "https://example.com/a", "x")},
+		2: {dedupeEntry(2, 20, "Two", "https://example.com/a/", "y")},
+	}
+	var requested []int
+	paged := func(opt *GetEntriesOptions) ([]Entry, *Response, error) {
+		requested = append(requested, opt.Page)
+		resp := &Response{Links: &Links{}}
+		if opt.Page == 1 {
+			resp.Links.Next = "next"
+		}
+		return pages[opt.Page], resp, nil
+	}
+	// The second source returns entry 2 again
+	again := func(opt *GetEntriesOptions) ([]Entry, *Response, error) {
+		return pages[2], &Response{}, nil
+	}
+
+	clusters, err := FindDuplicatesFrom(nil, nil, paged, again)
+	if err != nil {
+		t.Fatalf("FindDuplicatesFrom returned error: %v", err)
+	}
+	if !reflect.DeepEqual(requested, []int{1, 2}) {
+		t.Errorf("requested pages %v, want [1 2]", requested)
+	}
+	if len(clusters) != 1 || len(clusters[0].Entries) != 2 {
+		t.Errorf("clusters = %+v, want entries 1 and 2 once", clusters)
+	}
+}
+
+// redirectTransport sends every request to a test server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_FindDuplicateFeedEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/feeds/10/entries.json":
+			fmt.Fprint(w, `[{"id": 1, "feed_id": 10, "title": "Copy", "url": "https://example.com/post?utm_campaign=x"}]`)
+		case "/v2/feeds/20/entries.json":
+			fmt.Fprint(w, `[{"id": 2, "feed_id": 20, "title": "Copy", "url": "https://example.com/post"}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+	c := New("user", "pass")
+	c.client = &http.Client{Transport: redirectTransport{target: target}}
+
+	clusters, err := c.FindDuplicateFeedEntries([]int64{10, 20}, nil, nil)
+	if err != nil {
+		t.Fatalf("FindDuplicateFeedEntries returned error: %v", err)
+	}
+	if len(clusters) != 1 || len(clusters[0].Entries) != 2 || clusters[0].Reasons[0] != DuplicateURL {
+		t.Errorf("clusters = %+v, want entries 1 and 2 grouped by URL", clusters)
+	}
+}
@@ -100,7 +100,9 @@ func main() {
 	fmt.Println("-------------------------------------")
 
 	// Example: Using the Extract service
-	// The Extract service token is your Feedbin username, which is handled during client initialization.
+	// Requests are signed with the signing key Feedbin issues for the extraction service;
+	// the username is set during client initialization.
+	client.Extract.SetSigningKey(os.Getenv("FEEDBIN_EXTRACT_SECRET"))
 	articleURL := "https://www.theverge.com/2023/10/26/23933449/google-ai-search-generative-experience-rollout" // Example URL
 	fmt.Printf("Attempting to extract content from: %s\n", articleURL)
 	extractedContent, extractResp, err := client.Extract.Extract(articleURL)
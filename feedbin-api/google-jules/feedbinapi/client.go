@@ -41,6 +41,7 @@ type Client struct {
 	Imports                *ImportsService
 	Pages                  *PagesService
 	Extract                *ExtractService // For Full Content Extraction
+	Stats                  *StatsService   // Client-side reading statistics
 }
 
 // NewClient returns a new Feedbin API client
@@ -72,8 +73,9 @@ func (c *Client) initServices() {
 	c.Icons = NewIconsService(c)
 	c.Imports = NewImportsService(c)
 	c.Pages = NewPagesService(c)
-	// For ExtractService, the apiToken is the username.
+	// ExtractService signs requests with the username; the signing key is set separately.
 	c.Extract = NewExtractService(c.client, c.username, c.UserAgent)
+	c.Stats = NewStatsService(c)
 }
 
 // SetBaseURL sets the base URL for API requests to a custom endpoint.
@@ -74,6 +74,9 @@ func TestNewClient(t *testing.T) {
 	if c.Extract == nil {
 		t.Error("Extract service not initialized")
 	}
+	if c.Stats == nil {
+		t.Error("Stats service not initialized")
+	}
 }
 
 // TestClient_Setters tests the various setter methods on the client.
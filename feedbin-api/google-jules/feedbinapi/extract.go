@@ -1,60 +1,132 @@
 package feedbinapi
 
 import (
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 )
 
 const (
 	// ExtractBaseURL is the base URL for the Feedbin Full Content Extraction API.
 	ExtractBaseURL = "https://extract.feedbin.com/parser"
+
+	// DefaultExtractConcurrency is the number of concurrent requests used by ExtractBatch.
+	DefaultExtractConcurrency = 4
+
+	// DefaultExtractCacheSize is the number of results kept by the default extraction cache.
+	DefaultExtractCacheSize = 256
 )
 
 // ExtractService handles operations for the Full Content Extraction API.
+// Requests are authenticated with an HMAC-SHA1 signature of the URL to parse,
+// computed with the signing key issued by Feedbin.
+// Docs: https://github.com/feedbin/feedbin-api/blob/master/content/extract-full-content.md
 type ExtractService struct {
 	httpClient *http.Client // Use a specific httpClient, can be shared from main client
-	apiToken   string       // API token, typically the Feedbin username
+	username   string       // Feedbin username, part of the request path
+	signingKey string       // Secret used to sign the URL to parse
 	userAgent  string       // User agent, can be shared from main client
+	baseURL    string       // Defaults to ExtractBaseURL
+	cache      ExtractCache // Results keyed by URL, nil disables caching
 }
 
 // NewExtractService creates a new service for content extraction.
-func NewExtractService(sharedHttpClient *http.Client, apiToken string, userAgent string) *ExtractService {
+// A signing key must be set with SetSigningKey before extracting.
+func NewExtractService(sharedHttpClient *http.Client, username string, userAgent string) *ExtractService {
 	return &ExtractService{
 		httpClient: sharedHttpClient,
-		apiToken:   apiToken,
+		username:   username,
 		userAgent:  userAgent,
+		baseURL:    ExtractBaseURL,
+		cache:      NewMemoryExtractCache(DefaultExtractCacheSize),
 	}
 }
 
-// SetApiToken allows updating the API token for the extraction service.
-func (s *ExtractService) SetApiToken(apiToken string) {
-	s.apiToken = apiToken
+// SetUsername sets the username used in extraction request paths.
+func (s *ExtractService) SetUsername(username string) {
+	s.username = username
 }
 
-// Extract fetches the parsed content of a given URL.
-// Docs: https://github.com/feedbin/feedbin-api/blob/master/content/extract-full-content.md
-// The endpoint is GET /parser?token=<token>&url=<url_to_parse>
-func (s *ExtractService) Extract(urlToParse string) (*ExtractResult, *http.Response, error) {
-	if s.apiToken == "" {
-		return nil, nil, fmt.Errorf("API token for ExtractService is not set")
+// SetSigningKey sets the secret used to sign extraction requests.
+func (s *ExtractService) SetSigningKey(signingKey string) {
+	s.signingKey = signingKey
+}
+
+// SetBaseURL sets the base URL of the extraction service, e.g. for a self-hosted parser.
+func (s *ExtractService) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetCache replaces the extraction cache. A nil cache disables caching.
+func (s *ExtractService) SetCache(cache ExtractCache) {
+	s.cache = cache
+}
+
+// Sign returns the hex-encoded HMAC-SHA1 signature of urlToParse.
+func (s *ExtractService) Sign(urlToParse string) string {
+	mac := hmac.New(sha1.New, []byte(s.signingKey))
+	mac.Write([]byte(urlToParse))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL returns the signed request URL for urlToParse:
+// <base>/:username/:signature?base64_url=:base64_url, where base64_url is the
+// RFC 4648 URL-safe base64 encoding of urlToParse.
+func (s *ExtractService) SignedURL(urlToParse string) (string, error) {
+	if s.username == "" {
+		return "", fmt.Errorf("username for ExtractService is not set")
+	}
+	if s.signingKey == "" {
+		return "", fmt.Errorf("signing key for ExtractService is not set")
 	}
 	if urlToParse == "" {
-		return nil, nil, fmt.Errorf("URL to parse is required")
+		return "", fmt.Errorf("URL to parse is required")
+	}
+
+	base := s.baseURL
+	if base == "" {
+		base = ExtractBaseURL
 	}
 
-	extractURL, err := url.Parse(ExtractBaseURL)
+	extractURL, err := url.Parse(base + "/" + url.PathEscape(s.username) + "/" + s.Sign(urlToParse))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse ExtractBaseURL: %w", err)
+		return "", fmt.Errorf("failed to parse extraction URL: %w", err)
+	}
+	// Set RawQuery directly: base64 padding must not be percent-encoded.
+	extractURL.RawQuery = "base64_url=" + base64.URLEncoding.EncodeToString([]byte(urlToParse))
+
+	return extractURL.String(), nil
+}
+
+// Extract fetches the parsed content of a given URL.
+// Cached results are returned without a request, and with a nil *http.Response.
+func (s *ExtractService) Extract(urlToParse string) (*ExtractResult, *http.Response, error) {
+	return s.ExtractContext(context.Background(), urlToParse)
+}
+
+// ExtractContext is like Extract but cancels the request with ctx.
+func (s *ExtractService) ExtractContext(ctx context.Context, urlToParse string) (*ExtractResult, *http.Response, error) {
+	if s.cache != nil {
+		if result, ok := s.cache.Get(urlToParse); ok {
+			return result, nil, nil
+		}
 	}
 
-	q := extractURL.Query()
-	q.Set("token", s.apiToken)
-	q.Set("url", urlToParse)
-	extractURL.RawQuery = q.Encode()
+	signedURL, err := s.SignedURL(urlToParse)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	req, err := http.NewRequest(http.MethodGet, extractURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("creating extraction request: %w", err)
 	}
@@ -69,9 +141,6 @@ func (s *ExtractService) Extract(urlToParse string) (*ExtractResult, *http.Respo
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// Attempt to read body for more detailed error message, similar to main client's CheckResponse
-		// errorBody, _ := io.ReadAll(resp.Body) // Be careful with consuming body
-		// return nil, resp, fmt.Errorf("extraction API error: status %s, body: %s", resp.Status, string(errorBody))
 		return nil, resp, fmt.Errorf("extraction API error: status %s", resp.Status)
 	}
 
@@ -80,5 +149,178 @@ func (s *ExtractService) Extract(urlToParse string) (*ExtractResult, *http.Respo
 		return nil, resp, fmt.Errorf("decoding extraction response: %w", err)
 	}
 
+	if s.cache != nil {
+		s.cache.Set(urlToParse, &result)
+	}
+
 	return &result, resp, nil
 }
+
+// ExtractBatchOptions configures ExtractBatch and ExtractEntries.
+type ExtractBatchOptions struct {
+	// Concurrency is the maximum number of requests in flight.
+	// Defaults to DefaultExtractConcurrency.
+	Concurrency int
+}
+
+// ExtractBatchResult is the outcome of extracting one URL in a batch.
+type ExtractBatchResult struct {
+	URL    string
+	Result *ExtractResult
+	Err    error
+}
+
+// ExtractBatch extracts many URLs with bounded concurrency. Results are
+// returned in the order of urls; a failed URL has Err set and does not stop
+// the others. Duplicate URLs are fetched once. If ctx is cancelled, the URLs
+// not extracted yet get ctx.Err().
+func (s *ExtractService) ExtractBatch(ctx context.Context, urls []string, opts *ExtractBatchOptions) []ExtractBatchResult {
+	concurrency := DefaultExtractConcurrency
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+
+	results := make([]ExtractBatchResult, len(urls))
+	positions := make(map[string][]int)
+	var unique []string
+	for i, u := range urls {
+		results[i].URL = u
+		if _, ok := positions[u]; !ok {
+			unique = append(unique, u)
+		}
+		positions[u] = append(positions[u], i)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for _, u := range unique {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			for _, i := range positions[u] {
+				results[i].Err = ctx.Err()
+			}
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, _, err := s.ExtractContext(ctx, u)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, i := range positions[u] {
+				results[i].Result = result
+				results[i].Err = err
+			}
+		}(u)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// ExtractEntries extracts the full content of entries by their URL, with
+// bounded concurrency. The results are keyed by entry ID; entries without a
+// URL are skipped.
+func (s *ExtractService) ExtractEntries(ctx context.Context, entries []Entry, opts *ExtractBatchOptions) map[int64]ExtractBatchResult {
+	var (
+		urls []string
+		ids  []int64
+	)
+	for _, entry := range entries {
+		if entry.URL == "" {
+			continue
+		}
+		urls = append(urls, entry.URL)
+		ids = append(ids, entry.ID)
+	}
+
+	results := make(map[int64]ExtractBatchResult, len(ids))
+	for i, result := range s.ExtractBatch(ctx, urls, opts) {
+		results[ids[i]] = result
+	}
+	return results
+}
+
+// ExtractCache stores extraction results keyed by the extracted URL.
+// Implementations must be safe for concurrent use.
+type ExtractCache interface {
+	Get(url string) (*ExtractResult, bool)
+	Set(url string, result *ExtractResult)
+}
+
+// MemoryExtractCache is an in-memory ExtractCache that evicts the least
+// recently used result once it holds more than its maximum number of results.
+type MemoryExtractCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	items      map[string]*list.Element
+}
+
+// memoryExtractItem is a value in the MemoryExtractCache order list.
+type memoryExtractItem struct {
+	url    string
+	result *ExtractResult
+}
+
+// NewMemoryExtractCache creates an in-memory cache holding up to maxEntries
+// results. A maxEntries of zero or less means no limit.
+func NewMemoryExtractCache(maxEntries int) *MemoryExtractCache {
+	return &MemoryExtractCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached result for url.
+func (c *MemoryExtractCache) Get(url string) (*ExtractResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[url]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryExtractItem).result, true
+}
+
+// Set stores the result for url.
+func (c *MemoryExtractCache) Set(url string, result *ExtractResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[url]; ok {
+		elem.Value.(*memoryExtractItem).result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[url] = c.order.PushFront(&memoryExtractItem{url: url, result: result})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*memoryExtractItem).url)
+	}
+}
+
+// Len returns the number of cached results.
+func (c *MemoryExtractCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,148 @@
+package feedbinapi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestExtract_SignedURL checks the signed URL against the example in
+// specs/content/extract-full-content.md.
+func TestExtract_SignedURL(t *testing.T) {
+	s := NewExtractService(http.DefaultClient, "username", UserAgent)
+	s.SetSigningKey("secret")
+
+	got, err := s.SignedURL("https://feedbin.com/blog/2018/09/11/private-by-default/")
+	if err != nil {
+		t.Fatalf("SignedURL returned error: %v", err)
+	}
+
+	want := "https://extract.feedbin.com/parser/username/e4696f8630bb68c21d77a9629ce8d063d8e5f81c?base64_url=aHR0cHM6Ly9mZWVkYmluLmNvbS9ibG9nLzIwMTgvMDkvMTEvcHJpdmF0ZS1ieS1kZWZhdWx0Lw=="
+	if got != want {
+		t.Errorf("SignedURL = %q; want %q", got, want)
+	}
+}
+
+// TestExtract_SignedURLRequiresKey ensures nothing is sent without a signing key.
+func TestExtract_SignedURLRequiresKey(t *testing.T) {
+	s := NewExtractService(http.DefaultClient, "username", UserAgent)
+	if _, _, err := s.Extract("https://example.com/"); err == nil {
+		t.Error("Extract without a signing key should return an error")
+	}
+}
+
+// newExtractTestServer serves extraction results for signed requests and
+// records the peak number of concurrent requests.
+func newExtractTestServer(t *testing.T, requests, peak *int32) (*ExtractService, func()) {
+	var inFlight int32
+	var mu sync.Mutex
+
+	var s *ExtractService
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		mu.Lock()
+		if n > *peak {
+			*peak = n
+		}
+		mu.Unlock()
+
+		raw, err := base64.URLEncoding.DecodeString(r.URL.Query().Get("base64_url"))
+		if err != nil {
+			t.Errorf("Invalid base64_url: %v", err)
+		}
+		u := string(raw)
+
+		if want := "/parser/username/" + s.Sign(u); r.URL.Path != want {
+			t.Errorf("Request path = %q; want %q", r.URL.Path, want)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(u, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"url": %q, "title": "Title of %s", "content": "<p>Hi</p>", "date_published": "2018-09-11T00:00:00.000Z"}`, u, u)
+	}))
+
+	s = NewExtractService(server.Client(), "username", UserAgent)
+	s.SetSigningKey("secret")
+	s.SetBaseURL(server.URL + "/parser")
+	return s, server.Close
+}
+
+// TestExtract_ExtractBatch extracts with bounded concurrency, keeps the input
+// order and serves repeated URLs from the cache.
+func TestExtract_ExtractBatch(t *testing.T) {
+	var requests, peak int32
+	s, teardown := newExtractTestServer(t, &requests, &peak)
+	defer teardown()
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("https://example.com/%d", i))
+	}
+	urls = append(urls, "https://example.com/missing", urls[0])
+
+	results := s.ExtractBatch(context.Background(), urls, &ExtractBatchOptions{Concurrency: 3})
+	if len(results) != len(urls) {
+		t.Fatalf("ExtractBatch returned %d results; want %d", len(results), len(urls))
+	}
+	for i, result := range results {
+		if result.URL != urls[i] {
+			t.Errorf("Result %d URL = %q; want %q", i, result.URL, urls[i])
+		}
+	}
+	if results[10].Err == nil {
+		t.Error("Expected an error for the missing URL")
+	}
+	if results[11].Err != nil || results[11].Result.Title != "Title of https://example.com/0" {
+		t.Errorf("Unexpected result for duplicate URL: %+v", results[11])
+	}
+	if results[0].Result.PublishedAt != "2018-09-11T00:00:00.000Z" {
+		t.Errorf("PublishedAt = %q", results[0].Result.PublishedAt)
+	}
+
+	if requests != 11 {
+		t.Errorf("Expected 11 requests, got %d", requests)
+	}
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent requests, got %d", peak)
+	}
+
+	// Successful results are cached by URL
+	result, resp, err := s.Extract(urls[5])
+	if err != nil || resp != nil || result.URL != urls[5] {
+		t.Errorf("Expected cached result for %s, got %+v, %v, %v", urls[5], result, resp, err)
+	}
+	if requests != 11 {
+		t.Errorf("Expected no request for a cached URL, got %d requests", requests)
+	}
+}
+
+// TestMemoryExtractCache_Evicts ensures the least recently used result is evicted.
+func TestMemoryExtractCache_Evicts(t *testing.T) {
+	c := NewMemoryExtractCache(2)
+	c.Set("a", &ExtractResult{URL: "a"})
+	c.Set("b", &ExtractResult{URL: "b"})
+	c.Get("a")
+	c.Set("c", &ExtractResult{URL: "c"})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected a to be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d; want 2", c.Len())
+	}
+}
@@ -0,0 +1,150 @@
+package feedbinapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// servePagedEntries registers a handler on path that serves entries 1..total,
+// perPage at a time, with Link headers pointing at the next page.
+func servePagedEntries(t *testing.T, mux *http.ServeMux, path string, total, perPage int, requests *int) {
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+
+		start := (page - 1) * perPage
+		end := start + perPage
+		if end > total {
+			end = total
+		}
+		if end < total {
+			q := r.URL.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, q.Encode()))
+		}
+
+		fmt.Fprint(w, "[")
+		for id := start + 1; id <= end; id++ {
+			if id > start+1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id": %d, "feed_id": 1, "title": "Entry %d"}`, id, id)
+		}
+		fmt.Fprint(w, "]")
+	})
+}
+
+// TestEntries_AllEntries follows next links until the last page.
+func TestEntries_AllEntries(t *testing.T) {
+	client, mux, teardown := setupTestServer(t)
+	defer teardown()
+
+	requests := 0
+	servePagedEntries(t, mux, "/entries.json", 5, 2, &requests)
+
+	var ids []int64
+	for entry, err := range client.Entries.AllEntries(context.Background(), &EntryListOptions{Read: Bool(false)}, nil) {
+		if err != nil {
+			t.Fatalf("AllEntries yielded error: %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	if len(ids) != 5 {
+		t.Fatalf("Expected 5 entries, got %d: %v", len(ids), ids)
+	}
+	for i, id := range ids {
+		if id != int64(i+1) {
+			t.Errorf("Entry %d has ID %d, want %d", i, id, i+1)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+}
+
+// TestEntries_AllFeedEntries_MaxItems stops early without fetching more pages.
+func TestEntries_AllFeedEntries_MaxItems(t *testing.T) {
+	client, mux, teardown := setupTestServer(t)
+	defer teardown()
+
+	requests := 0
+	servePagedEntries(t, mux, "/feeds/7/entries.json", 10, 2, &requests)
+
+	count := 0
+	for _, err := range client.Entries.AllFeedEntries(context.Background(), 7, nil, &IterOptions{MaxItems: 3}) {
+		if err != nil {
+			t.Fatalf("AllFeedEntries yielded error: %v", err)
+		}
+		count++
+	}
+
+	if count != 3 {
+		t.Errorf("Expected 3 entries, got %d", count)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+}
+
+// TestSavedSearches_AllSavedSearchEntries requests entry objects.
+func TestSavedSearches_AllSavedSearchEntries(t *testing.T) {
+	client, mux, teardown := setupTestServer(t)
+	defer teardown()
+
+	mux.HandleFunc("/saved_searches/3.json", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("include_entries"); got != "true" {
+			t.Errorf("include_entries = %q, want %q", got, "true")
+		}
+		fmt.Fprint(w, `[{"id": 42, "feed_id": 1, "title": "Match"}]`)
+	})
+
+	var entries []Entry
+	for entry, err := range client.SavedSearches.AllSavedSearchEntries(context.Background(), 3, nil, nil) {
+		if err != nil {
+			t.Fatalf("AllSavedSearchEntries yielded error: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 1 || entries[0].ID != 42 {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+// TestEntries_AllEntries_Cancelled yields the context error.
+func TestEntries_AllEntries_Cancelled(t *testing.T) {
+	client, mux, teardown := setupTestServer(t)
+	defer teardown()
+
+	requests := 0
+	servePagedEntries(t, mux, "/entries.json", 5, 2, &requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var gotErr error
+	count := 0
+	for _, err := range client.Entries.AllEntries(ctx, nil, nil) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		count++
+		if count == 2 {
+			cancel()
+		}
+	}
+
+	if gotErr != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", gotErr)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 entries before cancellation, got %d", count)
+	}
+}
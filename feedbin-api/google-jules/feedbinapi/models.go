@@ -164,7 +164,7 @@ type ExtractResult struct {
 	URL         string `json:"url"`
 	Title       string `json:"title,omitempty"`
 	Author      string `json:"author,omitempty"`
-	PublishedAt string `json:"published_at,omitempty"` // String because format isn't guaranteed ISO 8601
+	PublishedAt string `json:"date_published,omitempty"` // String because format isn't guaranteed ISO 8601
 	Dek         string `json:"dek,omitempty"`          // Subtitle or summary
 	LeadImageURL string `json:"lead_image_url,omitempty"`
 	Content     string `json:"content"` // HTML content
@@ -172,7 +172,9 @@ type ExtractResult struct {
 	Excerpt     string `json:"excerpt,omitempty"`
 	WordCount   int    `json:"word_count,omitempty"`
 	Direction   string `json:"direction,omitempty"` // e.g., "ltr", "rtl"
-	// Other fields like domain, total_pages, rendered_pages, etc.
+	Domain      string `json:"domain,omitempty"`
+	TotalPages  int    `json:"total_pages,omitempty"`
+	RenderedPages int  `json:"rendered_pages,omitempty"`
 }
 
 // General API Options Structs
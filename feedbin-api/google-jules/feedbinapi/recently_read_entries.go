@@ -24,8 +24,11 @@ type RecentlyReadEntryListOptions struct {
 }
 
 // List retrieves recently read entries.
-// The response is an array of `RecentlyReadEntry` objects.
 // Docs: https://github.com/feedbin/feedbin-api/blob/master/content/recently-read-entries.md#get-recently-read-entries
+//
+// Deprecated: The endpoint returns a plain array of entry IDs, which cannot be
+// decoded into RecentlyReadEntry values, so List fails on every response.
+// Use ListIDs instead.
 func (s *RecentlyReadEntriesService) List(opts *RecentlyReadEntryListOptions) ([]RecentlyReadEntry, *http.Response, error) {
 	path := "recently_read_entries.json"
 	if opts != nil {
@@ -51,6 +54,23 @@ func (s *RecentlyReadEntriesService) List(opts *RecentlyReadEntryListOptions) ([
 	return entries, resp, nil
 }
 
+// ListIDs retrieves the IDs of recently read entries, in the order they should
+// be displayed. The endpoint returns a plain array of entry IDs.
+// Docs: https://github.com/feedbin/feedbin-api/blob/master/content/recently-read-entries.md#get-recently-read-entries
+func (s *RecentlyReadEntriesService) ListIDs() ([]int64, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "recently_read_entries.json", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entryIDs []int64
+	resp, err := s.client.Do(req, &entryIDs)
+	if err != nil {
+		return nil, resp, err
+	}
+	return entryIDs, resp, nil
+}
+
 // CreateRecentlyReadEntryOptions defines the structure for the POST request.
 // The API expects an array of objects, but the example shows a single object for a single entry.
 // Let's assume for now it's a single object post, or the API handles an array of these if multiple are sent.
@@ -0,0 +1,588 @@
+package feedbinapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// DefaultStatsInitialWindow is how far back the first snapshot of a
+	// history fetches entry metadata.
+	DefaultStatsInitialWindow = 30 * 24 * time.Hour
+	// DefaultCandidateMinEntries is the number of entries a feed needs in the
+	// report window before it can be suggested for unsubscribing.
+	DefaultCandidateMinEntries = 20
+	// DefaultCandidateMaxReadRatio is the read ratio at or below which a feed
+	// is suggested for unsubscribing.
+	DefaultCandidateMaxReadRatio = 0.1
+	// DefaultMaxReadInterval is the longest gap between two snapshots whose
+	// reads are used for time-of-day patterns. Reads in longer gaps cannot be
+	// placed precisely enough.
+	DefaultMaxReadInterval = 3 * time.Hour
+
+	// statsLookupBatchSize is the most IDs requested at once with the ids
+	// parameter of GET /v2/entries.json.
+	statsLookupBatchSize = 100
+)
+
+// StatsService records snapshots of the user's reading state so that reading
+// behavior can be analyzed over time. Feedbin keeps no reading history beyond
+// the recently read list, so snapshots should be taken regularly, e.g. hourly.
+type StatsService struct {
+	client *Client
+	now    func() time.Time
+}
+
+// NewStatsService creates a new service for reading statistics.
+func NewStatsService(client *Client) *StatsService {
+	return &StatsService{client: client, now: time.Now}
+}
+
+// ReadingSnapshot is the reading state at one point in time.
+type ReadingSnapshot struct {
+	TakenAt         time.Time `json:"taken_at"`
+	UnreadIDs       []int64   `json:"unread_ids"`
+	StarredIDs      []int64   `json:"starred_ids"`
+	RecentlyReadIDs []int64   `json:"recently_read_ids"`
+}
+
+// EntryMeta is the part of an entry the statistics need.
+type EntryMeta struct {
+	FeedID      int64     `json:"feed_id"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+}
+
+// ReadingHistory is a series of snapshots along with the metadata of every
+// entry they refer to and the latest subscriptions and taggings.
+type ReadingHistory struct {
+	Snapshots     []ReadingSnapshot   `json:"snapshots"`
+	Entries       map[int64]EntryMeta `json:"entries"`
+	Subscriptions []Subscription      `json:"subscriptions"`
+	Taggings      []Tagging           `json:"taggings"`
+}
+
+// NewReadingHistory returns an empty history.
+func NewReadingHistory() *ReadingHistory {
+	return &ReadingHistory{Entries: make(map[int64]EntryMeta)}
+}
+
+// LoadReadingHistory reads a history saved with Save. A missing file yields
+// an empty history.
+func LoadReadingHistory(path string) (*ReadingHistory, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewReadingHistory(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewReadingHistory()
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("decoding reading history %s: %w", path, err)
+	}
+	if h.Entries == nil {
+		h.Entries = make(map[int64]EntryMeta)
+	}
+	return h, nil
+}
+
+// Save writes the history to path as JSON, replacing the file atomically.
+func (h *ReadingHistory) Save(path string) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Prune drops snapshots taken before t, and the metadata of entries that no
+// remaining snapshot refers to and that were first seen before t.
+func (h *ReadingHistory) Prune(t time.Time) {
+	kept := h.Snapshots[:0]
+	for _, snap := range h.Snapshots {
+		if !snap.TakenAt.Before(t) {
+			kept = append(kept, snap)
+		}
+	}
+	h.Snapshots = kept
+
+	referenced := make(map[int64]bool)
+	for _, snap := range h.Snapshots {
+		for _, ids := range [][]int64{snap.UnreadIDs, snap.StarredIDs, snap.RecentlyReadIDs} {
+			for _, id := range ids {
+				referenced[id] = true
+			}
+		}
+	}
+	for id, meta := range h.Entries {
+		if !referenced[id] && meta.FirstSeenAt.Before(t) {
+			delete(h.Entries, id)
+		}
+	}
+}
+
+// SnapshotOptions specifies optional parameters for StatsService.Snapshot.
+type SnapshotOptions struct {
+	// InitialWindow is how far back entries are fetched when the history has
+	// no snapshots yet. Defaults to DefaultStatsInitialWindow.
+	InitialWindow time.Duration
+}
+
+// Snapshot records the current unread, starred and recently read entry IDs
+// in history, together with the subscriptions and taggings. It fetches the
+// metadata of entries created since the previous snapshot, so entries that
+// were read before a snapshot saw them unread are still counted, and of any
+// other entry the snapshot refers to that the history does not know yet.
+func (s *StatsService) Snapshot(ctx context.Context, history *ReadingHistory, opts *SnapshotOptions) (*ReadingSnapshot, error) {
+	if history.Entries == nil {
+		history.Entries = make(map[int64]EntryMeta)
+	}
+	now := s.now()
+
+	unreadIDs, _, err := s.client.UnreadEntries.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing unread entries: %w", err)
+	}
+	starredIDs, _, err := s.client.StarredEntries.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing starred entries: %w", err)
+	}
+	recentIDs, _, err := s.client.RecentlyReadEntries.ListIDs()
+	if err != nil {
+		return nil, fmt.Errorf("listing recently read entries: %w", err)
+	}
+
+	var subscriptions []Subscription
+	for sub, err := range s.client.Subscriptions.AllSubscriptions(ctx, nil, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("listing subscriptions: %w", err)
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	taggings, _, err := s.client.Taggings.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing taggings: %w", err)
+	}
+
+	since := now.Add(-DefaultStatsInitialWindow)
+	if opts != nil && opts.InitialWindow > 0 {
+		since = now.Add(-opts.InitialWindow)
+	}
+	if n := len(history.Snapshots); n > 0 {
+		since = history.Snapshots[n-1].TakenAt
+	}
+	listOpts := &EntryListOptions{ListOptions: ListOptions{Since: FormatFeedbinTime(since)}}
+	for entry, err := range s.client.Entries.AllEntries(ctx, listOpts, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("listing entries since %s: %w", listOpts.Since, err)
+		}
+		history.addEntry(entry, now)
+	}
+
+	var missing []int64
+	seen := make(map[int64]bool)
+	for _, ids := range [][]int64{unreadIDs, starredIDs, recentIDs} {
+		for _, id := range ids {
+			if _, ok := history.Entries[id]; !ok && !seen[id] {
+				seen[id] = true
+				missing = append(missing, id)
+			}
+		}
+	}
+	for start := 0; start < len(missing); start += statsLookupBatchSize {
+		end := min(start+statsLookupBatchSize, len(missing))
+		entries, _, err := s.client.Entries.List(&EntryListOptions{IDs: missing[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("fetching entry metadata: %w", err)
+		}
+		for _, entry := range entries {
+			history.addEntry(entry, now)
+		}
+	}
+
+	snap := ReadingSnapshot{
+		TakenAt:         now,
+		UnreadIDs:       unreadIDs,
+		StarredIDs:      starredIDs,
+		RecentlyReadIDs: recentIDs,
+	}
+	history.Snapshots = append(history.Snapshots, snap)
+	history.Subscriptions = subscriptions
+	history.Taggings = taggings
+	return &snap, nil
+}
+
+func (h *ReadingHistory) addEntry(entry Entry, seenAt time.Time) {
+	if _, ok := h.Entries[entry.ID]; ok {
+		return
+	}
+	h.Entries[entry.ID] = EntryMeta{
+		FeedID:      entry.FeedID,
+		PublishedAt: entry.PublishedAt,
+		CreatedAt:   entry.CreatedAt,
+		FirstSeenAt: seenAt,
+	}
+}
+
+// ReadingReportOptions specifies optional parameters for ReadingHistory.Report.
+type ReadingReportOptions struct {
+	// Since limits the report to entries created at or after this time and
+	// to snapshots taken since then. The zero value covers the whole history.
+	Since time.Time
+
+	// Location is the time zone of the time-of-day patterns. Defaults to
+	// time.Local.
+	Location *time.Location
+
+	// CandidateMinEntries and CandidateMaxReadRatio select the feeds that
+	// are suggested for unsubscribing. Default to DefaultCandidateMinEntries
+	// and DefaultCandidateMaxReadRatio.
+	CandidateMinEntries   int
+	CandidateMaxReadRatio float64
+
+	// MaxReadInterval defaults to DefaultMaxReadInterval.
+	MaxReadInterval time.Duration
+}
+
+// ReadCounts counts entries by their state in the latest snapshot. Entries
+// that are not unread count as read, whether they were opened or marked read.
+type ReadCounts struct {
+	Entries   int     `json:"entries"`
+	Read      int     `json:"read"`
+	Unread    int     `json:"unread"`
+	Starred   int     `json:"starred"`
+	ReadRatio float64 `json:"read_ratio"`
+}
+
+func (c *ReadCounts) add(unread, starred bool) {
+	c.Entries++
+	if unread {
+		c.Unread++
+	} else {
+		c.Read++
+	}
+	if starred {
+		c.Starred++
+	}
+	c.ReadRatio = float64(c.Read) / float64(c.Entries)
+}
+
+// FeedReadingStats are the reading statistics of one feed.
+type FeedReadingStats struct {
+	FeedID     int64    `json:"feed_id"`
+	Title      string   `json:"title"`
+	Subscribed bool     `json:"subscribed"`
+	Tags       []string `json:"tags,omitempty"`
+	ReadCounts
+}
+
+// TagReadingStats are the reading statistics of the feeds with one tag.
+type TagReadingStats struct {
+	Tag   string `json:"tag"`
+	Feeds int    `json:"feeds"`
+	ReadCounts
+}
+
+// BacklogPoint is the number of unread entries in one snapshot.
+type BacklogPoint struct {
+	TakenAt time.Time `json:"taken_at"`
+	Unread  int       `json:"unread"`
+}
+
+// ReadingReport summarizes reading behavior over a history.
+type ReadingReport struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Snapshots int       `json:"snapshots"`
+	ReadCounts
+
+	// Feeds are sorted by number of entries, most first, and Tags by name.
+	Feeds []FeedReadingStats `json:"feeds"`
+	Tags  []TagReadingStats  `json:"tags"`
+
+	// UnsubscribeCandidates are subscribed feeds with many entries that are
+	// hardly ever read and never starred, most unread first.
+	UnsubscribeCandidates []FeedReadingStats `json:"unsubscribe_candidates"`
+
+	// ReadsByHour and ReadsByWeekday count reads observed between snapshots
+	// that were at most MaxReadInterval apart, placed at the midpoint of the
+	// interval. ReadsByWeekday is indexed by time.Weekday.
+	ReadsByHour    [24]int `json:"reads_by_hour"`
+	ReadsByWeekday [7]int  `json:"reads_by_weekday"`
+
+	Backlog []BacklogPoint `json:"backlog"`
+	// BacklogGrowthPerDay is the change in unread entries per day between
+	// the first and last snapshot of the report.
+	BacklogGrowthPerDay float64 `json:"backlog_growth_per_day"`
+}
+
+// Report analyzes the history. It needs at least one snapshot.
+func (h *ReadingHistory) Report(opts *ReadingReportOptions) (*ReadingReport, error) {
+	o := ReadingReportOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Location == nil {
+		o.Location = time.Local
+	}
+	if o.CandidateMinEntries <= 0 {
+		o.CandidateMinEntries = DefaultCandidateMinEntries
+	}
+	if o.CandidateMaxReadRatio <= 0 {
+		o.CandidateMaxReadRatio = DefaultCandidateMaxReadRatio
+	}
+	if o.MaxReadInterval <= 0 {
+		o.MaxReadInterval = DefaultMaxReadInterval
+	}
+
+	var snaps []ReadingSnapshot
+	for _, snap := range h.Snapshots {
+		if !snap.TakenAt.Before(o.Since) {
+			snaps = append(snaps, snap)
+		}
+	}
+	if len(snaps) == 0 {
+		return nil, errors.New("reading history has no snapshots in the report period")
+	}
+	latest := snaps[len(snaps)-1]
+
+	report := &ReadingReport{
+		From:      snaps[0].TakenAt,
+		To:        latest.TakenAt,
+		Snapshots: len(snaps),
+	}
+	if !o.Since.IsZero() {
+		report.From = o.Since
+	}
+
+	titles := make(map[int64]string)
+	for _, sub := range h.Subscriptions {
+		titles[sub.FeedID] = sub.Title
+	}
+	feedTags := make(map[int64][]string)
+	for _, tagging := range h.Taggings {
+		if !containsString(feedTags[tagging.FeedID], tagging.Name) {
+			feedTags[tagging.FeedID] = append(feedTags[tagging.FeedID], tagging.Name)
+		}
+	}
+
+	unread := idSet(latest.UnreadIDs)
+	starred := idSet(latest.StarredIDs)
+	feeds := make(map[int64]*FeedReadingStats)
+	tags := make(map[string]*TagReadingStats)
+	tagFeeds := make(map[string]map[int64]bool)
+	for id, meta := range h.Entries {
+		created := meta.CreatedAt
+		if created.IsZero() {
+			created = meta.FirstSeenAt
+		}
+		if created.Before(o.Since) {
+			continue
+		}
+
+		isUnread, isStarred := unread[id], starred[id]
+		report.ReadCounts.add(isUnread, isStarred)
+
+		feed := feeds[meta.FeedID]
+		if feed == nil {
+			title, subscribed := titles[meta.FeedID]
+			if !subscribed {
+				title = fmt.Sprintf("Feed %d", meta.FeedID)
+			}
+			feed = &FeedReadingStats{FeedID: meta.FeedID, Title: title, Subscribed: subscribed, Tags: feedTags[meta.FeedID]}
+			feeds[meta.FeedID] = feed
+		}
+		feed.add(isUnread, isStarred)
+
+		for _, name := range feedTags[meta.FeedID] {
+			tag := tags[name]
+			if tag == nil {
+				tag = &TagReadingStats{Tag: name}
+				tags[name] = tag
+				tagFeeds[name] = make(map[int64]bool)
+			}
+			tag.add(isUnread, isStarred)
+			tagFeeds[name][meta.FeedID] = true
+		}
+	}
+
+	for _, feed := range feeds {
+		report.Feeds = append(report.Feeds, *feed)
+		if feed.Subscribed && feed.Entries >= o.CandidateMinEntries && feed.ReadRatio <= o.CandidateMaxReadRatio && feed.Starred == 0 {
+			report.UnsubscribeCandidates = append(report.UnsubscribeCandidates, *feed)
+		}
+	}
+	sort.Slice(report.Feeds, func(i, j int) bool {
+		a, b := report.Feeds[i], report.Feeds[j]
+		if a.Entries != b.Entries {
+			return a.Entries > b.Entries
+		}
+		return a.FeedID < b.FeedID
+	})
+	sort.Slice(report.UnsubscribeCandidates, func(i, j int) bool {
+		a, b := report.UnsubscribeCandidates[i], report.UnsubscribeCandidates[j]
+		if a.Unread != b.Unread {
+			return a.Unread > b.Unread
+		}
+		return a.FeedID < b.FeedID
+	})
+	for name, tag := range tags {
+		tag.Feeds = len(tagFeeds[name])
+		report.Tags = append(report.Tags, *tag)
+	}
+	sort.Slice(report.Tags, func(i, j int) bool { return report.Tags[i].Tag < report.Tags[j].Tag })
+
+	for i, snap := range snaps {
+		report.Backlog = append(report.Backlog, BacklogPoint{TakenAt: snap.TakenAt, Unread: len(snap.UnreadIDs)})
+		if i == 0 {
+			continue
+		}
+		prev := snaps[i-1]
+		gap := snap.TakenAt.Sub(prev.TakenAt)
+		if gap <= 0 || gap > o.MaxReadInterval {
+			continue
+		}
+		at := prev.TakenAt.Add(gap / 2).In(o.Location)
+		reads := readsBetween(prev, snap)
+		report.ReadsByHour[at.Hour()] += reads
+		report.ReadsByWeekday[at.Weekday()] += reads
+	}
+	if days := latest.TakenAt.Sub(snaps[0].TakenAt).Hours() / 24; days > 0 {
+		report.BacklogGrowthPerDay = float64(len(latest.UnreadIDs)-len(snaps[0].UnreadIDs)) / days
+	}
+	return report, nil
+}
+
+// readsBetween counts the entries read between two snapshots: those that
+// stopped being unread and those that joined the recently read list.
+func readsBetween(prev, next ReadingSnapshot) int {
+	read := make(map[int64]bool)
+	stillUnread := idSet(next.UnreadIDs)
+	for _, id := range prev.UnreadIDs {
+		if !stillUnread[id] {
+			read[id] = true
+		}
+	}
+	wasRecent := idSet(prev.RecentlyReadIDs)
+	for _, id := range next.RecentlyReadIDs {
+		if !wasRecent[id] {
+			read[id] = true
+		}
+	}
+	return len(read)
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *ReadingReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the report as plain text tables for a terminal.
+func (r *ReadingReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	const day = "2006-01-02 15:04"
+
+	fmt.Fprintf(tw, "Reading report %s to %s (%d snapshots)\n", r.From.Format(day), r.To.Format(day), r.Snapshots)
+	fmt.Fprintf(tw, "%d entries, %d read (%s), %d unread, %d starred\n\n",
+		r.Entries, r.Read, percent(r.ReadRatio), r.Unread, r.Starred)
+
+	fmt.Fprintln(tw, "FEED\tENTRIES\tREAD\tUNREAD\tSTARRED\tREAD %\tTAGS")
+	for _, f := range r.Feeds {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			f.Title, f.Entries, f.Read, f.Unread, f.Starred, percent(f.ReadRatio), strings.Join(f.Tags, ", "))
+	}
+
+	if len(r.Tags) > 0 {
+		fmt.Fprintln(tw, "\nTAG\tFEEDS\tENTRIES\tREAD\tUNREAD\tSTARRED\tREAD %")
+		for _, t := range r.Tags {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+				t.Tag, t.Feeds, t.Entries, t.Read, t.Unread, t.Starred, percent(t.ReadRatio))
+		}
+	}
+
+	fmt.Fprintln(tw, "\nUNSUBSCRIBE CANDIDATES\tENTRIES\tUNREAD\tREAD %")
+	if len(r.UnsubscribeCandidates) == 0 {
+		fmt.Fprintln(tw, "(none)\t\t\t")
+	}
+	for _, f := range r.UnsubscribeCandidates {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", f.Title, f.Entries, f.Unread, percent(f.ReadRatio))
+	}
+
+	fmt.Fprintln(tw, "\nHOUR\tREADS\t")
+	maxReads := 0
+	for _, n := range r.ReadsByHour {
+		maxReads = max(maxReads, n)
+	}
+	for hour, n := range r.ReadsByHour {
+		fmt.Fprintf(tw, "%02d:00\t%d\t%s\n", hour, n, bar(n, maxReads, 40))
+	}
+
+	fmt.Fprintln(tw, "\nDAY\tREADS\t")
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		fmt.Fprintf(tw, "%s\t%d\t\n", day.String()[:3], r.ReadsByWeekday[day])
+	}
+
+	if len(r.Backlog) > 0 {
+		first, last := r.Backlog[0], r.Backlog[len(r.Backlog)-1]
+		fmt.Fprintf(tw, "\nBacklog: %d unread at %s, %d at %s (%+.1f per day)\n",
+			first.Unread, first.TakenAt.Format(day), last.Unread, last.TakenAt.Format(day), r.BacklogGrowthPerDay)
+	}
+	return tw.Flush()
+}
+
+func percent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
+
+func bar(n, maxN, width int) string {
+	if maxN == 0 {
+		return ""
+	}
+	return strings.Repeat("#", n*width/maxN)
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package feedbinapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStats_Snapshot records the reading state and fetches the metadata of
+// new entries and of unknown entries referred to by ID.
+func TestStats_Snapshot(t *testing.T) {
+	client, mux, teardown := setupTestServer(t)
+	defer teardown()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	client.Stats.now = func() time.Time { return now }
+
+	mux.HandleFunc("/unread_entries.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[1, 2, 3]`)
+	})
+	mux.HandleFunc("/starred_entries.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[3]`)
+	})
+	mux.HandleFunc("/recently_read_entries.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[4]`)
+	})
+	mux.HandleFunc("/subscriptions.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 10, "feed_id": 100, "title": "Daily News"}]`)
+	})
+	mux.HandleFunc("/taggings.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "feed_id": 100, "name": "News"}]`)
+	})
+	var idRequests []string
+	mux.HandleFunc("/entries.json", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("since") != "":
+			if want := "2024-01-31T12:00:00Z"; q.Get("since") != want {
+				t.Errorf("Expected since=%s, got %s", want, q.Get("since"))
+			}
+			fmt.Fprint(w, `[{"id": 1, "feed_id": 100}, {"id": 5, "feed_id": 100}]`)
+		case q.Get("ids") != "":
+			idRequests = append(idRequests, q.Get("ids"))
+			fmt.Fprint(w, `[{"id": 2, "feed_id": 200}, {"id": 3, "feed_id": 200}, {"id": 4, "feed_id": 100}]`)
+		default:
+			t.Errorf("Unexpected entries request %s", r.URL)
+		}
+	})
+
+	history := NewReadingHistory()
+	snap, err := client.Stats.Snapshot(context.Background(), history, nil)
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+
+	if len(snap.UnreadIDs) != 3 || len(snap.StarredIDs) != 1 || len(snap.RecentlyReadIDs) != 1 {
+		t.Errorf("Unexpected snapshot: %+v", snap)
+	}
+	if len(idRequests) != 1 || idRequests[0] != "2,3,4" {
+		t.Errorf("Expected one lookup of entries 2,3,4, got %v", idRequests)
+	}
+	if len(history.Entries) != 5 || history.Entries[3].FeedID != 200 || !history.Entries[5].FirstSeenAt.Equal(now) {
+		t.Errorf("Unexpected entry metadata: %+v", history.Entries)
+	}
+	if len(history.Subscriptions) != 1 || len(history.Taggings) != 1 {
+		t.Errorf("Expected subscriptions and taggings to be recorded")
+	}
+}
+
+// TestReadingHistory_Report computes read ratios, candidates, reading times
+// and backlog growth from a series of snapshots.
+func TestReadingHistory_Report(t *testing.T) {
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	history := NewReadingHistory()
+	history.Subscriptions = []Subscription{
+		{FeedID: 1, Title: "Noisy"},
+		{FeedID: 2, Title: "Favorite"},
+	}
+	history.Taggings = []Tagging{{FeedID: 1, Name: "News"}, {FeedID: 2, Name: "News"}, {FeedID: 2, Name: "Tech"}}
+
+	// Feed 1 has 20 entries nobody reads, feed 2 has 4 entries of which 3
+	// are read, and feed 3 is no longer subscribed.
+	var noisy []int64
+	for id := int64(1); id <= 20; id++ {
+		history.Entries[id] = EntryMeta{FeedID: 1, CreatedAt: start}
+		noisy = append(noisy, id)
+	}
+	for id := int64(21); id <= 24; id++ {
+		history.Entries[id] = EntryMeta{FeedID: 2, CreatedAt: start}
+	}
+	history.Entries[25] = EntryMeta{FeedID: 3, FirstSeenAt: start}
+
+	history.Snapshots = []ReadingSnapshot{
+		{TakenAt: start, UnreadIDs: append([]int64{21, 22, 23, 24}, noisy...)},
+		// Two reads between 08:00 and 09:00: one marked read, one opened
+		{TakenAt: start.Add(time.Hour), UnreadIDs: append([]int64{23, 24}, noisy...), RecentlyReadIDs: []int64{22}},
+		// Read in a long gap, which is left out of the reading times
+		{TakenAt: start.Add(24 * time.Hour), UnreadIDs: append([]int64{24}, noisy...), StarredIDs: []int64{21}, RecentlyReadIDs: []int64{22, 23}},
+	}
+
+	report, err := history.Report(&ReadingReportOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("Report returned error: %v", err)
+	}
+
+	if report.Entries != 25 || report.Read != 4 || report.Unread != 21 || report.Starred != 1 {
+		t.Errorf("Unexpected totals: %+v", report.ReadCounts)
+	}
+	if len(report.Feeds) != 3 || report.Feeds[0].FeedID != 1 || report.Feeds[1].ReadRatio != 0.75 {
+		t.Errorf("Unexpected feed stats: %+v", report.Feeds)
+	}
+	if report.Feeds[2].Subscribed || report.Feeds[2].Title != "Feed 3" {
+		t.Errorf("Expected feed 3 to be reported as unsubscribed, got %+v", report.Feeds[2])
+	}
+	if len(report.Tags) != 2 || report.Tags[0].Tag != "News" || report.Tags[0].Feeds != 2 || report.Tags[0].Entries != 24 {
+		t.Errorf("Unexpected tag stats: %+v", report.Tags)
+	}
+	if len(report.UnsubscribeCandidates) != 1 || report.UnsubscribeCandidates[0].FeedID != 1 {
+		t.Errorf("Expected feed 1 as the only unsubscribe candidate, got %+v", report.UnsubscribeCandidates)
+	}
+	if report.ReadsByHour[8] != 2 || report.ReadsByWeekday[time.Friday] != 2 {
+		t.Errorf("Expected 2 reads on Friday at 08:00, got %v / %v", report.ReadsByHour, report.ReadsByWeekday)
+	}
+	if len(report.Backlog) != 3 || report.Backlog[2].Unread != 21 || report.BacklogGrowthPerDay != -3 {
+		t.Errorf("Unexpected backlog: %+v, %v per day", report.Backlog, report.BacklogGrowthPerDay)
+	}
+
+	var table bytes.Buffer
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatalf("WriteTable returned error: %v", err)
+	}
+	for _, want := range []string{"Noisy", "UNSUBSCRIBE CANDIDATES", "08:00", "-3.0 per day"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("Expected table to contain %q:\n%s", want, table.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	var decoded ReadingReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.Read != 4 || len(decoded.Feeds) != 3 {
+		t.Errorf("Unexpected JSON round trip: %v, %+v", err, decoded.ReadCounts)
+	}
+}
+
+// TestReadingHistory_SaveLoad round-trips a history through a file.
+func TestReadingHistory_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	history, err := LoadReadingHistory(path)
+	if err != nil || len(history.Snapshots) != 0 {
+		t.Fatalf("Expected an empty history for a missing file, got %+v, %v", history, err)
+	}
+
+	history.Entries[7] = EntryMeta{FeedID: 1}
+	history.Snapshots = append(history.Snapshots, ReadingSnapshot{TakenAt: time.Now().UTC(), UnreadIDs: []int64{7}})
+	if err := history.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := LoadReadingHistory(path)
+	if err != nil {
+		t.Fatalf("LoadReadingHistory returned error: %v", err)
+	}
+	if len(loaded.Snapshots) != 1 || loaded.Entries[7].FeedID != 1 {
+		t.Errorf("Unexpected loaded history: %+v", loaded)
+	}
+}
@@ -158,7 +158,7 @@ func (s *SubscriptionsService) Delete(id int64) (*http.Response, error) {
 // Helper function to add query parameters to a URL path.
 // Note: This is a more generic helper. The go-querystring library is used above,
 // which is generally preferred for struct-to-querystring conversion.
-// This function is not actively used in the current code but kept for potential future use.
+// It is used by the All* iterators to build the first page URL.
 func addOptions(path string, opts interface{}) (string, error) {
 	if opts == nil {
 		return path, nil
@@ -1,5 +1,5 @@
 module jules-feedbin-client
 
-go 1.22.2
+go 1.23
 
 require github.com/google/go-querystring v1.1.0
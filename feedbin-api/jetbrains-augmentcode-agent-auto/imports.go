@@ -47,8 +47,13 @@ func (s *ImportsService) Get(id int64) (*Import, *http.Response, error) {
 	return imp, resp, nil
 }
 
-// Create creates a new import from an OPML file.
+// Create creates a new import from an OPML file. The content is parsed
+// locally first, and nothing is sent if it is not an OPML document.
 func (s *ImportsService) Create(opmlContent string) (*Import, *http.Response, error) {
+	if _, err := ParseOPML(strings.NewReader(opmlContent)); err != nil {
+		return nil, nil, err
+	}
+
 	req, err := s.client.NewRequest(http.MethodPost, "/v2/imports.json", nil)
 	if err != nil {
 		return nil, nil, err
@@ -68,3 +73,166 @@ func (s *ImportsService) Create(opmlContent string) (*Import, *http.Response, er
 
 	return imp, resp, nil
 }
+
+// ImportPlanItem is a feed of an OPML document matched against the
+// current subscriptions.
+type ImportPlanItem struct {
+	Feed OPMLFeed
+
+	// Subscription is the existing subscription for the feed, or nil if
+	// the feed would be newly added.
+	Subscription *Subscription
+
+	// NewTags holds the tags of the feed in the document that the feed
+	// does not have yet.
+	NewTags []string
+}
+
+// ImportPlan describes what importing an OPML document would change.
+type ImportPlan struct {
+	// Title is the title of the OPML document.
+	Title string
+
+	// Add holds the feeds that are not subscribed yet.
+	Add []ImportPlanItem
+
+	// Retag holds subscribed feeds that would get new tags.
+	Retag []ImportPlanItem
+
+	// Unchanged holds subscribed feeds that already have all their tags.
+	Unchanged []ImportPlanItem
+
+	// Issues holds the problems found while validating the document.
+	Issues []OPMLIssue
+}
+
+// Empty reports whether the plan would not change anything.
+func (p *ImportPlan) Empty() bool {
+	return len(p.Add) == 0 && len(p.Retag) == 0
+}
+
+// OPML returns an OPML document with only the feeds to add, so the delta
+// can be reviewed or passed to ImportsService.Create.
+func (p *ImportPlan) OPML() (string, error) {
+	feeds := make([]OPMLFeed, len(p.Add))
+	for i, item := range p.Add {
+		feeds[i] = item.Feed
+	}
+
+	data, err := MarshalOPML(p.Title, feeds)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// NewImportPlan matches the feeds of an OPML document against
+// subscriptions and taggings. Feed URLs are compared after normalization,
+// see ParseOPML.
+func NewImportPlan(doc *OPMLDocument, subscriptions []*Subscription, taggings []*Tagging) *ImportPlan {
+	byURL := make(map[string]*Subscription, len(subscriptions))
+	for _, sub := range subscriptions {
+		if key, err := normalizeFeedURL(sub.FeedURL); err == nil {
+			byURL[key] = sub
+		}
+	}
+
+	tags := make(map[int64]map[string]bool)
+	for _, tagging := range taggings {
+		if tags[tagging.FeedID] == nil {
+			tags[tagging.FeedID] = make(map[string]bool)
+		}
+		tags[tagging.FeedID][tagging.Name] = true
+	}
+
+	plan := &ImportPlan{
+		Title:  doc.Title,
+		Issues: doc.Issues,
+	}
+
+	for _, feed := range doc.Feeds {
+		item := ImportPlanItem{Feed: feed}
+
+		key, _ := normalizeFeedURL(feed.FeedURL)
+		sub, ok := byURL[key]
+		if !ok {
+			item.NewTags = feed.Tags
+			plan.Add = append(plan.Add, item)
+			continue
+		}
+
+		item.Subscription = sub
+		for _, tag := range feed.Tags {
+			if !tags[sub.FeedID][tag] {
+				item.NewTags = append(item.NewTags, tag)
+			}
+		}
+
+		if len(item.NewTags) > 0 {
+			plan.Retag = append(plan.Retag, item)
+		} else {
+			plan.Unchanged = append(plan.Unchanged, item)
+		}
+	}
+
+	return plan
+}
+
+// Plan validates an OPML document and compares it with the current
+// subscriptions and taggings, without importing anything.
+func (s *ImportsService) Plan(opmlContent string) (*ImportPlan, *http.Response, error) {
+	doc, err := ParseOPML(strings.NewReader(opmlContent))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subscriptions, resp, err := s.client.Subscriptions.List(nil)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	taggings, resp, err := s.client.Taggings.List()
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return NewImportPlan(doc, subscriptions, taggings), resp, nil
+}
+
+// Apply imports the delta of a plan: the feeds to add are imported as
+// OPML, and the new tags of subscribed feeds are created as taggings.
+// The returned import is nil if there was nothing to add.
+func (s *ImportsService) Apply(plan *ImportPlan) (*Import, *http.Response, error) {
+	var (
+		imp  *Import
+		resp *http.Response
+		err  error
+	)
+
+	if len(plan.Add) > 0 {
+		content, err := plan.OPML()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		imp, resp, err = s.Create(content)
+		if err != nil {
+			return nil, resp, err
+		}
+	}
+
+	for _, item := range plan.Retag {
+		for _, tag := range item.NewTags {
+			_, resp, err = s.client.Taggings.Create(&CreateTaggingOptions{
+				FeedID: item.Subscription.FeedID,
+				Name:   tag,
+			})
+			if err != nil {
+				return imp, resp, err
+			}
+		}
+	}
+
+	return imp, resp, nil
+}
@@ -0,0 +1,373 @@
+package feedbin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// OPMLFeed represents a feed found in an OPML document.
+type OPMLFeed struct {
+	Title   string
+	FeedURL string
+	SiteURL string
+
+	// Tags holds the names of the folders the feed appears in.
+	Tags []string
+
+	// Line is the line of the first outline for the feed.
+	Line int
+}
+
+// OPMLIssue describes a problem found while validating an OPML document.
+type OPMLIssue struct {
+	Line    int
+	FeedURL string
+	Message string
+}
+
+// String returns the issue as "line N: message".
+func (i OPMLIssue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// OPMLDocument is the result of parsing and validating an OPML document.
+type OPMLDocument struct {
+	Title string
+
+	// Feeds holds each feed once, in document order. A feed that appears
+	// in several folders gets the names of all of them as tags.
+	Feeds []OPMLFeed
+
+	// Issues holds the malformed outlines and duplicate feeds that were
+	// skipped while parsing.
+	Issues []OPMLIssue
+}
+
+// Valid reports whether the document was parsed without issues.
+func (d *OPMLDocument) Valid() bool {
+	return len(d.Issues) == 0
+}
+
+// opmlFrame is an outline element that is still open while parsing.
+type opmlFrame struct {
+	line     int
+	feed     bool
+	folder   string
+	children int
+}
+
+// ParseOPML reads and validates an OPML document. An error is returned only
+// when the input is not an OPML document at all; problems with individual
+// outlines are reported in the Issues of the returned document.
+//
+// Nested folders are flattened: a feed is tagged with the name of its
+// innermost folder.
+func ParseOPML(r io.Reader) (*OPMLDocument, error) {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = opmlCharsetReader
+	doc := &OPMLDocument{}
+
+	var (
+		path     []string
+		outlines []opmlFrame
+		seenRoot bool
+		seenBody bool
+		index    = make(map[string]int)
+		seen     = make(map[string]bool)
+	)
+
+	issue := func(line int, feedURL, format string, args ...interface{}) {
+		doc.Issues = append(doc.Issues, OPMLIssue{
+			Line:    line,
+			FeedURL: feedURL,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OPML: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			line, _ := dec.InputPos()
+			name := t.Name.Local
+
+			if len(path) == 0 && name != "opml" {
+				return nil, fmt.Errorf("invalid OPML: root element is <%s>, want <opml>", name)
+			}
+			seenRoot = true
+			path = append(path, name)
+
+			if name == "body" && len(path) == 2 {
+				seenBody = true
+			}
+			if name != "outline" {
+				continue
+			}
+
+			frame := opmlFrame{line: line}
+			if len(outlines) > 0 {
+				outlines[len(outlines)-1].children++
+			} else if len(path) != 3 || path[1] != "body" {
+				issue(line, "", "outline outside of <body>")
+			}
+
+			attrs := outlineAttrs(t.Attr)
+			title := attrs["title"]
+			if title == "" {
+				title = attrs["text"]
+			}
+
+			feedURL := strings.TrimSpace(attrs["xmlUrl"])
+			if feedURL == "" {
+				frame.folder = title
+				outlines = append(outlines, frame)
+				continue
+			}
+
+			frame.feed = true
+			if len(outlines) > 0 && outlines[len(outlines)-1].feed {
+				issue(line, feedURL, "feed %s is nested inside another feed", feedURL)
+				outlines = append(outlines, frame)
+				continue
+			}
+
+			key, err := normalizeFeedURL(feedURL)
+			if err != nil {
+				issue(line, feedURL, "invalid xmlUrl %q: %v", feedURL, err)
+				outlines = append(outlines, frame)
+				continue
+			}
+
+			tag := ""
+			for i := len(outlines) - 1; i >= 0; i-- {
+				if outlines[i].folder != "" {
+					tag = outlines[i].folder
+					break
+				}
+			}
+
+			if seen[key+"\x00"+tag] {
+				if tag == "" {
+					issue(line, feedURL, "duplicate feed %s at the top level", feedURL)
+				} else {
+					issue(line, feedURL, "duplicate feed %s in folder %q", feedURL, tag)
+				}
+				outlines = append(outlines, frame)
+				continue
+			}
+			seen[key+"\x00"+tag] = true
+
+			i, ok := index[key]
+			if !ok {
+				i = len(doc.Feeds)
+				index[key] = i
+				doc.Feeds = append(doc.Feeds, OPMLFeed{
+					Title:   title,
+					FeedURL: feedURL,
+					SiteURL: strings.TrimSpace(attrs["htmlUrl"]),
+					Line:    line,
+				})
+			}
+			if tag != "" {
+				doc.Feeds[i].Tags = appendUnique(doc.Feeds[i].Tags, tag)
+			}
+
+			outlines = append(outlines, frame)
+
+		case xml.EndElement:
+			if t.Name.Local == "outline" && len(outlines) > 0 {
+				frame := outlines[len(outlines)-1]
+				outlines = outlines[:len(outlines)-1]
+
+				if !frame.feed && frame.children == 0 {
+					issue(frame.line, "", "outline has neither an xmlUrl nor child outlines")
+				} else if !frame.feed && frame.folder == "" {
+					issue(frame.line, "", "folder outline has no text or title")
+				}
+			}
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+
+		case xml.CharData:
+			if len(path) == 3 && path[1] == "head" && path[2] == "title" {
+				doc.Title += strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	if !seenRoot {
+		return nil, errors.New("invalid OPML: empty document")
+	}
+	if !seenBody {
+		return nil, errors.New("invalid OPML: missing <body>")
+	}
+
+	return doc, nil
+}
+
+// cp1252 holds the characters of windows-1252 for the bytes 0x80-0x9F, where
+// it differs from ISO-8859-1. Undefined bytes decode to U+FFFD.
+var cp1252 = [32]rune{
+	'€', '\uFFFD', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\uFFFD', 'Ž', '\uFFFD',
+	'\uFFFD', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\uFFFD', 'ž', 'Ÿ',
+}
+
+// opmlCharsetReader decodes the single-byte encodings OPML exports are
+// commonly saved in. Each byte of ISO-8859-1 is the code point of the same
+// value, and windows-1252 only differs in the range 0x80-0x9F.
+func opmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	windows := false
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "latin1", "us-ascii":
+	case "windows-1252", "cp1252":
+		windows = true
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", charset)
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+		if windows && b >= 0x80 && b < 0xA0 {
+			runes[i] = cp1252[b-0x80]
+		}
+	}
+
+	return strings.NewReader(string(runes)), nil
+}
+
+// outlineAttrs returns the attributes of an outline element by local name.
+func outlineAttrs(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Name.Local] = attr.Value
+	}
+	return m
+}
+
+// normalizeFeedURL returns a key under which equivalent feed URLs compare
+// equal. The scheme and host are lowercased, default ports, fragments and
+// trailing slashes are dropped, and http and https are treated as the same.
+func normalizeFeedURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", errors.New("not an http or https URL")
+	}
+	if u.Host == "" {
+		return "", errors.New("missing host")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	key := host + strings.TrimRight(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+
+	return key, nil
+}
+
+// appendUnique appends v to s unless s already contains it.
+func appendUnique(s []string, v string) []string {
+	for _, item := range s {
+		if item == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+// opmlXML is the document written by MarshalOPML.
+type opmlXML struct {
+	XMLName xml.Name         `xml:"opml"`
+	Version string           `xml:"version,attr"`
+	Title   string           `xml:"head>title,omitempty"`
+	Body    []opmlOutlineXML `xml:"body>outline"`
+}
+
+// opmlOutlineXML is an outline element written by MarshalOPML.
+type opmlOutlineXML struct {
+	Text     string           `xml:"text,attr"`
+	Title    string           `xml:"title,attr,omitempty"`
+	Type     string           `xml:"type,attr,omitempty"`
+	XMLURL   string           `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string           `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutlineXML `xml:"outline"`
+}
+
+// MarshalOPML returns an OPML 2.0 document for feeds. Each feed is placed in
+// a folder per tag, and untagged feeds at the top level.
+func MarshalOPML(title string, feeds []OPMLFeed) ([]byte, error) {
+	doc := opmlXML{Version: "2.0", Title: title}
+
+	folders := make(map[string]int)
+	var untagged []opmlOutlineXML
+
+	for _, feed := range feeds {
+		text := feed.Title
+		if text == "" {
+			text = feed.FeedURL
+		}
+		outline := opmlOutlineXML{
+			Text:    text,
+			Title:   text,
+			Type:    "rss",
+			XMLURL:  feed.FeedURL,
+			HTMLURL: feed.SiteURL,
+		}
+
+		if len(feed.Tags) == 0 {
+			untagged = append(untagged, outline)
+			continue
+		}
+
+		for _, tag := range feed.Tags {
+			i, ok := folders[tag]
+			if !ok {
+				i = len(doc.Body)
+				folders[tag] = i
+				doc.Body = append(doc.Body, opmlOutlineXML{Text: tag, Title: tag})
+			}
+			doc.Body[i].Outlines = append(doc.Body[i].Outlines, outline)
+		}
+	}
+	doc.Body = append(doc.Body, untagged...)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
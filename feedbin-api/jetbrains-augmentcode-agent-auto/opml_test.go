@@ -0,0 +1,202 @@
+package feedbin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>My Feeds</title></head>
+  <body>
+    <outline text="Tech">
+      <outline text="Example" xmlUrl="https://example.com/feed.xml" htmlUrl="https://example.com"/>
+      <outline text="Example again" xmlUrl="HTTPS://Example.com/feed.xml/"/>
+      <outline text="Go" xmlUrl="https://go.dev/blog/feed.atom"/>
+    </outline>
+    <outline text="News">
+      <outline text="Example" xmlUrl="https://example.com/feed.xml"/>
+      <outline text="Daily" xmlUrl="https://daily.example.org/rss"/>
+    </outline>
+    <outline text="Broken" xmlUrl="ftp://example.com/feed"/>
+    <outline text="Empty folder"/>
+    <outline text="Untagged" xmlUrl="https://untagged.example.net/feed"/>
+  </body>
+</opml>`
+
+func TestParseOPML(t *testing.T) {
+	doc, err := ParseOPML(strings.NewReader(testOPML))
+	if err != nil {
+		t.Fatalf("ParseOPML returned error: %v", err)
+	}
+
+	if doc.Title != "My Feeds" {
+		t.Errorf("ParseOPML Title = %q, want %q", doc.Title, "My Feeds")
+	}
+
+	var urls []string
+	for _, feed := range doc.Feeds {
+		urls = append(urls, feed.FeedURL)
+	}
+	wantURLs := []string{
+		"https://example.com/feed.xml",
+		"https://go.dev/blog/feed.atom",
+		"https://daily.example.org/rss",
+		"https://untagged.example.net/feed",
+	}
+	if !reflect.DeepEqual(urls, wantURLs) {
+		t.Errorf("ParseOPML feeds = %v, want %v", urls, wantURLs)
+	}
+
+	if got, want := doc.Feeds[0].Tags, []string{"Tech", "News"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseOPML tags = %v, want %v", got, want)
+	}
+
+	if len(doc.Issues) != 3 {
+		t.Fatalf("ParseOPML returned %d issues, want 3: %v", len(doc.Issues), doc.Issues)
+	}
+	for i, want := range []string{"duplicate feed", "invalid xmlUrl", "neither an xmlUrl"} {
+		if !strings.Contains(doc.Issues[i].Message, want) {
+			t.Errorf("Issue %d = %q, want it to contain %q", i, doc.Issues[i].Message, want)
+		}
+	}
+	if doc.Issues[0].Line != 7 {
+		t.Errorf("Duplicate feed reported on line %d, want 7", doc.Issues[0].Line)
+	}
+}
+
+func TestParseOPMLInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not xml",
+		"<rss><channel/></rss>",
+		`<opml version="2.0"><head/></opml>`,
+		`<opml version="2.0"><body><outline text="a"></body></opml>`,
+	}
+
+	for _, input := range tests {
+		if _, err := ParseOPML(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseOPML(%q) should have returned an error", input)
+		}
+	}
+}
+
+func TestParseOPMLCharset(t *testing.T) {
+	tests := []struct {
+		encoding string
+		title    string
+		want     string
+	}{
+		{"ISO-8859-1", "Caf\xe9 \xabNews\xbb", "Café «News»"},
+		{"windows-1252", "\x93Quoted\x94 \x96 Caf\xe9 \x80", "“Quoted” – Café €"},
+	}
+
+	for _, tt := range tests {
+		input := `<?xml version="1.0" encoding="` + tt.encoding + `"?>
+<opml version="2.0"><body>
+  <outline text="` + tt.title + `" xmlUrl="https://example.com/feed.xml"/>
+</body></opml>`
+
+		doc, err := ParseOPML(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("ParseOPML with encoding %s returned error: %v", tt.encoding, err)
+			continue
+		}
+		if len(doc.Feeds) != 1 || doc.Feeds[0].Title != tt.want {
+			t.Errorf("ParseOPML with encoding %s feeds = %+v, want title %q", tt.encoding, doc.Feeds, tt.want)
+		}
+	}
+
+	input := `<?xml version="1.0" encoding="EBCDIC"?><opml version="2.0"><body/></opml>`
+	if _, err := ParseOPML(strings.NewReader(input)); err == nil {
+		t.Error("ParseOPML with an unsupported encoding should have returned an error")
+	}
+}
+
+func TestImportsService_CreateLatin1(t *testing.T) {
+	content := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<opml version=\"1.0\"><body><outline text=\"Caf\xe9\" xmlUrl=\"https://example.com/feed.xml\"/></body></opml>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/imports.json" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != content {
+			t.Errorf("Imports.Create sent %q, want the document unchanged", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Import{ID: 1})
+	}))
+	defer server.Close()
+
+	client := NewClient("username", "password")
+	client.SetBaseURL(server.URL)
+
+	imp, _, err := client.Imports.Create(content)
+	if err != nil {
+		t.Fatalf("Imports.Create returned error: %v", err)
+	}
+	if imp.ID != 1 {
+		t.Errorf("Imports.Create ID = %d, want 1", imp.ID)
+	}
+}
+
+func TestImportsService_Plan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/subscriptions.json":
+			json.NewEncoder(w).Encode([]*Subscription{
+				{ID: 1, FeedID: 10, FeedURL: "http://example.com/feed.xml"},
+				{ID: 2, FeedID: 20, FeedURL: "https://go.dev/blog/feed.atom"},
+			})
+		case "/v2/taggings.json":
+			json.NewEncoder(w).Encode([]*Tagging{
+				{ID: 1, FeedID: 10, Name: "Tech"},
+				{ID: 2, FeedID: 20, Name: "Tech"},
+			})
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("username", "password")
+	client.SetBaseURL(server.URL)
+
+	plan, _, err := client.Imports.Plan(testOPML)
+	if err != nil {
+		t.Fatalf("Imports.Plan returned error: %v", err)
+	}
+
+	if len(plan.Add) != 2 || plan.Add[0].Feed.FeedURL != "https://daily.example.org/rss" {
+		t.Errorf("Imports.Plan Add = %+v", plan.Add)
+	}
+	if len(plan.Retag) != 1 || plan.Retag[0].Subscription.ID != 1 || !reflect.DeepEqual(plan.Retag[0].NewTags, []string{"News"}) {
+		t.Errorf("Imports.Plan Retag = %+v", plan.Retag)
+	}
+	if len(plan.Unchanged) != 1 || plan.Unchanged[0].Subscription.ID != 2 {
+		t.Errorf("Imports.Plan Unchanged = %+v", plan.Unchanged)
+	}
+	if len(plan.Issues) != 3 {
+		t.Errorf("Imports.Plan returned %d issues, want 3", len(plan.Issues))
+	}
+
+	content, err := plan.OPML()
+	if err != nil {
+		t.Fatalf("ImportPlan.OPML returned error: %v", err)
+	}
+	delta, err := ParseOPML(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseOPML of the delta returned error: %v", err)
+	}
+	if len(delta.Feeds) != 2 || !delta.Valid() {
+		t.Errorf("Delta OPML has feeds %+v and issues %v", delta.Feeds, delta.Issues)
+	}
+}
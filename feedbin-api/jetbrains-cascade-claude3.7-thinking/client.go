@@ -40,6 +40,7 @@ type Client struct {
 	StarredEntries *StarredEntriesService
 	Tags           *TagsService
 	Taggings       *TaggingsService
+	UpdatedEntries *UpdatedEntriesService
 }
 
 // ClientOption is a function that configures a Client
@@ -71,6 +72,7 @@ func NewClient(username, password string, options ...ClientOption) (*Client, err
 	c.StarredEntries = &StarredEntriesService{client: c}
 	c.Tags = &TagsService{client: c}
 	c.Taggings = &TaggingsService{client: c}
+	c.UpdatedEntries = &UpdatedEntriesService{client: c}
 
 	return c, nil
 }
@@ -40,6 +40,7 @@ type Entry struct {
 
 	// Extended mode fields
 	Original          *EntryOriginal `json:"original,omitempty"`
+	ContentDiff       *string        `json:"content_diff,omitempty"` // With include_content_diff only
 	TwitterID         *int64         `json:"twitter_id,omitempty"`
 	TwitterThreadIDs  []int64        `json:"twitter_thread_ids,omitempty"`
 	Images            *EntryImages   `json:"images,omitempty"`
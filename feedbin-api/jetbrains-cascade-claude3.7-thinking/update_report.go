@@ -0,0 +1,429 @@
+package client
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UpdateReportOptions specifies the optional parameters to the
+// UpdatedEntriesService.Report method
+type UpdateReportOptions struct {
+	Since      time.Time // Only report entries updated after this time
+	KeepUnread bool      // Don't mark the reported entries as read
+
+	// AcknowledgeMissing also marks the MissingIDs as read, so entries of
+	// unsubscribed or deleted feeds stop coming back in every report
+	AcknowledgeMissing bool
+}
+
+// UpdateReport describes what changed in updated entries
+type UpdateReport struct {
+	// Changes are in the order the updated entry IDs were returned
+	Changes []*EntryChange
+
+	// MissingIDs are updated entry IDs the entries endpoint did not return,
+	// for example because the feed was unsubscribed. They are only marked as
+	// read with UpdateReportOptions.AcknowledgeMissing.
+	MissingIDs []int64
+
+	// MarkedRead are the IDs the API confirmed as marked read
+	MarkedRead []int64
+}
+
+// EntryChange describes how an entry differs from its original version
+type EntryChange struct {
+	Entry *Entry
+
+	Title  *FieldChange // nil when unchanged
+	Author *FieldChange // nil when unchanged
+
+	// ContentChanged reports whether the text of the content changed.
+	// Changes to markup alone are ignored.
+	ContentChanged bool
+
+	// HTMLDiff is the server's HTML diff of the content, with insertions and
+	// deletions marked by the diff-ins and diff-del classes
+	HTMLDiff string
+
+	// TextDiff is computed locally from the text of the content when the
+	// content changed and the server did not send a diff
+	TextDiff TextDiff
+}
+
+// FieldChange is the old and new value of a changed field
+type FieldChange struct {
+	Old string
+	New string
+}
+
+// Changed reports whether the title, author or content text changed
+func (c *EntryChange) Changed() bool {
+	return c.Title != nil || c.Author != nil || c.ContentChanged
+}
+
+// Report fetches the updated entries with their original versions and content diffs,
+// compares each with its original and then marks them as read unless opts.KeepUnread is set.
+// The missing entries are marked as well when opts.AcknowledgeMissing is set.
+// When marking fails the report is returned together with the error.
+func (s *UpdatedEntriesService) Report(opts *UpdateReportOptions) (*UpdateReport, error) {
+	if opts == nil {
+		opts = &UpdateReportOptions{}
+	}
+
+	entryIDs, err := s.List(&UpdatedEntryListOptions{Since: opts.Since})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UpdateReport{}
+	position := make(map[int64]int, len(entryIDs))
+	for i, id := range entryIDs {
+		position[id] = i
+	}
+
+	// The entries endpoint accepts at most 100 IDs per request
+	for start := 0; start < len(entryIDs); start += 100 {
+		end := start + 100
+		if end > len(entryIDs) {
+			end = len(entryIDs)
+		}
+
+		entries, _, err := s.client.Entries.List(&EntryListOptions{
+			Ids:                entryIDs[start:end],
+			PerPage:            end - start,
+			IncludeOriginal:    true,
+			IncludeContentDiff: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if _, ok := position[entry.ID]; ok {
+				report.Changes = append(report.Changes, CompareEntry(entry))
+			}
+		}
+	}
+
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		return position[report.Changes[i].Entry.ID] < position[report.Changes[j].Entry.ID]
+	})
+
+	found := make(map[int64]bool, len(report.Changes))
+	var reportedIDs []int64
+	for _, change := range report.Changes {
+		if !found[change.Entry.ID] {
+			found[change.Entry.ID] = true
+			reportedIDs = append(reportedIDs, change.Entry.ID)
+		}
+	}
+	for _, id := range entryIDs {
+		if !found[id] {
+			found[id] = true
+			report.MissingIDs = append(report.MissingIDs, id)
+		}
+	}
+
+	if opts.KeepUnread {
+		return report, nil
+	}
+	if opts.AcknowledgeMissing {
+		reportedIDs = append(reportedIDs, report.MissingIDs...)
+	}
+
+	for start := 0; start < len(reportedIDs); start += 1000 {
+		end := start + 1000
+		if end > len(reportedIDs) {
+			end = len(reportedIDs)
+		}
+
+		processedIDs, err := s.MarkAsRead(reportedIDs[start:end])
+		if err != nil {
+			return report, fmt.Errorf("marking updated entries as read: %w", err)
+		}
+		report.MarkedRead = append(report.MarkedRead, processedIDs...)
+	}
+
+	return report, nil
+}
+
+// CompareEntry compares an entry fetched with include_original and
+// include_content_diff with its original version. Without an original
+// version nothing is reported as changed.
+func CompareEntry(entry *Entry) *EntryChange {
+	change := &EntryChange{Entry: entry}
+	if entry.ContentDiff != nil {
+		change.HTMLDiff = *entry.ContentDiff
+	}
+
+	original := entry.Original
+	if original == nil {
+		return change
+	}
+
+	change.Title = compareField(original.Title, entry.Title)
+	change.Author = compareField(original.Author, entry.Author)
+
+	oldLines := htmlTextLines(original.Content)
+	newLines := htmlTextLines(stringValue(entry.Content))
+	change.ContentChanged = !equalLines(oldLines, newLines)
+	if change.ContentChanged && change.HTMLDiff == "" {
+		change.TextDiff = diffLines(oldLines, newLines)
+	}
+
+	return change
+}
+
+// compareField returns the change of a field, or nil if it is unchanged
+func compareField(old string, current *string) *FieldChange {
+	old = strings.TrimSpace(old)
+	value := strings.TrimSpace(stringValue(current))
+	if old == value {
+		return nil
+	}
+	return &FieldChange{Old: old, New: value}
+}
+
+// stringValue returns the value of s, or "" if s is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// DiffOp is the kind of a line in a TextDiff
+type DiffOp int
+
+const (
+	DiffEqual  DiffOp = iota // The line is in both versions
+	DiffDelete               // The line is only in the original
+	DiffInsert               // The line is only in the current version
+)
+
+// DiffLine is a line of a TextDiff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// TextDiff is a line by line diff of two texts, where lines are the
+// paragraphs, list items and other blocks of the content
+type TextDiff []DiffLine
+
+// diffContext is the number of unchanged lines TextDiff.String shows around changes
+const diffContext = 2
+
+// String formats the diff with "-" and "+" prefixes for deleted and
+// inserted lines, and up to two unchanged lines around each change
+func (d TextDiff) String() string {
+	show := make([]bool, len(d))
+	for i, line := range d {
+		if line.Op == DiffEqual {
+			continue
+		}
+		for j := i - diffContext; j <= i+diffContext; j++ {
+			if j >= 0 && j < len(d) {
+				show[j] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	for i, line := range d {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped && b.Len() > 0 {
+			b.WriteString("...\n")
+		}
+		skipped = false
+
+		switch line.Op {
+		case DiffDelete:
+			b.WriteString("- ")
+		case DiffInsert:
+			b.WriteString("+ ")
+		default:
+			b.WriteString("  ")
+		}
+		b.WriteString(line.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// maxDiffCells bounds the size of the table used to diff the changed middle
+// of two texts. Larger changes are shown as a deletion and an insertion.
+const maxDiffCells = 1 << 20
+
+// diffLines computes a minimal line diff of a and b
+func diffLines(a, b []string) TextDiff {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff TextDiff
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCells {
+		for _, line := range midA {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range midB {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+	} else {
+		diff = append(diff, lcsDiff(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// lcsDiff diffs a and b using their longest common subsequence
+func lcsDiff(a, b []string) TextDiff {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff TextDiff
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	return diff
+}
+
+// equalLines reports whether a and b contain the same lines
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// textBreakTags are the elements that start a new line of text
+var textBreakTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "footer": true, "header": true,
+	"hr": true, "li": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "td": true, "th": true, "tr": true,
+	"ul": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true,
+}
+
+// htmlTextLines returns the text of HTML content as lines, one per
+// paragraph or other block, with whitespace collapsed. Line breaks in the
+// source are whitespace like in a browser, except inside <pre>.
+func htmlTextLines(content string) []string {
+	var text strings.Builder
+	skip := ""
+	pre := false
+	writeText := func(s string) {
+		if skip != "" {
+			return
+		}
+		if !pre {
+			s = strings.ReplaceAll(s, "\n", " ")
+		}
+		text.WriteString(s)
+	}
+	for content != "" {
+		lt := strings.IndexByte(content, '<')
+		if lt < 0 {
+			writeText(content)
+			break
+		}
+		writeText(content[:lt])
+		content = content[lt:]
+
+		if strings.HasPrefix(content, "<!--") {
+			end := strings.Index(content, "-->")
+			if end < 0 {
+				break
+			}
+			content = content[end+3:]
+			continue
+		}
+
+		gt := strings.IndexByte(content, '>')
+		if gt < 0 {
+			break
+		}
+		tag := strings.ToLower(strings.Trim(content[1:gt], "/ \t\r\n"))
+		closing := strings.HasPrefix(content, "</")
+		content = content[gt+1:]
+
+		name := tag
+		if i := strings.IndexAny(tag, " \t\r\n/"); i >= 0 {
+			name = tag[:i]
+		}
+		switch {
+		case name == "script" || name == "style":
+			if closing && skip == name {
+				skip = ""
+			} else if !closing {
+				skip = name
+			}
+		case textBreakTags[name] && skip == "":
+			if name == "pre" {
+				pre = !closing
+			}
+			text.WriteString("\n")
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(html.UnescapeString(text.String()), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,314 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHTMLTextLines(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"", nil},
+		{"plain text", []string{"plain text"}},
+		{"<p>One</p><p>Two\n  and   a half</p>", []string{"One", "Two and a half"}},
+		{"first<br>second<br/>third", []string{"first", "second", "third"}},
+		{"<ul><li>a</li><li>b</li></ul>", []string{"a", "b"}},
+		{"<p>Fish &amp; chips &lt;3 &#8212; &quot;ok&quot;</p>", []string{`Fish & chips <3 — "ok"`}},
+		{"<p>Kept</p><script>var x = '<p>';</script><style>p{}</style><p>Also kept</p>", []string{"Kept", "Also kept"}},
+		{"<p>Before<!-- <p>hidden</p> -->after</p>", []string{"Beforeafter"}},
+		{`<p class="x">A <a href="/y">link</a> <em>inline</em></p>`, []string{"A link inline"}},
+		{"<h2>Title</h2>Body", []string{"Title", "Body"}},
+		{"<p>Kept\r\nwrapped</p><pre>line 1\n  line 2</pre>", []string{"Kept wrapped", "line 1", "line 2"}},
+	}
+
+	for _, tt := range tests {
+		if got := htmlTextLines(tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("htmlTextLines(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+// formatDiff writes a diff as one string, with =, - and + for the operations
+func formatDiff(diff TextDiff) string {
+	var parts []string
+	for _, line := range diff {
+		op := "="
+		switch line.Op {
+		case DiffDelete:
+			op = "-"
+		case DiffInsert:
+			op = "+"
+		}
+		parts = append(parts, op+line.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"a b c", "a b c", "=a =b =c"},
+		{"a b c", "a x c", "=a -b +x =c"},
+		{"a b c", "a c", "=a -b =c"},
+		{"a c", "a b c", "=a +b =c"},
+		{"", "a b", "+a +b"},
+		{"a b", "", "-a -b"},
+		{"a b c d", "b c d e", "-a =b =c =d +e"},
+		{"x a b y", "x b a y", "=x -a =b +a =y"},
+	}
+
+	for _, tt := range tests {
+		got := formatDiff(diffLines(strings.Fields(tt.a), strings.Fields(tt.b)))
+		if got != tt.want {
+			t.Errorf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLCSDiff(t *testing.T) {
+	// Without the prefix and suffix trimming of diffLines
+	got := formatDiff(lcsDiff(strings.Fields("a b c"), strings.Fields("b c d")))
+	if want := "-a =b =c +d"; got != want {
+		t.Errorf("lcsDiff = %q, want %q", got, want)
+	}
+
+	got = formatDiff(lcsDiff(strings.Fields("a b c a b"), strings.Fields("c b a")))
+	a, b := 0, 0
+	for _, part := range strings.Fields(got) {
+		switch part[0] {
+		case '=':
+			a++
+			b++
+		case '-':
+			a++
+		case '+':
+			b++
+		}
+	}
+	if a != 5 || b != 3 || strings.Count(got, "=") != 2 {
+		t.Errorf("lcsDiff = %q, want both texts with a common subsequence of 2", got)
+	}
+}
+
+func TestDiffLines_Large(t *testing.T) {
+	// Changes too large for the LCS table are a deletion and an insertion
+	var a, b []string
+	for i := 0; i < 1100; i++ {
+		a = append(a, "old "+strconv.Itoa(i))
+		b = append(b, "new "+strconv.Itoa(i))
+	}
+	a = append([]string{"same"}, a...)
+	b = append([]string{"same"}, b...)
+
+	diff := diffLines(a, b)
+	if len(diff) != 1+2*1100 || diff[0].Op != DiffEqual {
+		t.Fatalf("diffLines returned %d lines", len(diff))
+	}
+	for i, line := range diff[1:] {
+		want := DiffDelete
+		if i >= 1100 {
+			want = DiffInsert
+		}
+		if line.Op != want {
+			t.Fatalf("line %d has op %v, want %v", i+1, line.Op, want)
+		}
+	}
+}
+
+func TestTextDiff_String(t *testing.T) {
+	diff := diffLines(strings.Fields("1 2 3 4 5 6 7 8 9"), strings.Fields("1 2 3 4 5 6 7 8 nine"))
+	want := "  7\n  8\n- 9\n+ nine\n"
+	if got := diff.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	diff = diffLines(strings.Fields("a 2 3 4 5 6 z"), strings.Fields("A 2 3 4 5 6 Z"))
+	want = "- a\n+ A\n  2\n  3\n...\n  5\n  6\n- z\n+ Z\n"
+	if got := diff.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// updatedEntriesServer serves updated_entries.json and entries.json for
+// Report, leaving the IDs in missing out of the entries
+type updatedEntriesServer struct {
+	mu       sync.Mutex
+	ids      []int64
+	missing  map[int64]bool
+	batches  []int
+	marked   [][]int64
+	pageSize []string
+	invalid  []string // Entry queries without include_original or include_content_diff
+}
+
+func (s *updatedEntriesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/v2/updated_entries.json" && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(s.ids)
+
+	case r.URL.Path == "/v2/updated_entries.json" && r.Method == http.MethodDelete:
+		var body struct {
+			UpdatedEntries []int64 `json:"updated_entries"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.marked = append(s.marked, body.UpdatedEntries)
+		json.NewEncoder(w).Encode(body.UpdatedEntries)
+
+	case r.URL.Path == "/v2/entries.json":
+		query := r.URL.Query()
+		if query.Get("include_original") != "true" || query.Get("include_content_diff") != "true" {
+			s.invalid = append(s.invalid, r.URL.RawQuery)
+		}
+		s.pageSize = append(s.pageSize, query.Get("per_page"))
+
+		ids := strings.Split(query.Get("ids"), ",")
+		s.batches = append(s.batches, len(ids))
+
+		// The API does not keep the order of the IDs
+		entries := []*Entry{}
+		for i := len(ids) - 1; i >= 0; i-- {
+			id, _ := strconv.ParseInt(ids[i], 10, 64)
+			if s.missing[id] {
+				continue
+			}
+			title, content := "New title", "<p>New</p>"
+			entries = append(entries, &Entry{
+				ID:       id,
+				Title:    &title,
+				Content:  &content,
+				Original: &EntryOriginal{Title: "Old title", Content: "<p>Old</p>"},
+			})
+		}
+		json.NewEncoder(w).Encode(entries)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newReportTest(tt *testing.T, count int, missing ...int64) (*Client, *updatedEntriesServer) {
+	tt.Helper()
+
+	server := &updatedEntriesServer{missing: make(map[int64]bool)}
+	for id := int64(1); id <= int64(count); id++ {
+		server.ids = append(server.ids, id)
+	}
+	for _, id := range missing {
+		server.missing[id] = true
+	}
+
+	httpServer := httptest.NewServer(server)
+	tt.Cleanup(httpServer.Close)
+
+	c, err := NewClient("user", "pass", WithBaseURL(httpServer.URL+"/v2/"))
+	if err != nil {
+		tt.Fatal(err)
+	}
+	return c, server
+}
+
+func markedSizes(marked [][]int64) []int {
+	var sizes []int
+	for _, ids := range marked {
+		sizes = append(sizes, len(ids))
+	}
+	return sizes
+}
+
+func TestUpdatedEntriesService_Report(t *testing.T) {
+	c, server := newReportTest(t, 250, 7, 180)
+
+	report, err := c.UpdatedEntries.Report(&UpdateReportOptions{KeepUnread: true})
+	if err != nil {
+		t.Fatalf("Report returned error: %v", err)
+	}
+
+	if len(server.invalid) != 0 {
+		t.Errorf("entries requested without original and diff: %q", server.invalid)
+	}
+	sort.Ints(server.batches)
+	if want := []int{50, 100, 100}; !reflect.DeepEqual(server.batches, want) {
+		t.Errorf("entries requested in batches of %v, want %v", server.batches, want)
+	}
+	for _, perPage := range server.pageSize {
+		if perPage != "100" && perPage != "50" {
+			t.Errorf("per_page = %q, want the batch size", perPage)
+		}
+	}
+
+	if len(report.Changes) != 248 {
+		t.Fatalf("Report returned %d changes, want 248", len(report.Changes))
+	}
+	for i := 1; i < len(report.Changes); i++ {
+		if report.Changes[i-1].Entry.ID >= report.Changes[i].Entry.ID {
+			t.Fatalf("changes are not in the order of the updated IDs at %d", i)
+		}
+	}
+	change := report.Changes[0]
+	if change.Title == nil || change.Title.Old != "Old title" || !change.ContentChanged {
+		t.Errorf("first change = %+v", change)
+	}
+	if got := formatDiff(change.TextDiff); got != "-Old +New" {
+		t.Errorf("TextDiff = %q", got)
+	}
+
+	if want := []int64{7, 180}; !reflect.DeepEqual(report.MissingIDs, want) {
+		t.Errorf("MissingIDs = %v, want %v", report.MissingIDs, want)
+	}
+	if len(server.marked) != 0 || len(report.MarkedRead) != 0 {
+		t.Errorf("KeepUnread marked %v", server.marked)
+	}
+}
+
+func TestUpdatedEntriesService_ReportMarkRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		acknowledge bool
+		sizes       []int
+	}{
+		{"reported only", false, []int{1000, 1000, 48}},
+		{"acknowledge missing", true, []int{1000, 1000, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, server := newReportTest(t, 2050, 3, 2049)
+
+			report, err := c.UpdatedEntries.Report(&UpdateReportOptions{AcknowledgeMissing: tt.acknowledge})
+			if err != nil {
+				t.Fatalf("Report returned error: %v", err)
+			}
+
+			if got := markedSizes(server.marked); !reflect.DeepEqual(got, tt.sizes) {
+				t.Errorf("marked in batches of %v, want %v", got, tt.sizes)
+			}
+			if len(report.MarkedRead) != tt.sizes[0]+tt.sizes[1]+tt.sizes[2] {
+				t.Errorf("MarkedRead has %d IDs", len(report.MarkedRead))
+			}
+
+			last := server.marked[len(server.marked)-1]
+			acknowledged := last[len(last)-1] == 2049
+			if acknowledged != tt.acknowledge {
+				t.Errorf("missing IDs marked = %v, want %v", acknowledged, tt.acknowledge)
+			}
+			if len(report.MissingIDs) != 2 {
+				t.Errorf("MissingIDs = %v", report.MissingIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// UpdatedEntriesService handles communication with the updated entries related
+// methods of the Feedbin API
+type UpdatedEntriesService struct {
+	client *Client
+}
+
+// UpdatedEntryListOptions specifies the optional parameters to the
+// UpdatedEntriesService.List method
+type UpdatedEntryListOptions struct {
+	Since time.Time // Get entries updated after this time
+}
+
+// List returns the IDs of entries that were modified after they were published
+func (s *UpdatedEntriesService) List(opts *UpdatedEntryListOptions) ([]int64, error) {
+	u := "updated_entries.json"
+	u, err := addUpdatedEntryOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.newRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entryIDs []int64
+	_, err = s.client.do(req, &entryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return entryIDs, nil
+}
+
+// MarkAsRead removes the specified entry IDs from the updated entries
+func (s *UpdatedEntriesService) MarkAsRead(entryIDs []int64) ([]int64, error) {
+	return s.markAsRead("DELETE", "updated_entries.json", entryIDs)
+}
+
+// MarkAsReadWithPOST removes the specified entry IDs from the updated entries using POST instead of DELETE
+// Some clients like Android don't easily allow a body with a DELETE request
+func (s *UpdatedEntriesService) MarkAsReadWithPOST(entryIDs []int64) ([]int64, error) {
+	return s.markAsRead("POST", "updated_entries/delete.json", entryIDs)
+}
+
+// markAsRead sends the request for MarkAsRead and MarkAsReadWithPOST
+func (s *UpdatedEntriesService) markAsRead(method, path string, entryIDs []int64) ([]int64, error) {
+	if len(entryIDs) > 1000 {
+		return nil, fmt.Errorf("maximum of 1000 entry IDs can be marked as read in a single request")
+	}
+
+	type updatedRequest struct {
+		UpdatedEntries []int64 `json:"updated_entries"`
+	}
+
+	req, err := s.client.newRequest(method, path, &updatedRequest{
+		UpdatedEntries: entryIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var processedIDs []int64
+	_, err = s.client.do(req, &processedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return processedIDs, nil
+}
+
+// addUpdatedEntryOptions adds the parameters in opts as URL query parameters to s.
+// opts is a pointer to an UpdatedEntryListOptions struct.
+func addUpdatedEntryOptions(s string, opts *UpdatedEntryListOptions) (string, error) {
+	if opts == nil {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return s, err
+	}
+
+	q := u.Query()
+	if !opts.Since.IsZero() {
+		q.Set("since", opts.Since.Format(time.RFC3339Nano))
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
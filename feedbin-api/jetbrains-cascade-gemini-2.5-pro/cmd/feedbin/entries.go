@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	feedbin "github.com/cascade/feedbin-go"
+)
+
+// maxEntryIDs is the number of entry IDs the API accepts per request.
+const maxEntryIDs = 1000
+
+// entryColumns are the table columns for entries.
+var entryColumns = []column[*feedbin.Entry]{
+	{"ID", func(e *feedbin.Entry) string { return strconv.FormatInt(e.ID, 10) }},
+	{"FEED_ID", func(e *feedbin.Entry) string { return strconv.FormatInt(e.FeedID, 10) }},
+	{"PUBLISHED", func(e *feedbin.Entry) string { return formatTime(e.Published) }},
+	{"TITLE", func(e *feedbin.Entry) string { return deref(e.Title) }},
+	{"URL", func(e *feedbin.Entry) string { return deref(e.URL) }},
+}
+
+// entriesList prints entries, optionally filtered.
+func entriesList(a *app, args []string) error {
+	fs := a.flagSet("entries ls")
+	unread := fs.Bool("unread", false, "only unread entries")
+	starred := fs.Bool("starred", false, "only starred entries")
+	feedID := fs.Int64("feed", 0, "only entries of this feed ID")
+	since := fs.String("since", "", "only entries created after this time (RFC 3339, YYYY-MM-DD or a duration such as 24h)")
+	page := fs.Int("page", 0, "page number")
+	perPage := fs.Int("per-page", 0, "entries per page")
+
+	args, err := a.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usagef("entries ls takes no arguments")
+	}
+
+	var sinceTime *time.Time
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		sinceTime = &t
+	}
+
+	var (
+		read, star *bool
+		pageOpt    *int
+		perPageOpt *int
+	)
+	if *unread {
+		read = feedbin.Bool(false)
+	}
+	if *starred {
+		star = feedbin.Bool(true)
+	}
+	if *page > 0 {
+		pageOpt = page
+	}
+	if *perPage > 0 {
+		perPageOpt = perPage
+	}
+
+	var entries []*feedbin.Entry
+	if *feedID != 0 {
+		entries, _, err = a.client.Entries.ListFeedEntries(*feedID, &feedbin.ListFeedEntriesOptions{
+			Page: pageOpt, Since: sinceTime, Read: read, Starred: star, PerPage: perPageOpt,
+		})
+	} else {
+		entries, _, err = a.client.Entries.List(&feedbin.ListEntriesOptions{
+			Page: pageOpt, Since: sinceTime, Read: read, Starred: star, PerPage: perPageOpt,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return emit(a, entries, entryColumns)
+}
+
+// parseSince parses an RFC 3339 time, a date, or a duration before now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, usagef("invalid --since %q: use RFC 3339, YYYY-MM-DD or a duration such as 24h", s)
+}
+
+// markRead marks entries as read.
+func markRead(a *app, args []string) error {
+	return a.changeEntries("read", args, func(ids []int64) (*http.Response, error) {
+		return a.client.UnreadEntries.MarkAsRead(ids)
+	})
+}
+
+// markUnread marks entries as unread.
+func markUnread(a *app, args []string) error {
+	return a.changeEntries("unread", args, func(ids []int64) (*http.Response, error) {
+		_, resp, err := a.client.UnreadEntries.MarkAsUnread(ids)
+		return resp, err
+	})
+}
+
+// star stars entries.
+func star(a *app, args []string) error {
+	return a.changeEntries("star", args, func(ids []int64) (*http.Response, error) {
+		_, resp, err := a.client.StarredEntries.MarkAsStarred(ids)
+		return resp, err
+	})
+}
+
+// unstar unstars entries.
+func unstar(a *app, args []string) error {
+	return a.changeEntries("unstar", args, func(ids []int64) (*http.Response, error) {
+		return a.client.StarredEntries.MarkAsUnstarred(ids)
+	})
+}
+
+// changeEntries applies change to the entry IDs in args, or read from stdin
+// when there are none, in requests of at most maxEntryIDs IDs.
+func (a *app) changeEntries(name string, args []string, change func([]int64) (*http.Response, error)) error {
+	args, err := a.parseFlags(a.flagSet(name), args)
+	if err != nil {
+		return err
+	}
+
+	var ids []int64
+	if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+		ids, err = readIDs(a.stdin)
+	} else {
+		ids, err = parseIDs(args)
+	}
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return usagef("%s: no entry IDs given", name)
+	}
+
+	for start := 0; start < len(ids); start += maxEntryIDs {
+		end := start + maxEntryIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if _, err := change(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseIDs parses IDs given as arguments, separated by spaces or commas.
+func parseIDs(args []string) ([]int64, error) {
+	var ids []int64
+	for _, arg := range args {
+		for _, field := range strings.FieldsFunc(arg, func(r rune) bool { return r == ',' }) {
+			id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil || id <= 0 {
+				return nil, usagef("invalid ID %q", field)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// readIDs reads entry IDs from r: whitespace or comma separated numbers, or
+// one JSON object with an "id" field per line, as printed by -o ndjson.
+func readIDs(r io.Reader) ([]int64, error) {
+	var ids []int64
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "{") {
+			var obj struct {
+				ID int64 `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(text), &obj); err != nil || obj.ID <= 0 {
+				return nil, fmt.Errorf("stdin line %d: expected an object with an id", line)
+			}
+			ids = append(ids, obj.ID)
+			continue
+		}
+
+		parsed, err := parseIDs(strings.Fields(text))
+		if err != nil {
+			return nil, fmt.Errorf("stdin line %d: %w", line, err)
+		}
+		ids = append(ids, parsed...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	feedbin "github.com/cascade/feedbin-go"
+)
+
+// importColumns are the table columns for an import without its items.
+var importColumns = []column[*feedbin.Import]{
+	{"ID", func(i *feedbin.Import) string { return strconv.FormatInt(i.ID, 10) }},
+	{"COMPLETE", func(i *feedbin.Import) string { return strconv.FormatBool(i.Complete) }},
+	{"ITEMS", func(i *feedbin.Import) string { return strconv.Itoa(len(i.ImportItems)) }},
+}
+
+// importItemColumns are the table columns for import items.
+var importItemColumns = []column[feedbin.ImportItem]{
+	{"STATUS", func(i feedbin.ImportItem) string { return i.Status }},
+	{"TITLE", func(i feedbin.ImportItem) string { return deref(i.Title) }},
+	{"FEED_URL", func(i feedbin.ImportItem) string { return deref(i.FeedURL) }},
+}
+
+// importOPML uploads an OPML file and optionally waits for the import to finish.
+func importOPML(a *app, args []string) error {
+	fs := a.flagSet("import")
+	wait := fs.Bool("wait", false, "wait for the import to complete and print the status of each feed")
+	timeout := fs.Duration("timeout", 10*time.Minute, "how long to wait for the import")
+	interval := fs.Duration("interval", 2*time.Second, "how often to check the import while waiting")
+
+	args, err := a.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usagef("usage: feedbin import <file.opml> [--wait]")
+	}
+	if *interval <= 0 {
+		return usagef("--interval must be positive")
+	}
+
+	var data []byte
+	if args[0] == "-" {
+		data, err = io.ReadAll(a.stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	imp, _, err := a.client.Imports.Create(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		return emit(a, []*feedbin.Import{imp}, importColumns)
+	}
+
+	deadline := time.Now().Add(*timeout)
+	for !imp.Complete {
+		if time.Now().Add(*interval).After(deadline) {
+			return &exitCodeError{code: exitTimeout, msg: fmt.Sprintf("import %d did not complete within %s", imp.ID, *timeout)}
+		}
+		time.Sleep(*interval)
+
+		imp, _, err = a.client.Imports.Get(imp.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := emit(a, imp.ImportItems, importItemColumns); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, item := range imp.ImportItems {
+		if item.Status == "failed" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &exitCodeError{code: exitImportFailed, msg: fmt.Sprintf("import %d: %d of %d feeds failed", imp.ID, failed, len(imp.ImportItems))}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Command feedbin is a command-line client for the Feedbin API.
+//
+// Usage:
+//
+//	feedbin [-o table|json|ndjson] <command> [arguments]
+//
+// Credentials are read from FEEDBIN_USERNAME and FEEDBIN_PASSWORD, or from the
+// netrc entry for api.feedbin.com ($NETRC or ~/.netrc). FEEDBIN_API_URL
+// overrides the API base URL.
+//
+// Exit codes:
+//
+//	0  success
+//	1  other errors (network, files, ...)
+//	2  invalid usage
+//	3  API error (any other non-2xx status)
+//	4  authentication failed (401 or 403)
+//	5  not found (404)
+//	6  multiple feeds found for a URL (300)
+//	7  import finished with failed feeds
+//	8  timed out waiting for an import
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	feedbin "github.com/cascade/feedbin-go"
+)
+
+// defaultHost is the API host looked up in netrc.
+const defaultHost = "api.feedbin.com"
+
+// Exit codes, see the package documentation.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitAPIError
+	exitUnauthorized
+	exitNotFound
+	exitMultipleChoices
+	exitImportFailed
+	exitTimeout
+)
+
+const usage = `Usage: feedbin [-o table|json|ndjson] <command> [arguments]
+
+Commands:
+  subs list                            List subscriptions
+  subs add <url>                       Subscribe to a feed
+  subs rm <id>...                      Unsubscribe
+  subs rename <id> <title>             Rename a subscription
+  tags ls                              List tags and their feeds
+  tags rename <old> <new>              Rename a tag
+  tags rm <name>                       Delete a tag
+  entries ls [--unread] [--starred] [--feed id] [--since time] [--page n] [--per-page n]
+                                       List entries
+  read|unread|star|unstar [<id>...]    Change entries, reading IDs from stdin if none are given
+  import <file.opml> [--wait] [--timeout d] [--interval d]
+                                       Import an OPML file ("-" reads stdin)
+  search ls                            List saved searches
+  search run <id> [--page n]           List the entries of a saved search
+
+Every command accepts -o to choose the output format.
+`
+
+// app holds what commands need to run.
+type app struct {
+	client *feedbin.Client
+	format string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// usageError is returned for invalid arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usagef returns a usageError with a formatted message.
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCodeError carries an explicit exit code, e.g. for failed imports.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string { return e.msg }
+
+// commands maps "group subcommand" or "command" to its handler.
+var commands = map[string]func(a *app, args []string) error{
+	"subs list":    subsList,
+	"subs ls":      subsList,
+	"subs add":     subsAdd,
+	"subs rm":      subsRemove,
+	"subs rename":  subsRename,
+	"tags ls":      tagsList,
+	"tags list":    tagsList,
+	"tags rename":  tagsRename,
+	"tags rm":      tagsRemove,
+	"entries ls":   entriesList,
+	"entries list": entriesList,
+	"read":         markRead,
+	"unread":       markUnread,
+	"star":         star,
+	"unstar":       unstar,
+	"import":       importOPML,
+	"search ls":    searchList,
+	"search list":  searchList,
+	"search run":   searchRun,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line in args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	fs := a.flagSet("feedbin")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	handler, ok := commands[rest[0]]
+	rest = rest[1:]
+	if !ok && len(rest) > 0 {
+		handler, ok = commands[fs.Arg(0)+" "+rest[0]]
+		rest = rest[1:]
+	}
+	if !ok {
+		fmt.Fprintf(stderr, "feedbin: unknown command %q\n\n%s", fs.Arg(0), usage)
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(stderr, "feedbin: %v\n", err)
+		return exitError
+	}
+	a.client = client
+
+	return a.exitCode(handler(a, rest))
+}
+
+// newClient returns an API client using the configured base URL and credentials.
+func newClient() (*feedbin.Client, error) {
+	var baseURL *url.URL
+	host := defaultHost
+	if raw := os.Getenv("FEEDBIN_API_URL"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid FEEDBIN_API_URL %q", raw)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		baseURL = u
+		host = u.Hostname()
+	}
+
+	username, password, err := loadCredentials(host)
+	if err != nil {
+		return nil, err
+	}
+
+	client := feedbin.NewClient(username, password, &http.Client{Timeout: 30 * time.Second})
+	if baseURL != nil {
+		client.BaseURL = baseURL
+	}
+	return client, nil
+}
+
+// flagSet returns a flag set that also accepts the output format flag, so it
+// can be given before or after the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() { fmt.Fprint(a.stderr, usage) }
+	if a.format == "" {
+		a.format = formatTable
+	}
+	fs.StringVar(&a.format, "o", a.format, "output format: table, json or ndjson")
+	return fs
+}
+
+// parseFlags parses the flags of a command and returns its arguments. Flags
+// may follow arguments, as in "import feeds.opml --wait"; "--" ends the flags.
+func (a *app) parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, &usageError{msg: err.Error()}
+		}
+		rest := fs.Args()
+		// Parse consumes "--"; everything after it is an argument.
+		if len(rest) == 0 || len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	switch a.format {
+	case formatTable, formatJSON, formatNDJSON:
+	default:
+		return nil, usagef("unknown output format %q", a.format)
+	}
+	return positional, nil
+}
+
+// exitCode reports err on stderr and maps it to an exit code.
+func (a *app) exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var (
+		usageErr   *usageError
+		codeErr    *exitCodeError
+		choicesErr *feedbin.MultipleChoicesError
+		apiErr     *feedbin.APIError
+	)
+	switch {
+	case errors.As(err, &usageErr):
+		if usageErr.msg != flag.ErrHelp.Error() {
+			fmt.Fprintf(a.stderr, "feedbin: %v\n", err)
+		}
+		return exitUsage
+	case errors.As(err, &codeErr):
+		fmt.Fprintf(a.stderr, "feedbin: %v\n", err)
+		return codeErr.code
+	case errors.As(err, &choicesErr):
+		fmt.Fprintf(a.stderr, "feedbin: multiple feeds found, subscribe to one of them:\n")
+		for _, choice := range choicesErr.Choices {
+			fmt.Fprintf(a.stderr, "  %s\t%s\n", choice.FeedURL, choice.Title)
+		}
+		return exitMultipleChoices
+	case errors.As(err, &apiErr):
+		fmt.Fprintf(a.stderr, "feedbin: %v\n", err)
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return exitUnauthorized
+		case http.StatusNotFound:
+			return exitNotFound
+		}
+		return exitAPIError
+	default:
+		fmt.Fprintf(a.stderr, "feedbin: %v\n", err)
+		return exitError
+	}
+}
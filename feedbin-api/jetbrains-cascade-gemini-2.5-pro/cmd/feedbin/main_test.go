@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestAPI serves the subscription endpoints used by the tests and points
+// the command at it.
+func newTestAPI(t *testing.T) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/subscriptions.json", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "me" || pass != "secret" {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":1,"feed_id":10,"title":"Example","feed_url":"https://example.com/feed"}]`))
+		case http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMultipleChoices)
+			w.Write([]byte(`[{"feed_url":"https://example.com/atom","title":"Atom"},{"feed_url":"https://example.com/rss","title":"RSS"}]`))
+		}
+	})
+	mux.HandleFunc("/v2/subscriptions/", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("FEEDBIN_API_URL", srv.URL+"/v2")
+	t.Setenv("FEEDBIN_USERNAME", "me")
+	t.Setenv("FEEDBIN_PASSWORD", "secret")
+}
+
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		pass   string
+		code   int
+		stderr string
+	}{
+		{"success", []string{"subs", "list"}, "secret", exitOK, ""},
+		{"unauthorized", []string{"subs", "list"}, "wrong", exitUnauthorized, "401"},
+		{"not found", []string{"subs", "rm", "5"}, "secret", exitNotFound, "404"},
+		{"multiple choices", []string{"subs", "add", "https://example.com"}, "secret", exitMultipleChoices, "https://example.com/rss\tRSS"},
+		{"unknown command", []string{"subs", "frobnicate"}, "secret", exitUsage, "unknown command"},
+		{"no command", nil, "secret", exitUsage, "Usage:"},
+		{"bad arguments", []string{"subs", "rm", "x"}, "secret", exitUsage, ""},
+		{"bad format", []string{"-o", "xml", "subs", "list"}, "secret", exitUsage, "unknown output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestAPI(t)
+			t.Setenv("FEEDBIN_PASSWORD", tt.pass)
+
+			code, _, stderr := runCommand(tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d (stderr: %s)", code, tt.code, stderr)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestRun_FormatFlagPosition(t *testing.T) {
+	for _, args := range [][]string{
+		{"-o", "json", "subs", "list"},
+		{"subs", "list", "-o", "json"},
+	} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			newTestAPI(t)
+
+			code, stdout, stderr := runCommand(args...)
+			if code != exitOK {
+				t.Fatalf("exit code = %d, stderr: %s", code, stderr)
+			}
+
+			var subs []map[string]interface{}
+			if err := json.Unmarshal([]byte(stdout), &subs); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+			}
+			if len(subs) != 1 || subs[0]["title"] != "Example" {
+				t.Errorf("unexpected output: %s", stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadCredentials returns the username and password from FEEDBIN_USERNAME
+// and FEEDBIN_PASSWORD, falling back to the netrc entry for host.
+func loadCredentials(host string) (string, string, error) {
+	username := os.Getenv("FEEDBIN_USERNAME")
+	password := os.Getenv("FEEDBIN_PASSWORD")
+	if username != "" && password != "" {
+		return username, password, nil
+	}
+
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", errors.New("no credentials: set FEEDBIN_USERNAME and FEEDBIN_PASSWORD")
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+
+	login, secret, err := readNetrc(path, host)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", errors.New("no credentials: set FEEDBIN_USERNAME and FEEDBIN_PASSWORD or add a netrc entry for " + host)
+		}
+		return "", "", err
+	}
+	if login == "" || secret == "" {
+		return "", "", fmt.Errorf("no credentials: %s has no login and password for %s", path, host)
+	}
+
+	// The environment overrides netrc field by field.
+	if username != "" {
+		login = username
+	}
+	if password != "" {
+		secret = password
+	}
+	return login, secret, nil
+}
+
+// readNetrc returns the login and password of the machine entry for host in
+// the netrc file at path, or of the default entry if there is none.
+func readNetrc(path, host string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			// A macro definition ends at the first empty line.
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if field == "macdef" {
+				fields = fields[:i]
+				inMacro = true
+				break
+			}
+		}
+		tokens = append(tokens, fields...)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	type entry struct{ login, password string }
+	var (
+		machine, fallback *entry
+		current           *entry
+	)
+	for i := 0; i < len(tokens); i++ {
+		next := func() string {
+			if i+1 < len(tokens) {
+				i++
+				return tokens[i]
+			}
+			return ""
+		}
+
+		switch tokens[i] {
+		case "machine":
+			current = &entry{}
+			if next() == host && machine == nil {
+				machine = current
+			}
+		case "default":
+			current = &entry{}
+			if fallback == nil {
+				fallback = current
+			}
+		case "login":
+			if value := next(); current != nil {
+				current.login = value
+			}
+		case "password":
+			if value := next(); current != nil {
+				current.password = value
+			}
+		case "account":
+			next()
+		}
+	}
+
+	switch {
+	case machine != nil:
+		return machine.login, machine.password, nil
+	case fallback != nil:
+		return fallback.login, fallback.password, nil
+	}
+	return "", "", nil
+}
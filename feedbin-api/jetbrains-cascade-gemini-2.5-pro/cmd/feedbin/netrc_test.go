@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeNetrc(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadNetrc(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		login    string
+		password string
+	}{
+		{
+			name:     "machine",
+			content:  "machine example.com login other password x\nmachine api.feedbin.com login me password secret\n",
+			login:    "me",
+			password: "secret",
+		},
+		{
+			name:     "single line",
+			content:  "machine api.feedbin.com login me account work password secret",
+			login:    "me",
+			password: "secret",
+		},
+		{
+			name:     "first machine wins",
+			content:  "machine api.feedbin.com login first password one\nmachine api.feedbin.com login second password two\n",
+			login:    "first",
+			password: "one",
+		},
+		{
+			name:     "default",
+			content:  "machine example.com login other password x\ndefault login anon password guest\n",
+			login:    "anon",
+			password: "guest",
+		},
+		{
+			name:     "machine before default",
+			content:  "default login anon password guest\nmachine api.feedbin.com login me password secret\n",
+			login:    "me",
+			password: "secret",
+		},
+		{
+			name: "comments",
+			content: "# machine api.feedbin.com login commented password out\n" +
+				"machine api.feedbin.com\n  login me\n  password secret\n",
+			login:    "me",
+			password: "secret",
+		},
+		{
+			name: "macdef",
+			content: "machine example.com login other password x macdef init\n" +
+				"machine api.feedbin.com login evil password evil\n" +
+				"\n" +
+				"machine api.feedbin.com login me password secret\n",
+			login:    "me",
+			password: "secret",
+		},
+		{
+			name:    "no entry",
+			content: "machine example.com login other password x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, password, err := readNetrc(writeNetrc(t, tt.content), "api.feedbin.com")
+			if err != nil {
+				t.Fatalf("readNetrc: %v", err)
+			}
+			if login != tt.login || password != tt.password {
+				t.Errorf("got %q/%q, want %q/%q", login, password, tt.login, tt.password)
+			}
+		})
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	t.Setenv("NETRC", writeNetrc(t, "machine api.feedbin.com login me password secret\n"))
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     [2]string
+	}{
+		{"netrc", "", "", [2]string{"me", "secret"}},
+		{"environment", "env", "envpass", [2]string{"env", "envpass"}},
+		{"username override", "env", "", [2]string{"env", "secret"}},
+		{"password override", "", "envpass", [2]string{"me", "envpass"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FEEDBIN_USERNAME", tt.username)
+			t.Setenv("FEEDBIN_PASSWORD", tt.password)
+
+			login, password, err := loadCredentials("api.feedbin.com")
+			if err != nil {
+				t.Fatalf("loadCredentials: %v", err)
+			}
+			if got := [2]string{login, password}; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCredentials_Missing(t *testing.T) {
+	t.Setenv("FEEDBIN_USERNAME", "")
+	t.Setenv("FEEDBIN_PASSWORD", "")
+
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	if _, _, err := loadCredentials("api.feedbin.com"); err == nil {
+		t.Error("expected an error for a missing netrc file")
+	}
+
+	t.Setenv("NETRC", writeNetrc(t, "machine api.feedbin.com login me\n"))
+	if _, _, err := loadCredentials("api.feedbin.com"); err == nil {
+		t.Error("expected an error for an entry without a password")
+	}
+}
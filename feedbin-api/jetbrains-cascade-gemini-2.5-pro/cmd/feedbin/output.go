@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats accepted by -o.
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// column is a table column: its header and how to render a row's cell.
+type column[T any] struct {
+	header string
+	value  func(T) string
+}
+
+// emit writes items in the selected output format. JSON output is the API
+// objects as returned by the client; tables show the given columns.
+func emit[T any](a *app, items []T, columns []column[T]) error {
+	switch a.format {
+	case formatJSON:
+		if items == nil {
+			items = []T{}
+		}
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case formatNDJSON:
+		enc := json.NewEncoder(a.stdout)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+	if _, err := w.Write([]byte(strings.Join(headers, "\t") + "\n")); err != nil {
+		return err
+	}
+
+	cells := make([]string, len(columns))
+	for _, item := range items {
+		for i, col := range columns {
+			cells[i] = cleanCell(col.value(item))
+		}
+		if _, err := w.Write([]byte(strings.Join(cells, "\t") + "\n")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// cleanCell keeps table cells on one line.
+func cleanCell(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// deref returns the value of an optional string field.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatTime renders an optional time for tables.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
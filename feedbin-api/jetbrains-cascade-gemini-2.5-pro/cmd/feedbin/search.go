@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strconv"
+
+	feedbin "github.com/cascade/feedbin-go"
+)
+
+// savedSearchColumns are the table columns for saved searches.
+var savedSearchColumns = []column[*feedbin.SavedSearch]{
+	{"ID", func(s *feedbin.SavedSearch) string { return strconv.FormatInt(s.ID, 10) }},
+	{"NAME", func(s *feedbin.SavedSearch) string { return s.Name }},
+	{"QUERY", func(s *feedbin.SavedSearch) string { return s.Query }},
+}
+
+// searchList prints the saved searches.
+func searchList(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("search ls"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usagef("search ls takes no arguments")
+	}
+
+	searches, _, err := a.client.SavedSearches.List()
+	if err != nil {
+		return err
+	}
+	return emit(a, searches, savedSearchColumns)
+}
+
+// searchRun prints the entries matching a saved search.
+func searchRun(a *app, args []string) error {
+	fs := a.flagSet("search run")
+	page := fs.Int("page", 0, "page number")
+
+	args, err := a.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usagef("usage: feedbin search run <id> [--page n]")
+	}
+
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	opts := &feedbin.GetSavedSearchEntriesOptions{IncludeEntries: feedbin.Bool(true)}
+	if *page > 0 {
+		opts.Page = page
+	}
+
+	result, _, err := a.client.SavedSearches.GetEntries(ids[0], opts)
+	if err != nil {
+		return err
+	}
+	return emit(a, result.Entries, entryColumns)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	feedbin "github.com/cascade/feedbin-go"
+)
+
+// subscriptionColumns are the table columns for subscriptions.
+var subscriptionColumns = []column[*feedbin.Subscription]{
+	{"ID", func(s *feedbin.Subscription) string { return strconv.FormatInt(s.ID, 10) }},
+	{"FEED_ID", func(s *feedbin.Subscription) string { return strconv.FormatInt(s.FeedID, 10) }},
+	{"TITLE", func(s *feedbin.Subscription) string { return s.Title }},
+	{"FEED_URL", func(s *feedbin.Subscription) string { return s.FeedURL }},
+}
+
+// subsList prints all subscriptions.
+func subsList(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("subs list"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usagef("subs list takes no arguments")
+	}
+
+	subs, _, err := a.client.Subscriptions.List(nil)
+	if err != nil {
+		return err
+	}
+	return emit(a, subs, subscriptionColumns)
+}
+
+// subsAdd subscribes to a feed and prints the new subscription.
+func subsAdd(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("subs add"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usagef("usage: feedbin subs add <url>")
+	}
+
+	sub, _, err := a.client.Subscriptions.Create(args[0])
+	if err != nil {
+		return err
+	}
+	return emit(a, []*feedbin.Subscription{sub}, subscriptionColumns)
+}
+
+// subsRemove unsubscribes from each subscription ID.
+func subsRemove(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("subs rm"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return usagef("usage: feedbin subs rm <id>...")
+	}
+
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := a.client.Subscriptions.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subsRename sets the title of a subscription and prints it.
+func subsRename(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("subs rename"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return usagef("usage: feedbin subs rename <id> <title>")
+	}
+
+	ids, err := parseIDs(args[:1])
+	if err != nil {
+		return err
+	}
+
+	sub, _, err := a.client.Subscriptions.Update(ids[0], strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+	return emit(a, []*feedbin.Subscription{sub}, subscriptionColumns)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tagSummary is a tag with the feeds it is applied to.
+type tagSummary struct {
+	Name    string  `json:"name"`
+	FeedIDs []int64 `json:"feed_ids"`
+}
+
+// tagColumns are the table columns for tags.
+var tagColumns = []column[tagSummary]{
+	{"NAME", func(t tagSummary) string { return t.Name }},
+	{"FEEDS", func(t tagSummary) string { return strconv.Itoa(len(t.FeedIDs)) }},
+	{"FEED_IDS", func(t tagSummary) string {
+		ids := make([]string, len(t.FeedIDs))
+		for i, id := range t.FeedIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		return strings.Join(ids, ",")
+	}},
+}
+
+// tagsList prints the tags built from the user's taggings.
+func tagsList(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("tags ls"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return usagef("tags ls takes no arguments")
+	}
+
+	taggings, _, err := a.client.Taggings.List()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*tagSummary)
+	var tags []tagSummary
+	for _, tagging := range taggings {
+		tag, ok := byName[tagging.Name]
+		if !ok {
+			tag = &tagSummary{Name: tagging.Name}
+			byName[tagging.Name] = tag
+		}
+		tag.FeedIDs = append(tag.FeedIDs, tagging.FeedID)
+	}
+	for _, tag := range byName {
+		sort.Slice(tag.FeedIDs, func(i, j int) bool { return tag.FeedIDs[i] < tag.FeedIDs[j] })
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name) })
+
+	return emit(a, tags, tagColumns)
+}
+
+// tagsRename renames a tag on every feed.
+func tagsRename(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("tags rename"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return usagef("usage: feedbin tags rename <old> <new>")
+	}
+
+	_, err = a.client.Tags.Rename(args[0], args[1])
+	return err
+}
+
+// tagsRemove deletes a tag from every feed.
+func tagsRemove(a *app, args []string) error {
+	args, err := a.parseFlags(a.flagSet("tags rm"), args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return usagef("usage: feedbin tags rm <name>")
+	}
+
+	_, err = a.client.Tags.Delete(args[0])
+	return err
+}
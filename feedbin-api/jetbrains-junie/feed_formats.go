@@ -0,0 +1,397 @@
+// Package feedbin provides a Go client for the Feedbin API v2.
+package feedbin
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feedGenerator is the generator name written into rendered feeds.
+const feedGenerator = "Feedbin Go client"
+
+// FeedFormat is the format a generated feed is rendered in.
+type FeedFormat string
+
+const (
+	// FeedFormatAtom renders Atom 1.0.
+	FeedFormatAtom FeedFormat = "atom"
+
+	// FeedFormatRSS renders RSS 2.0.
+	FeedFormatRSS FeedFormat = "rss"
+
+	// FeedFormatJSON renders JSON Feed 1.1.
+	FeedFormatJSON FeedFormat = "json"
+)
+
+// ContentType returns the media type of the format.
+func (f FeedFormat) ContentType() string {
+	switch f {
+	case FeedFormatAtom:
+		return "application/atom+xml; charset=utf-8"
+	case FeedFormatRSS:
+		return "application/rss+xml; charset=utf-8"
+	case FeedFormatJSON:
+		return "application/feed+json; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// Render renders the feed in the given format.
+func (f *GeneratedFeed) Render(format FeedFormat) ([]byte, error) {
+	switch format {
+	case FeedFormatAtom:
+		return f.Atom()
+	case FeedFormatRSS:
+		return f.RSS()
+	case FeedFormatJSON:
+		return f.JSON()
+	}
+	return nil, fmt.Errorf("unknown feed format %q", format)
+}
+
+// atomFeed is an Atom 1.0 feed document.
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+// atomLink is an Atom link element.
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+// atomPerson is an Atom author.
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+// atomText is an Atom text construct.
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// atomEntry is an Atom entry.
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Links     []atomLink `xml:"link"`
+	Author    atomPerson `xml:"author"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Content   *atomText  `xml:"content,omitempty"`
+}
+
+// Atom renders the feed as Atom 1.0.
+func (f *GeneratedFeed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:        f.ID,
+		Title:     f.Title,
+		Subtitle:  f.Description,
+		Updated:   f.updated().Format(time.RFC3339),
+		Generator: feedGenerator,
+	}
+	if f.FeedURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "self", Href: f.FeedURL, Type: FeedFormatAtom.ContentType()})
+	}
+	if f.HomePageURL != "" {
+		doc.Links = append(doc.Links, atomLink{Rel: "alternate", Href: f.HomePageURL})
+	}
+
+	for _, entry := range f.Entries {
+		published := entryTime(entry)
+		item := atomEntry{
+			ID:        entryID(entry),
+			Title:     stringValue(entry.Title),
+			Updated:   published.Format(time.RFC3339),
+			Published: published.Format(time.RFC3339),
+			Author:    atomPerson{Name: f.author(entry)},
+		}
+		if entry.URL != "" {
+			item.Links = append(item.Links, atomLink{Rel: "alternate", Href: entry.URL})
+		}
+		if enc := entry.Enclosure; enc != nil && enc.EnclosureURL != "" {
+			item.Links = append(item.Links, atomLink{
+				Rel:    "enclosure",
+				Href:   enc.EnclosureURL,
+				Type:   enc.EnclosureType,
+				Length: enclosureLength(enc),
+			})
+		}
+		if summary := stringValue(entry.Summary); summary != "" {
+			item.Summary = &atomText{Type: "html", Body: summary}
+		}
+		if content := stringValue(entry.Content); content != "" {
+			item.Content = &atomText{Type: "html", Body: content}
+		}
+		doc.Entries = append(doc.Entries, item)
+	}
+
+	return marshalFeedXML(doc)
+}
+
+// rssDocument is an RSS 2.0 document.
+type rssDocument struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	XMLNSAtom    string     `xml:"xmlns:atom,attr"`
+	XMLNSContent string     `xml:"xmlns:content,attr"`
+	XMLNSDC      string     `xml:"xmlns:dc,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+// rssChannel is the channel of an RSS document.
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is an RSS item.
+type rssItem struct {
+	Title          string        `xml:"title,omitempty"`
+	Link           string        `xml:"link,omitempty"`
+	GUID           rssGUID       `xml:"guid"`
+	PubDate        string        `xml:"pubDate"`
+	Creator        string        `xml:"dc:creator,omitempty"`
+	Description    string        `xml:"description,omitempty"`
+	ContentEncoded string        `xml:"content:encoded,omitempty"`
+	Enclosure      *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+// rssGUID is the guid of an RSS item.
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// rssEnclosure is the enclosure of an RSS item.
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RSS renders the feed as RSS 2.0. Content goes into content:encoded and
+// the summary, or the content if there is none, into description.
+func (f *GeneratedFeed) RSS() ([]byte, error) {
+	link := f.HomePageURL
+	if link == "" {
+		link = f.FeedURL
+	}
+	if link == "" {
+		link = "https://feedbin.com/"
+	}
+
+	doc := rssDocument{
+		Version:      "2.0",
+		XMLNSAtom:    "http://www.w3.org/2005/Atom",
+		XMLNSContent: "http://purl.org/rss/1.0/modules/content/",
+		XMLNSDC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          link,
+			Description:   f.Description,
+			LastBuildDate: f.updated().Format(time.RFC1123Z),
+			Generator:     feedGenerator,
+		},
+	}
+	if f.FeedURL != "" {
+		doc.Channel.AtomLink = &atomLink{Rel: "self", Href: f.FeedURL, Type: FeedFormatRSS.ContentType()}
+	}
+
+	for _, entry := range f.Entries {
+		content := stringValue(entry.Content)
+		description := stringValue(entry.Summary)
+		if description == "" {
+			description = content
+		}
+
+		item := rssItem{
+			Title:          stringValue(entry.Title),
+			Link:           entry.URL,
+			GUID:           rssGUID{IsPermaLink: "false", Value: entryID(entry)},
+			PubDate:        entryTime(entry).Format(time.RFC1123Z),
+			Creator:        f.author(entry),
+			Description:    description,
+			ContentEncoded: content,
+		}
+		if enc := entry.Enclosure; enc != nil && enc.EnclosureURL != "" {
+			length := enclosureLength(enc)
+			if length == "" {
+				length = "0"
+			}
+			item.Enclosure = &rssEnclosure{URL: enc.EnclosureURL, Length: length, Type: enc.EnclosureType}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return marshalFeedXML(doc)
+}
+
+// jsonFeedDocument is a JSON Feed 1.1 document.
+type jsonFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+// jsonFeedItem is a JSON Feed item.
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html"`
+	Summary       string               `json:"summary,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+// jsonFeedAuthor is a JSON Feed author.
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// jsonFeedAttachment is a JSON Feed attachment.
+type jsonFeedAttachment struct {
+	URL               string `json:"url"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes,omitempty"`
+	DurationInSeconds int64  `json:"duration_in_seconds,omitempty"`
+}
+
+// JSON renders the feed as JSON Feed 1.1.
+func (f *GeneratedFeed) JSON() ([]byte, error) {
+	doc := jsonFeedDocument{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomePageURL,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, entry := range f.Entries {
+		content := stringValue(entry.Content)
+		summary := stringValue(entry.Summary)
+		if content == "" {
+			content = summary
+		}
+
+		item := jsonFeedItem{
+			ID:            entryID(entry),
+			URL:           entry.URL,
+			Title:         stringValue(entry.Title),
+			ContentHTML:   content,
+			Summary:       summary,
+			DatePublished: entryTime(entry).Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: f.author(entry)}},
+		}
+		if enc := entry.Enclosure; enc != nil && enc.EnclosureURL != "" {
+			size, _ := strconv.ParseInt(enclosureLength(enc), 10, 64)
+			item.Attachments = []jsonFeedAttachment{{
+				URL:               enc.EnclosureURL,
+				MimeType:          enc.EnclosureType,
+				SizeInBytes:       size,
+				DurationInSeconds: parseDuration(enc.ItunesDuration),
+			}}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// marshalFeedXML encodes an XML feed document with a declaration.
+func marshalFeedXML(doc interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// updated returns the feed's update time, or now for an empty feed.
+func (f *GeneratedFeed) updated() time.Time {
+	if f.Updated.IsZero() {
+		return time.Now().UTC()
+	}
+	return f.Updated.UTC()
+}
+
+// author returns the entry's author, falling back to the feed title.
+func (f *GeneratedFeed) author(entry Entry) string {
+	if author := strings.TrimSpace(stringValue(entry.Author)); author != "" {
+		return author
+	}
+	if title, ok := f.FeedTitles[entry.FeedID]; ok && title != "" {
+		return title
+	}
+	return "Unknown"
+}
+
+// entryID returns a stable, globally unique ID for an entry.
+func entryID(entry Entry) string {
+	return fmt.Sprintf("tag:feedbin.com,2013:entries/%d", entry.ID)
+}
+
+// entryTime returns when an entry was published, or created if unknown.
+func entryTime(entry Entry) time.Time {
+	if entry.Published.IsZero() {
+		return entry.CreatedAt.UTC()
+	}
+	return entry.Published.UTC()
+}
+
+// enclosureLength returns the enclosure size in bytes, or "" if unknown.
+func enclosureLength(enc *EntryEnclosure) string {
+	n, err := strconv.ParseInt(strings.TrimSpace(enc.EnclosureLength), 10, 64)
+	if err != nil || n <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// parseDuration parses an iTunes duration given as seconds, MM:SS or
+// HH:MM:SS. It returns 0 if the duration cannot be parsed.
+func parseDuration(s string) int64 {
+	var seconds int64
+	for _, part := range strings.Split(strings.TrimSpace(s), ":") {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+// stringValue returns the string s points to, or "" if s is nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
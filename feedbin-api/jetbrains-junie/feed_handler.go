@@ -0,0 +1,226 @@
+// Package feedbin provides a Go client for the Feedbin API v2.
+package feedbin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFeedCacheTTL is how long FeedHandler reuses a generated feed.
+const DefaultFeedCacheTTL = 5 * time.Minute
+
+// feedExtensions maps URL extensions to feed formats.
+var feedExtensions = map[string]FeedFormat{
+	".atom": FeedFormatAtom,
+	".rss":  FeedFormatRSS,
+	".json": FeedFormatJSON,
+}
+
+// FeedHandler serves generated feeds over HTTP at these paths, where the
+// extension selects the format (.atom, .rss or .json):
+//
+//	/tags/{name}.atom
+//	/starred.atom
+//	/saved_searches/{id}.atom
+//
+// Responses carry an ETag and Last-Modified, and conditional GET and HEAD
+// requests are answered with 304 Not Modified when the feed is unchanged.
+type FeedHandler struct {
+	generator *FeedGenerator
+
+	// CacheTTL is how long a generated feed is served before the API is
+	// queried again. Zero means DefaultFeedCacheTTL; a negative value
+	// disables caching.
+	CacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*renderedFeed
+}
+
+// renderedFeed is a feed rendered for one URL.
+type renderedFeed struct {
+	body        []byte
+	contentType string
+	etag        string
+	modified    time.Time
+	generated   time.Time
+}
+
+// NewFeedHandler creates a handler serving feeds from the generator.
+func NewFeedHandler(generator *FeedGenerator) *FeedHandler {
+	return &FeedHandler{
+		generator: generator,
+		cache:     make(map[string]*renderedFeed),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	source, format, ok := parseFeedPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	feed, err := h.render(r, source, format)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	header := w.Header()
+	header.Set("ETag", feed.etag)
+	header.Set("Last-Modified", feed.modified.UTC().Format(http.TimeFormat))
+	if ttl := h.ttl(); ttl > 0 {
+		header.Set("Cache-Control", fmt.Sprintf("max-age=%d", int(ttl.Seconds())))
+	}
+
+	if notModified(r, feed.etag, feed.modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", feed.contentType)
+	header.Set("Content-Length", strconv.Itoa(len(feed.body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(feed.body)
+	}
+}
+
+// render returns the cached feed for the request URL, generating it if it
+// is missing or older than the cache TTL.
+func (h *FeedHandler) render(r *http.Request, source FeedSource, format FeedFormat) (*renderedFeed, error) {
+	feedURL := requestURL(r)
+
+	h.mu.Lock()
+	cached, ok := h.cache[feedURL]
+	h.mu.Unlock()
+	if ok && time.Since(cached.generated) < h.ttl() {
+		return cached, nil
+	}
+
+	feed, err := h.generator.Generate(source)
+	if err != nil {
+		return nil, err
+	}
+	feed.FeedURL = feedURL
+
+	body, err := feed.Render(format)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	rendered := &renderedFeed{
+		body:        body,
+		contentType: format.ContentType(),
+		etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		modified:    feed.Updated,
+		generated:   time.Now(),
+	}
+	if rendered.modified.IsZero() {
+		rendered.modified = rendered.generated
+	}
+	if ok {
+		if cached.etag == rendered.etag {
+			// Keep the older time when the content did not change, so
+			// If-Modified-Since keeps matching
+			rendered.modified = cached.modified
+		} else if rendered.generated.After(rendered.modified) {
+			// The content changed without a newer entry, e.g. an older
+			// entry was unstarred, so the entry times are not enough
+			rendered.modified = rendered.generated
+		}
+	}
+
+	// The feed is kept even when caching is disabled, so the next
+	// response can be compared with it
+	h.mu.Lock()
+	h.cache[feedURL] = rendered
+	h.mu.Unlock()
+	return rendered, nil
+}
+
+// ttl returns the effective cache TTL.
+func (h *FeedHandler) ttl() time.Duration {
+	if h.CacheTTL == 0 {
+		return DefaultFeedCacheTTL
+	}
+	return h.CacheTTL
+}
+
+// parseFeedPath maps a request path to a feed source and format.
+func parseFeedPath(p string) (FeedSource, FeedFormat, bool) {
+	ext := path.Ext(p)
+	format, ok := feedExtensions[ext]
+	if !ok {
+		return FeedSource{}, "", false
+	}
+	name := strings.TrimPrefix(strings.TrimSuffix(p, ext), "/")
+
+	switch {
+	case name == "starred":
+		return StarredSource(), format, true
+	case strings.HasPrefix(name, "tags/") && len(name) > len("tags/"):
+		return TagSource(strings.TrimPrefix(name, "tags/")), format, true
+	case strings.HasPrefix(name, "saved_searches/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(name, "saved_searches/"))
+		if err != nil || id <= 0 {
+			return FeedSource{}, "", false
+		}
+		return SavedSearchSource(id), format, true
+	}
+	return FeedSource{}, "", false
+}
+
+// requestURL returns the absolute URL a request was made for.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.EscapedPath()
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is
+// no If-None-Match, as described in RFC 9110.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !modified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package feedbin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// starredAPI serves the subscriptions and starred entries a FeedHandler
+// needs for /starred feeds, and fails to list taggings. The starred entries
+// can be changed between requests.
+type starredAPI struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (a *starredAPI) setEntries(entries ...Entry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = entries
+}
+
+func (a *starredAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/subscriptions.json":
+		w.Write([]byte(`[]`))
+	case "/entries.json":
+		json.NewEncoder(w).Encode(a.entries)
+	case "/taggings.json":
+		http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestFeedHandler(t *testing.T, api http.Handler) *FeedHandler {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	client := NewClient("user", "pass")
+	client.BaseURL = server.URL
+	return NewFeedHandler(NewFeedGenerator(client))
+}
+
+func serveFeed(h *FeedHandler, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://feeds.example.com"+path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func testEntry(id int, published time.Time) Entry {
+	title := "Entry"
+	return Entry{ID: id, FeedID: 1, Title: &title, URL: fmt.Sprintf("https://example.com/%d", id), Published: published}
+}
+
+func TestFeedHandler_Conditional(t *testing.T) {
+	newest := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := &starredAPI{}
+	api.setEntries(testEntry(2, newest), testEntry(1, newest.Add(-time.Hour)))
+
+	h := newTestFeedHandler(t, api)
+	h.CacheTTL = -1
+
+	first := serveFeed(h, http.MethodGet, "/starred.atom", nil)
+	if first.Code != http.StatusOK {
+		t.Fatalf("GET returned %d: %s", first.Code, first.Body)
+	}
+	etag := first.Header().Get("ETag")
+	modified := first.Header().Get("Last-Modified")
+	if modified != newest.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want the newest entry time %q", modified, newest.Format(http.TimeFormat))
+	}
+
+	// Unchanged content is not modified by either validator
+	for _, header := range []map[string]string{
+		{"If-None-Match": etag},
+		{"If-Modified-Since": modified},
+	} {
+		rec := serveFeed(h, http.MethodGet, "/starred.atom", header)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("GET with %v returned %d, want 304", header, rec.Code)
+		}
+	}
+
+	// Unstarring the older entry changes the feed without a newer entry
+	api.setEntries(testEntry(2, newest))
+
+	for _, header := range []map[string]string{
+		{"If-None-Match": etag},
+		{"If-Modified-Since": modified},
+	} {
+		rec := serveFeed(h, http.MethodGet, "/starred.atom", header)
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET with %v after a change returned %d, want 200", header, rec.Code)
+		}
+	}
+
+	changed := serveFeed(h, http.MethodGet, "/starred.atom", nil)
+	if changed.Header().Get("ETag") == etag {
+		t.Error("ETag did not change with the content")
+	}
+	lastModified, err := http.ParseTime(changed.Header().Get("Last-Modified"))
+	if err != nil || !lastModified.After(newest) {
+		t.Errorf("Last-Modified = %q, want it after %v", changed.Header().Get("Last-Modified"), newest)
+	}
+
+	// The new time is kept while the content stays the same
+	rec := serveFeed(h, http.MethodGet, "/starred.atom", map[string]string{
+		"If-Modified-Since": changed.Header().Get("Last-Modified"),
+	})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("GET with the new Last-Modified returned %d, want 304", rec.Code)
+	}
+}
+
+func TestFeedHandler_Errors(t *testing.T) {
+	h := newTestFeedHandler(t, &starredAPI{})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/starred.xml", http.StatusNotFound},
+		{http.MethodGet, "/saved_searches/x.json", http.StatusNotFound},
+		{http.MethodGet, "/tags/.rss", http.StatusNotFound},
+		{http.MethodGet, "/saved_searches/7.json", http.StatusNotFound},
+		{http.MethodGet, "/tags/Go.json", http.StatusBadGateway},
+		{http.MethodPost, "/starred.atom", http.StatusMethodNotAllowed},
+		{http.MethodHead, "/starred.rss", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		rec := serveFeed(h, tt.method, tt.path, nil)
+		if rec.Code != tt.code {
+			t.Errorf("%s %s returned %d, want %d", tt.method, tt.path, rec.Code, tt.code)
+		}
+		if tt.method == http.MethodHead && rec.Body.Len() != 0 {
+			t.Errorf("HEAD %s returned a body", tt.path)
+		}
+	}
+}
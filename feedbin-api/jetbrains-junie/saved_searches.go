@@ -45,6 +45,29 @@ func (s *SavedSearchService) GetSavedSearch(id int) (*SavedSearch, error) {
 	return &search, nil
 }
 
+// GetSavedSearchEntryIDsByPage retrieves one page of the IDs of the entries
+// matching a saved search, in the order returned by the server.
+func (s *SavedSearchService) GetSavedSearchEntryIDsByPage(id, page int) ([]int, *PaginationLinks, error) {
+	path := fmt.Sprintf("/saved_searches/%d.json", id)
+	path = AddPageParams(path, &PageOptions{Page: page})
+
+	req, err := s.client.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entryIDs []int
+	resp, err := s.client.Do(req, &entryIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Parse pagination links
+	links := ParseLinkHeader(resp)
+
+	return entryIDs, links, nil
+}
+
 // CreateSavedSearch creates a new saved search.
 func (s *SavedSearchService) CreateSavedSearch(name, query string) (*SavedSearch, error) {
 	body := map[string]interface{}{
@@ -0,0 +1,534 @@
+package feedbin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIconCacheSize is the default size limit of an IconCache in bytes.
+	DefaultIconCacheSize = 16 << 20
+
+	// DefaultIconMaxAge is how long icons stay fresh when the server does not say.
+	DefaultIconMaxAge = 7 * 24 * time.Hour
+
+	// maxIconSize is the largest icon that is downloaded.
+	maxIconSize = 1 << 20
+
+	// iconListFile is the name of the file SaveIconList writes.
+	iconListFile = "icons.json"
+)
+
+var (
+	// ErrIconNotCached is returned by IconCache.Get for icons that are not in the cache.
+	ErrIconNotCached = errors.New("icon is not cached")
+
+	// ErrNotImage is returned by IconCache.Fetch when a download is not an image.
+	ErrNotImage = errors.New("icon is not an image")
+)
+
+// CachedIcon is an icon image stored in an IconCache.
+type CachedIcon struct {
+	URL         string
+	ContentType string
+	Data        []byte
+
+	// FetchedAt is when the icon was last downloaded or revalidated.
+	FetchedAt time.Time
+
+	// Expires is when the icon needs to be revalidated.
+	Expires time.Time
+
+	// Stale is set when the icon has expired. Fetch returns stale icons
+	// when they cannot be revalidated, for example while offline.
+	Stale bool
+}
+
+// IconCache stores icon images on disk, bounded in size. When the cache is
+// full, the least recently used icons are removed. Icons are kept until
+// they expire according to the Cache-Control and Expires headers they were
+// served with, or MaxAge when there are none, and then revalidated with
+// conditional requests.
+//
+// An IconCache is safe for concurrent use. Use one directory per cache.
+type IconCache struct {
+	// HTTPClient is the HTTP client used to download icons.
+	HTTPClient *http.Client
+
+	// MaxAge is how long icons stay fresh when the server does not say.
+	MaxAge time.Duration
+
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*iconCacheEntry
+	size    int64
+}
+
+// iconMetadata is stored next to each cached icon.
+type iconMetadata struct {
+	URL          string    `json:"url"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Expires      time.Time `json:"expires"`
+	Size         int64     `json:"size"`
+}
+
+// iconCacheEntry is the in-memory record of a cached icon.
+type iconCacheEntry struct {
+	meta     iconMetadata
+	lastUsed time.Time
+}
+
+// NewIconCache opens the icon cache in dir, creating the directory if
+// needed. maxBytes limits the total size of the cached images; if it is
+// zero or negative, DefaultIconCacheSize is used.
+func NewIconCache(dir string, maxBytes int64) (*IconCache, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultIconCacheSize
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create icon cache: %w", err)
+	}
+
+	cache := &IconCache{
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		MaxAge:     DefaultIconMaxAge,
+		dir:        dir,
+		maxBytes:   maxBytes,
+		entries:    make(map[string]*iconCacheEntry),
+	}
+
+	dataFiles, err := filepath.Glob(filepath.Join(dir, "*.icon"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read icon cache: %w", err)
+	}
+
+	for _, dataFile := range dataFiles {
+		key := strings.TrimSuffix(filepath.Base(dataFile), ".icon")
+
+		info, err := os.Stat(dataFile)
+		if err != nil {
+			continue
+		}
+
+		var meta iconMetadata
+		data, err := os.ReadFile(cache.path(key, ".json"))
+		if err != nil || json.Unmarshal(data, &meta) != nil || meta.Size != info.Size() || iconCacheKey(meta.URL) != key {
+			// Remove files left behind by an interrupted write
+			cache.remove(key)
+			continue
+		}
+
+		cache.entries[key] = &iconCacheEntry{meta: meta, lastUsed: info.ModTime()}
+		cache.size += meta.Size
+	}
+
+	cache.mu.Lock()
+	cache.evict("")
+	cache.mu.Unlock()
+
+	return cache, nil
+}
+
+// Size returns the total size of the cached images in bytes.
+func (c *IconCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Get returns a cached icon without making any requests, whether or not it
+// has expired. It returns ErrIconNotCached if the icon is not in the cache.
+func (c *IconCache) Get(iconURL string) (*CachedIcon, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := iconCacheKey(iconURL)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, ErrIconNotCached
+	}
+
+	return c.load(key, entry)
+}
+
+// Fetch returns an icon from the cache, downloading it if it is not cached
+// and revalidating it if it has expired. If an expired icon cannot be
+// revalidated because of a network or server error, the cached icon is
+// returned with Stale set.
+//
+// Downloads are identified by their content rather than the Content-Type
+// header, and ErrNotImage is returned for anything that is not an image.
+func (c *IconCache) Fetch(iconURL string) (*CachedIcon, error) {
+	key := iconCacheKey(iconURL)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Now().Before(entry.meta.Expires) {
+		icon, err := c.load(key, entry)
+		c.mu.Unlock()
+		return icon, err
+	}
+	var previous iconMetadata
+	if ok {
+		previous = entry.meta
+	}
+	c.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, iconURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if previous.ETag != "" {
+		req.Header.Set("If-None-Match", previous.ETag)
+	}
+	if previous.LastModified != "" {
+		req.Header.Set("If-Modified-Since", previous.LastModified)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return c.stale(key, fmt.Errorf("icon request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	now := time.Now()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		meta := previous
+		meta.FetchedAt = now
+		meta.Expires = c.expires(resp.Header, now)
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			meta.ETag = etag
+		}
+		return c.update(key, meta)
+
+	case resp.StatusCode == http.StatusOK:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxIconSize+1))
+		if err != nil {
+			return c.stale(key, fmt.Errorf("failed to read icon: %w", err))
+		}
+		if len(data) > maxIconSize {
+			return nil, fmt.Errorf("icon is larger than %d bytes", maxIconSize)
+		}
+
+		contentType, ok := sniffImage(data)
+		if !ok {
+			return nil, ErrNotImage
+		}
+
+		meta := iconMetadata{
+			URL:          iconURL,
+			ContentType:  contentType,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    now,
+			Expires:      c.expires(resp.Header, now),
+			Size:         int64(len(data)),
+		}
+		return c.store(key, meta, data)
+
+	default:
+		err := fmt.Errorf("icon error: %s (status code: %d)", resp.Status, resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return c.stale(key, err)
+		}
+		return nil, err
+	}
+}
+
+// SaveIconList stores the icon list in the cache directory, so that
+// subscriptions can be matched with their icons while offline.
+func (c *IconCache) SaveIconList(icons []Icon) error {
+	data, err := json.Marshal(icons)
+	if err != nil {
+		return fmt.Errorf("failed to encode icon list: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(c.dir, iconListFile), data); err != nil {
+		return fmt.Errorf("failed to save icon list: %w", err)
+	}
+	return nil
+}
+
+// LoadIconList returns the icon list stored by SaveIconList, or nil if
+// none has been stored.
+func (c *IconCache) LoadIconList() ([]Icon, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, iconListFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load icon list: %w", err)
+	}
+
+	var icons []Icon
+	if err := json.Unmarshal(data, &icons); err != nil {
+		return nil, fmt.Errorf("failed to decode icon list: %w", err)
+	}
+	return icons, nil
+}
+
+// SubscriptionIcons returns the cached icons of the given subscriptions by
+// feed ID, using the stored icon list and without making any requests.
+// Subscriptions whose icon is not cached are not included.
+func (c *IconCache) SubscriptionIcons(subscriptions []Subscription) (map[int]*CachedIcon, error) {
+	icons, err := c.LoadIconList()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]*CachedIcon)
+	for feedID, icon := range NewIconIndex(icons).ForSubscriptions(subscriptions) {
+		cached, err := c.Get(icon.URL)
+		if errors.Is(err, ErrIconNotCached) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[feedID] = cached
+	}
+	return result, nil
+}
+
+// stale returns the cached icon marked as stale, or err if there is none.
+func (c *IconCache) stale(key string, err error) (*CachedIcon, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, err
+	}
+	return c.load(key, entry)
+}
+
+// load reads a cached icon and marks it as recently used. c.mu must be held.
+func (c *IconCache) load(key string, entry *iconCacheEntry) (*CachedIcon, error) {
+	data, err := os.ReadFile(c.path(key, ".icon"))
+	if err != nil {
+		c.drop(key)
+		return nil, ErrIconNotCached
+	}
+
+	c.touch(key, entry)
+
+	return &CachedIcon{
+		URL:         entry.meta.URL,
+		ContentType: entry.meta.ContentType,
+		Data:        data,
+		FetchedAt:   entry.meta.FetchedAt,
+		Expires:     entry.meta.Expires,
+		Stale:       !time.Now().Before(entry.meta.Expires),
+	}, nil
+}
+
+// update replaces the metadata of a cached icon after a revalidation.
+func (c *IconCache) update(key string, meta iconMetadata) (*CachedIcon, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, ErrIconNotCached
+	}
+
+	if err := c.writeMetadata(key, meta); err != nil {
+		return nil, err
+	}
+	entry.meta = meta
+
+	return c.load(key, entry)
+}
+
+// store adds or replaces a cached icon and evicts icons to stay within the size limit.
+func (c *IconCache) store(key string, meta iconMetadata, data []byte) (*CachedIcon, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drop(key)
+
+	if err := writeFileAtomic(c.path(key, ".icon"), data); err != nil {
+		return nil, fmt.Errorf("failed to cache icon: %w", err)
+	}
+	if err := c.writeMetadata(key, meta); err != nil {
+		c.remove(key)
+		return nil, err
+	}
+
+	now := time.Now()
+	c.entries[key] = &iconCacheEntry{meta: meta, lastUsed: now}
+	c.size += meta.Size
+	c.evict(key)
+
+	return &CachedIcon{
+		URL:         meta.URL,
+		ContentType: meta.ContentType,
+		Data:        data,
+		FetchedAt:   meta.FetchedAt,
+		Expires:     meta.Expires,
+		Stale:       !now.Before(meta.Expires),
+	}, nil
+}
+
+// writeMetadata writes the metadata file of a cached icon.
+func (c *IconCache) writeMetadata(key string, meta iconMetadata) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode icon metadata: %w", err)
+	}
+	if err := writeFileAtomic(c.path(key, ".json"), data); err != nil {
+		return fmt.Errorf("failed to cache icon: %w", err)
+	}
+	return nil
+}
+
+// evict removes the least recently used icons, except keep, until the
+// cache is within its size limit. c.mu must be held.
+func (c *IconCache) evict(keep string) {
+	if c.size <= c.maxBytes {
+		return
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed.Before(c.entries[keys[j]].lastUsed)
+	})
+
+	for _, key := range keys {
+		if c.size <= c.maxBytes {
+			return
+		}
+		c.drop(key)
+	}
+}
+
+// drop removes an icon from the cache. c.mu must be held.
+func (c *IconCache) drop(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.meta.Size
+		delete(c.entries, key)
+	}
+	c.remove(key)
+}
+
+// remove deletes the files of a cached icon.
+func (c *IconCache) remove(key string) {
+	os.Remove(c.path(key, ".icon"))
+	os.Remove(c.path(key, ".json"))
+}
+
+// touch records that an icon was used. The modification time of the image
+// file keeps the order across restarts. c.mu must be held.
+func (c *IconCache) touch(key string, entry *iconCacheEntry) {
+	now := time.Now()
+	entry.lastUsed = now
+	os.Chtimes(c.path(key, ".icon"), now, now)
+}
+
+// path returns the path of a cache file.
+func (c *IconCache) path(key, ext string) string {
+	return filepath.Join(c.dir, key+ext)
+}
+
+// expires returns when a response received at now expires, based on its
+// Cache-Control and Expires headers or else c.MaxAge.
+func (c *IconCache) expires(header http.Header, now time.Time) time.Time {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return now
+		case "max-age":
+			if seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil {
+				return now.Add(time.Duration(seconds) * time.Second)
+			}
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		if t, err := http.ParseTime(expires); err == nil {
+			return t
+		}
+		// Invalid dates such as "0" mean already expired
+		return now
+	}
+
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultIconMaxAge
+	}
+	return now.Add(maxAge)
+}
+
+// iconCacheKey returns the file name of a cached icon without extension.
+func iconCacheKey(iconURL string) string {
+	sum := sha256.Sum256([]byte(iconURL))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sniffImage returns the content type of image data, or false if the data
+// is not an image.
+func sniffImage(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	if strings.HasPrefix(contentType, "image/") {
+		return contentType, true
+	}
+
+	// SVG is detected as XML or text
+	if strings.HasPrefix(contentType, "text/xml") || strings.HasPrefix(contentType, "text/plain") {
+		head := data
+		if len(head) > 512 {
+			head = head[:512]
+		}
+		if strings.Contains(strings.ToLower(string(head)), "<svg") {
+			return "image/svg+xml", true
+		}
+	}
+
+	return "", false
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to name,
+// so that readers never see a partially written file.
+func writeFileAtomic(name string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), name)
+	}
+	return err
+}
@@ -1,23 +1,183 @@
 package feedbin
 
 import (
-	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
-// GetIcon retrieves the icon for a feed.
-func (c *Client) GetIcon(feedID int) (*Icon, error) {
+// GetIcons retrieves the icons of all feeds the user is subscribed to.
+// Sites for which Feedbin could not find an icon are not included.
+func (c *Client) GetIcons() ([]Icon, error) {
 	// Make the request
-	resp, err := c.doRequest(http.MethodGet, fmt.Sprintf("/feeds/%d/icon.json", feedID), nil, nil)
+	resp, err := c.doRequest(http.MethodGet, "/icons.json", nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse the response
-	var icon Icon
-	if err := parseResponse(resp, &icon); err != nil {
+	var icons []Icon
+	if err := parseResponse(resp, &icons); err != nil {
 		return nil, err
 	}
 
-	return &icon, nil
+	return icons, nil
+}
+
+// IconIndex maps hosts to their icons.
+type IconIndex struct {
+	byHost map[string]Icon
+}
+
+// NewIconIndex creates an index of the given icons.
+func NewIconIndex(icons []Icon) *IconIndex {
+	index := &IconIndex{byHost: make(map[string]Icon, len(icons))}
+	for _, icon := range icons {
+		if host := normalizeHost(icon.Host); host != "" && icon.URL != "" {
+			index.byHost[host] = icon
+		}
+	}
+	return index
+}
+
+// Lookup returns the icon for a host. If there is no icon for the host
+// itself, the host with "www." added or removed is tried, followed by its
+// parent domains, so that "blog.example.com" can use the icon of
+// "example.com" or "www.example.com".
+func (idx *IconIndex) Lookup(host string) (Icon, bool) {
+	host = strings.TrimPrefix(normalizeHost(host), "www.")
+	if host == "" {
+		return Icon{}, false
+	}
+
+	for {
+		if icon, ok := idx.byHost[host]; ok {
+			return icon, true
+		}
+		if icon, ok := idx.byHost["www."+host]; ok {
+			return icon, true
+		}
+
+		// Stop before reaching the top-level domain
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 || strings.IndexByte(host[dot+1:], '.') < 0 {
+			return Icon{}, false
+		}
+		host = host[dot+1:]
+	}
+}
+
+// ForSubscription returns the icon for a subscription, looked up by the
+// host of its site URL or, if that has no icon, of its feed URL.
+func (idx *IconIndex) ForSubscription(subscription Subscription) (Icon, bool) {
+	for _, rawURL := range []string{subscription.SiteURL, subscription.FeedURL} {
+		if icon, ok := idx.Lookup(hostOf(rawURL)); ok {
+			return icon, true
+		}
+	}
+	return Icon{}, false
+}
+
+// ForSubscriptions returns the icons of the given subscriptions by feed ID.
+// Subscriptions without an icon are not included.
+func (idx *IconIndex) ForSubscriptions(subscriptions []Subscription) map[int]Icon {
+	icons := make(map[int]Icon, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if icon, ok := idx.ForSubscription(subscription); ok {
+			icons[subscription.FeedID] = icon
+		}
+	}
+	return icons
+}
+
+// IconSyncResult holds the outcome of SyncIcons.
+type IconSyncResult struct {
+	// Icons are the cached icons by feed ID
+	Icons map[int]*CachedIcon
+
+	// Missing are the feed IDs of subscriptions without an icon
+	Missing []int
+
+	// Errors are the errors of icons that could not be downloaded, by icon URL
+	Errors map[string]error
+}
+
+// SyncIcons loads the icon list and the subscriptions, saves the icon list
+// in the cache and downloads the icon of every subscription into it. Icons
+// that are still fresh are not downloaded again. Download errors are
+// reported in the result rather than stopping the sync.
+func (c *Client) SyncIcons(cache *IconCache) (*IconSyncResult, error) {
+	icons, err := c.GetIcons()
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, _, err := c.GetSubscriptions(nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.SaveIconList(icons); err != nil {
+		return nil, err
+	}
+
+	result := &IconSyncResult{
+		Icons:  make(map[int]*CachedIcon),
+		Errors: make(map[string]error),
+	}
+
+	index := NewIconIndex(icons)
+	fetched := make(map[string]*CachedIcon)
+	for _, subscription := range subscriptions {
+		icon, ok := index.ForSubscription(subscription)
+		if !ok {
+			result.Missing = append(result.Missing, subscription.FeedID)
+			continue
+		}
+
+		cached, ok := fetched[icon.URL]
+		if !ok {
+			if _, failed := result.Errors[icon.URL]; failed {
+				continue
+			}
+			cached, err = cache.Fetch(icon.URL)
+			if err != nil {
+				result.Errors[icon.URL] = err
+				continue
+			}
+			fetched[icon.URL] = cached
+		}
+		result.Icons[subscription.FeedID] = cached
+	}
+
+	return result, nil
+}
+
+// hostOf returns the host of a URL, which may lack a scheme.
+func hostOf(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + strings.TrimPrefix(rawURL, "//")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// normalizeHost lowercases a host and removes its port and trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if strings.Contains(host, "/") {
+		host = hostOf(host)
+	}
+	if h, _, ok := strings.Cut(host, ":"); ok && !strings.Contains(host, "]") {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
 }
@@ -0,0 +1,228 @@
+package feedbin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pngData is the start of a PNG image, enough for content sniffing.
+var pngData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x10\x00\x00\x00\x10")
+
+func TestIconIndexLookup(t *testing.T) {
+	index := NewIconIndex([]Icon{
+		{Host: "example.com", URL: "https://icons.test/example.png"},
+		{Host: "www.github.blog", URL: "https://icons.test/github.png"},
+		{Host: "M.SignalVNoise.com", URL: "https://icons.test/svn.png"},
+	})
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"example.com", "https://icons.test/example.png"},
+		{"www.example.com", "https://icons.test/example.png"},
+		{"blog.example.com", "https://icons.test/example.png"},
+		{"a.b.example.com:8080", "https://icons.test/example.png"},
+		{"github.blog", "https://icons.test/github.png"},
+		{"news.github.blog", "https://icons.test/github.png"},
+		{"m.signalvnoise.com", "https://icons.test/svn.png"},
+		{"signalvnoise.com", ""},
+		{"com", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		icon, ok := index.Lookup(tt.host)
+		if ok != (tt.want != "") || icon.URL != tt.want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", tt.host, icon.URL, ok, tt.want)
+		}
+	}
+
+	subscription := Subscription{FeedID: 7, SiteURL: "", FeedURL: "https://feeds.example.com/rss"}
+	if icon, ok := index.ForSubscription(subscription); !ok || icon.Host != "example.com" {
+		t.Errorf("Expected the feed URL to be used when the site URL has no icon, got %+v", icon)
+	}
+}
+
+func TestIconCacheFetch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/icon.png":
+			w.Header().Set("Cache-Control", "max-age=0")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", "text/html")
+			w.Write(pngData)
+		case "/icon.svg":
+			w.Write([]byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+		case "/page":
+			w.Write([]byte("<html><body>Not found</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	cache, err := NewIconCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	icon, err := cache.Fetch(server.URL + "/icon.png")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if icon.ContentType != "image/png" || !bytes.Equal(icon.Data, pngData) {
+		t.Errorf("Expected sniffed PNG data, got %q", icon.ContentType)
+	}
+
+	// The icon expired immediately, so it is revalidated
+	if _, err := cache.Fetch(server.URL + "/icon.png"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	icon, err = cache.Fetch(server.URL + "/icon.svg")
+	if err != nil || icon.ContentType != "image/svg+xml" {
+		t.Errorf("Expected an SVG icon, got %v", err)
+	}
+
+	// Fresh icons are served without a request
+	requests = 0
+	if _, err := cache.Fetch(server.URL + "/icon.svg"); err != nil || requests != 0 {
+		t.Errorf("Expected a cached icon without requests, got %d requests, error %v", requests, err)
+	}
+
+	if _, err := cache.Fetch(server.URL + "/page"); !errors.Is(err, ErrNotImage) {
+		t.Errorf("Expected ErrNotImage, got %v", err)
+	}
+
+	// Expired icons are returned as stale while the server is unreachable
+	server.Close()
+	icon, err = cache.Fetch(server.URL + "/icon.png")
+	if err != nil || !icon.Stale {
+		t.Errorf("Expected a stale icon, got %+v, %v", icon, err)
+	}
+
+	if _, err := cache.Get(server.URL + "/missing.png"); !errors.Is(err, ErrIconNotCached) {
+		t.Errorf("Expected ErrIconNotCached, got %v", err)
+	}
+}
+
+func TestIconCacheEviction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(pngData)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	size := int64(len(pngData))
+	cache, err := NewIconCache(dir, 2*size)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"/a.png", "/b.png"} {
+		if _, err := cache.Fetch(server.URL + name); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Using a makes b the least recently used icon
+	if _, err := cache.Get(server.URL + "/a.png"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := cache.Fetch(server.URL + "/c.png"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cache.Size() != 2*size {
+		t.Errorf("Expected size %d, got %d", 2*size, cache.Size())
+	}
+	if _, err := cache.Get(server.URL + "/b.png"); !errors.Is(err, ErrIconNotCached) {
+		t.Errorf("Expected b.png to be evicted, got %v", err)
+	}
+
+	// The cache is restored from disk
+	reopened, err := NewIconCache(dir, 2*size)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, name := range []string{"/a.png", "/c.png"} {
+		if _, err := reopened.Get(server.URL + name); err != nil {
+			t.Errorf("Expected %s to be cached, got %v", name, err)
+		}
+	}
+}
+
+func TestSyncIcons(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/icons.json":
+			json.NewEncoder(w).Encode([]Icon{
+				{Host: "www.example.com", URL: server.URL + "/favicons/example.png"},
+				{Host: "broken.test", URL: server.URL + "/favicons/broken.png"},
+			})
+		case "/subscriptions.json":
+			json.NewEncoder(w).Encode([]Subscription{
+				{FeedID: 1, SiteURL: "https://example.com"},
+				{FeedID: 2, SiteURL: "https://blog.example.com/"},
+				{FeedID: 3, SiteURL: "https://broken.test"},
+				{FeedID: 4, SiteURL: "https://unknown.test"},
+			})
+		case "/favicons/example.png":
+			if _, _, ok := r.BasicAuth(); ok {
+				t.Error("Expected no credentials to be sent for icons")
+			}
+			w.Write(pngData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("user", "pass", server.URL, 30*time.Second)
+	cache, err := NewIconCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result, err := client.SyncIcons(cache)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Icons) != 2 || result.Icons[1] == nil || result.Icons[2] == nil {
+		t.Errorf("Expected icons for feeds 1 and 2, got %v", result.Icons)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != 4 {
+		t.Errorf("Expected feed 4 to be missing, got %v", result.Missing)
+	}
+	if err := result.Errors[server.URL+"/favicons/broken.png"]; err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error for the broken icon, got %v", err)
+	}
+
+	// Icons are available offline
+	offline, err := cache.SubscriptionIcons([]Subscription{{FeedID: 1, SiteURL: "https://www.example.com"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if offline[1] == nil || !bytes.Equal(offline[1].Data, pngData) {
+		t.Errorf("Expected the cached icon for feed 1, got %v", offline)
+	}
+}
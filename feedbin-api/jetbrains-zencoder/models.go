@@ -133,12 +133,10 @@ type SavedSearchRequest struct {
 	Query string `json:"query"`
 }
 
-// Icon represents a Feedbin feed icon.
+// Icon represents the favicon of a site, resized by Feedbin to at most 32x32.
 type Icon struct {
-	ID     int    `json:"id"`
-	URL    string `json:"url"`
-	Width  int    `json:"width,omitempty"`
-	Height int    `json:"height,omitempty"`
+	Host string `json:"host"`
+	URL  string `json:"url"`
 }
 
 // Import represents a Feedbin import.
@@ -431,52 +431,6 @@ func (c *Client) DeleteUpdatedEntries(entryIDs []int64) ([]int64, error) {
 	return ids, err
 }
 
-// Twitter Accounts
-
-func (c *Client) GetTwitterAccounts() ([]*TwitterAccount, error) {
-	req, err := c.newRequest("GET", "twitter_accounts.json", nil)
-	if err != nil {
-		return nil, err
-	}
-	var accounts []*TwitterAccount
-	_, err = c.do(req, &accounts)
-	return accounts, err
-}
-
-func (c *Client) GetTwitterAccount(accountID int64) (*TwitterAccount, error) {
-	path := fmt.Sprintf("twitter_accounts/%d.json", accountID)
-	req, err := c.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-	var account TwitterAccount
-	_, err = c.do(req, &account)
-	return &account, err
-}
-
-// Twitter Tweets
-
-func (c *Client) GetTwitterAccountTweets(accountID int64, opts ...RequestOption) ([]*Tweet, error) {
-	path := fmt.Sprintf("twitter_accounts/%d/tweets.json", accountID)
-	req, err := c.newRequest("GET", path, nil, opts...)
-	if err != nil {
-		return nil, err
-	}
-	var tweets []*Tweet
-	_, err = c.do(req, &tweets)
-	return tweets, err
-}
-
-func (c *Client) GetTwitterTweet(tweetID int64) (*Tweet, error) {
-	path := fmt.Sprintf("twitter_tweets/%d.json", tweetID)
-	req, err := c.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-	var tweet Tweet
-	_, err = c.do(req, &tweet)
-	return &tweet, err
-}
 func (c *Client) UpdateSubscriptionAlt(subID int64, title string) (*Subscription, error) {
 	path := fmt.Sprintf("subscriptions/%d/update.json", subID)
 	body := map[string]string{"title": title}
@@ -105,17 +105,10 @@ type PageContent struct {
 	Content string `json:"content"`
 }
 
-type TwitterAccount struct {
-	ID              int64     `json:"id"`
-	CreatedAt       time.Time `json:"created_at"`
-	ScreenName      string    `json:"screen_name"`
-	ProfileImageURL string    `json:"profile_image_url"`
-}
-
 type Tweet struct {
-	ID         int64     `json:"id"`
-	Text       string    `json:"text"`
-	CreatedAt  time.Time `json:"created_at"`
-	ScreenName string    `json:"screen_name"`
-	TweetID    int64     `json:"tweet_id"`
+	ID                int64     `json:"id"`
+	Text              string    `json:"text"`
+	CreatedAt         time.Time `json:"created_at"`
+	ScreenName        string    `json:"screen_name"`
+	InReplyToStatusID *int64    `json:"in_reply_to_status_id,omitempty"`
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package feedbin
 import (
 	"net/url"
 	"strconv"
+	"time"
 )
 
 type RequestOption func(v url.Values)
@@ -17,4 +18,22 @@ func WithPerPage(perPage int) RequestOption {
 	return func(v url.Values) {
 		v.Set("per_page", strconv.Itoa(perPage))
 	}
-}
\ No newline at end of file
+}
+
+func WithSince(since time.Time) RequestOption {
+	return func(v url.Values) {
+		v.Set("since", since.UTC().Format("2006-01-02T15:04:05.000000Z"))
+	}
+}
+
+func WithMode(mode string) RequestOption {
+	return func(v url.Values) {
+		v.Set("mode", mode)
+	}
+}
+
+func WithIncludeEnclosure() RequestOption {
+	return func(v url.Values) {
+		v.Set("include_enclosure", "true")
+	}
+}
@@ -0,0 +1,291 @@
+package feedbin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RuleAction string
+
+const (
+	RuleActionMarkRead RuleAction = "mark_read"
+	RuleActionStar     RuleAction = "star"
+	RuleActionTag      RuleAction = "tag"
+)
+
+// RuleDuration is a duration written as in time.ParseDuration, with the
+// extra units "d" (days) and "w" (weeks), e.g. "36h", "7d" or "2w".
+type RuleDuration time.Duration
+
+func (d *RuleDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"7d\", got %s", data)
+	}
+	parsed, err := ParseRuleDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d RuleDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func ParseRuleDuration(s string) (RuleDuration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			f, err := strconv.ParseFloat(n, 64)
+			if err != nil || f < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return RuleDuration(f * float64(unit)), nil
+		}
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return RuleDuration(parsed), nil
+}
+
+// RuleConditions must all hold for a rule to match an entry. Lists match if
+// any of their values do. Regexes are case-insensitive and match anywhere in
+// the field; content is matched against the raw HTML, or the summary when
+// the entry has no content.
+type RuleConditions struct {
+	FeedIDs      []int64      `json:"feed_ids,omitempty"`
+	Tags         []string     `json:"tags,omitempty"`
+	Title        string       `json:"title,omitempty"`
+	Author       string       `json:"author,omitempty"`
+	Content      string       `json:"content,omitempty"`
+	OlderThan    RuleDuration `json:"older_than,omitempty"`
+	NewerThan    RuleDuration `json:"newer_than,omitempty"`
+	HasEnclosure *bool        `json:"has_enclosure,omitempty"`
+}
+
+type Rule struct {
+	Name    string         `json:"name"`
+	Match   RuleConditions `json:"match"`
+	Actions []RuleAction   `json:"actions"`
+	// Tag is the tag added to the entry's feed by the tag action.
+	Tag string `json:"tag,omitempty"`
+	// Stop skips the remaining rules for entries this rule matches.
+	Stop bool `json:"stop,omitempty"`
+
+	title   *regexp.Regexp
+	author  *regexp.Regexp
+	content *regexp.Regexp
+}
+
+type RuleSet struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// LoadRules reads a rule file, choosing the format by its extension:
+// .yaml or .yml for YAML, anything else for JSON.
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules *RuleSet
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		rules, err = ParseRulesYAML(data)
+	default:
+		rules, err = ParseRulesJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func ParseRulesJSON(data []byte) (*RuleSet, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var rules RuleSet
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// ParseRulesYAML accepts the same document as ParseRulesJSON written in
+// YAML. Only plain block YAML is supported: no anchors, flow mappings or
+// multi-line strings.
+func ParseRulesYAML(data []byte) (*RuleSet, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRulesJSON(converted)
+}
+
+func (rs *RuleSet) compile() error {
+	names := make(map[string]bool, len(rs.Rules))
+	for i, rule := range rs.Rules {
+		if rule == nil {
+			return fmt.Errorf("rule %d is empty", i+1)
+		}
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	m := r.Match
+	if len(m.FeedIDs) == 0 && len(m.Tags) == 0 && m.Title == "" && m.Author == "" && m.Content == "" &&
+		m.OlderThan == 0 && m.NewerThan == 0 && m.HasEnclosure == nil {
+		return fmt.Errorf("no match conditions; a rule must not match every entry")
+	}
+	if m.OlderThan != 0 && m.NewerThan != 0 && m.NewerThan <= m.OlderThan {
+		return fmt.Errorf("newer_than must be longer than older_than, or no entry can match")
+	}
+
+	var err error
+	if r.title, err = compileRuleRegexp("title", m.Title); err != nil {
+		return err
+	}
+	if r.author, err = compileRuleRegexp("author", m.Author); err != nil {
+		return err
+	}
+	if r.content, err = compileRuleRegexp("content", m.Content); err != nil {
+		return err
+	}
+
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("no actions")
+	}
+	hasTag := false
+	for _, action := range r.Actions {
+		switch action {
+		case RuleActionMarkRead, RuleActionStar:
+		case RuleActionTag:
+			hasTag = true
+		default:
+			return fmt.Errorf("unknown action %q", action)
+		}
+	}
+	if hasTag && strings.TrimSpace(r.Tag) == "" {
+		return fmt.Errorf("the tag action needs a tag")
+	}
+	if !hasTag && r.Tag != "" {
+		return fmt.Errorf("tag %q is set without the tag action", r.Tag)
+	}
+	r.Tag = strings.TrimSpace(r.Tag)
+	return nil
+}
+
+func compileRuleRegexp(field, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s regex: %w", field, err)
+	}
+	return re, nil
+}
+
+// Matches reports whether the rule matches an entry. feedTags are the tag
+// names of the entry's feed and now is the time entry ages are measured from.
+func (r *Rule) Matches(entry *Entry, feedTags []string, now time.Time) bool {
+	m := r.Match
+	if len(m.FeedIDs) > 0 && !containsInt64(m.FeedIDs, entry.FeedID) {
+		return false
+	}
+	if len(m.Tags) > 0 && !hasAnyTag(feedTags, m.Tags) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(stringValue(entry.Title)) {
+		return false
+	}
+	if r.author != nil && !r.author.MatchString(stringValue(entry.Author)) {
+		return false
+	}
+	if r.content != nil {
+		content := stringValue(entry.Content)
+		if strings.TrimSpace(content) == "" {
+			content = entry.Summary
+		}
+		if !r.content.MatchString(content) {
+			return false
+		}
+	}
+	if m.OlderThan != 0 || m.NewerThan != 0 {
+		published := entry.Published
+		if published.IsZero() {
+			published = entry.CreatedAt
+		}
+		age := now.Sub(published)
+		if m.OlderThan != 0 && age < time.Duration(m.OlderThan) {
+			return false
+		}
+		if m.NewerThan != 0 && age >= time.Duration(m.NewerThan) {
+			return false
+		}
+	}
+	if m.HasEnclosure != nil && *m.HasEnclosure != (entry.Enclosure != nil && entry.Enclosure.URL != "") {
+		return false
+	}
+	return true
+}
+
+func containsInt64(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(feedTags, tags []string) bool {
+	for _, have := range feedTags {
+		for _, want := range tags {
+			if strings.EqualFold(strings.TrimSpace(have), strings.TrimSpace(want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
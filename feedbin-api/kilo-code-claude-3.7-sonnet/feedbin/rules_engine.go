@@ -0,0 +1,328 @@
+package feedbin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ruleEntriesPerPage = 100
+	ruleBatchSize      = 1000
+)
+
+// RuleEngine applies a RuleSet to new entries. Feedbin has no server-side
+// filters, so the engine runs client-side: it fetches entries created since
+// a given time, evaluates every rule in order, and then marks read, stars
+// and tags feeds in bulk.
+type RuleEngine struct {
+	client   *Client
+	rules    *RuleSet
+	auditLog io.Writer
+	dryRun   bool
+	now      func() time.Time
+}
+
+type RuleEngineOption func(*RuleEngine)
+
+func NewRuleEngine(client *Client, rules *RuleSet, options ...RuleEngineOption) *RuleEngine {
+	e := &RuleEngine{
+		client: client,
+		rules:  rules,
+		now:    time.Now,
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// WithAuditLog writes a JSON line to w for every action taken.
+func WithAuditLog(w io.Writer) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.auditLog = w
+	}
+}
+
+// WithDryRun evaluates the rules and reports the actions they would take
+// without changing anything.
+func WithDryRun(dryRun bool) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.dryRun = dryRun
+	}
+}
+
+func WithRuleClock(now func() time.Time) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.now = now
+	}
+}
+
+type RuleEntryMatch struct {
+	EntryID int64        `json:"entry_id"`
+	FeedID  int64        `json:"feed_id"`
+	Title   string       `json:"title"`
+	Rules   []string     `json:"rules"`
+	Actions []RuleAction `json:"actions"`
+}
+
+type RuleFeedTag struct {
+	FeedID int64  `json:"feed_id"`
+	Tag    string `json:"tag"`
+	Rule   string `json:"rule"`
+}
+
+type RuleReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Since   time.Time         `json:"since"`
+	Entries int               `json:"entries"`
+	Matches []*RuleEntryMatch `json:"matches"`
+
+	// The changes the rules call for. Entries that are already read or
+	// starred and feeds that already have the tag are left out.
+	MarkRead []int64       `json:"mark_read"`
+	Star     []int64       `json:"star"`
+	Tag      []RuleFeedTag `json:"tag"`
+
+	// Applied counts the changes made; it stays zero in a dry run.
+	Applied int `json:"applied"`
+
+	markReadBy map[int64]ruleCredit
+	starBy     map[int64]ruleCredit
+}
+
+// ruleCredit records the rule that called for a change to an entry
+type ruleCredit struct {
+	rule  string
+	match *RuleEntryMatch
+}
+
+type RuleAuditRecord struct {
+	Time    time.Time  `json:"time"`
+	Rule    string     `json:"rule"`
+	Action  RuleAction `json:"action"`
+	EntryID int64      `json:"entry_id,omitempty"`
+	FeedID  int64      `json:"feed_id"`
+	Title   string     `json:"title,omitempty"`
+	Tag     string     `json:"tag,omitempty"`
+}
+
+// Run applies the rules to entries created since the given time. On error
+// the returned report still describes the changes made before it.
+func (e *RuleEngine) Run(since time.Time) (*RuleReport, error) {
+	entries, err := e.fetchEntries(since)
+	if err != nil {
+		return nil, fmt.Errorf("fetching entries: %w", err)
+	}
+	taggings, err := e.client.GetTaggings()
+	if err != nil {
+		return nil, fmt.Errorf("fetching taggings: %w", err)
+	}
+	unread, err := e.client.GetUnreadEntryIDs()
+	if err != nil {
+		return nil, fmt.Errorf("fetching unread entries: %w", err)
+	}
+	starred, err := e.client.GetStarredEntryIDs()
+	if err != nil {
+		return nil, fmt.Errorf("fetching starred entries: %w", err)
+	}
+
+	report := e.Evaluate(entries, taggings, unread, starred)
+	report.Since = since
+	if e.dryRun {
+		return report, nil
+	}
+	return report, e.apply(report)
+}
+
+// Evaluate runs the rules over entries without calling the API. The first
+// rule to call for a change is the one credited with it.
+func (e *RuleEngine) Evaluate(entries []*Entry, taggings []*Tagging, unreadEntryIDs, starredEntryIDs []int64) *RuleReport {
+	feedTags := make(map[int64][]string)
+	for _, tagging := range taggings {
+		feedTags[tagging.FeedID] = append(feedTags[tagging.FeedID], tagging.Name)
+	}
+	unread := int64Set(unreadEntryIDs)
+	starred := int64Set(starredEntryIDs)
+
+	report := &RuleReport{
+		DryRun:     e.dryRun,
+		Entries:    len(entries),
+		markReadBy: make(map[int64]ruleCredit),
+		starBy:     make(map[int64]ruleCredit),
+	}
+	now := e.now()
+	for _, entry := range entries {
+		var match *RuleEntryMatch
+		for _, rule := range e.rules.Rules {
+			if !rule.Matches(entry, feedTags[entry.FeedID], now) {
+				continue
+			}
+			if match == nil {
+				match = &RuleEntryMatch{EntryID: entry.ID, FeedID: entry.FeedID, Title: stringValue(entry.Title)}
+				report.Matches = append(report.Matches, match)
+			}
+			match.Rules = append(match.Rules, rule.Name)
+
+			for _, action := range rule.Actions {
+				switch action {
+				case RuleActionMarkRead:
+					if _, done := report.markReadBy[entry.ID]; unread[entry.ID] && !done {
+						report.markReadBy[entry.ID] = ruleCredit{rule: rule.Name, match: match}
+						report.MarkRead = append(report.MarkRead, entry.ID)
+						match.Actions = append(match.Actions, action)
+					}
+				case RuleActionStar:
+					if _, done := report.starBy[entry.ID]; !starred[entry.ID] && !done {
+						report.starBy[entry.ID] = ruleCredit{rule: rule.Name, match: match}
+						report.Star = append(report.Star, entry.ID)
+						match.Actions = append(match.Actions, action)
+					}
+				case RuleActionTag:
+					if !hasAnyTag(feedTags[entry.FeedID], []string{rule.Tag}) {
+						feedTags[entry.FeedID] = append(feedTags[entry.FeedID], rule.Tag)
+						report.Tag = append(report.Tag, RuleFeedTag{FeedID: entry.FeedID, Tag: rule.Tag, Rule: rule.Name})
+						match.Actions = append(match.Actions, action)
+					}
+				}
+			}
+			if rule.Stop {
+				break
+			}
+		}
+	}
+	return report
+}
+
+func (e *RuleEngine) fetchEntries(since time.Time) ([]*Entry, error) {
+	var entries []*Entry
+	for page := 1; ; page++ {
+		batch, err := e.client.GetEntries(WithSince(since), WithIncludeEnclosure(), WithPage(page), WithPerPage(ruleEntriesPerPage))
+		if err != nil {
+			// Feedbin answers 404 for a page past the last one
+			if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == http.StatusNotFound && page > 1 {
+				break
+			}
+			return nil, err
+		}
+		entries = append(entries, batch...)
+		if len(batch) < ruleEntriesPerPage {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func (e *RuleEngine) apply(report *RuleReport) error {
+	for _, ids := range chunkInt64s(report.MarkRead, ruleBatchSize) {
+		if _, err := e.client.MarkEntriesAsRead(ids); err != nil {
+			return fmt.Errorf("marking entries as read: %w", err)
+		}
+		for _, id := range ids {
+			if err := e.auditEntry(RuleActionMarkRead, report.markReadBy[id]); err != nil {
+				return err
+			}
+		}
+		report.Applied += len(ids)
+	}
+
+	for _, ids := range chunkInt64s(report.Star, ruleBatchSize) {
+		if _, err := e.client.StarEntries(ids); err != nil {
+			return fmt.Errorf("starring entries: %w", err)
+		}
+		for _, id := range ids {
+			if err := e.auditEntry(RuleActionStar, report.starBy[id]); err != nil {
+				return err
+			}
+		}
+		report.Applied += len(ids)
+	}
+
+	for _, tag := range report.Tag {
+		if _, err := e.client.CreateTagging(tag.FeedID, tag.Tag); err != nil {
+			return fmt.Errorf("tagging feed %d as %q: %w", tag.FeedID, tag.Tag, err)
+		}
+		err := e.audit(RuleAuditRecord{Rule: tag.Rule, Action: RuleActionTag, FeedID: tag.FeedID, Tag: tag.Tag})
+		if err != nil {
+			return err
+		}
+		report.Applied++
+	}
+	return nil
+}
+
+func (e *RuleEngine) auditEntry(action RuleAction, credit ruleCredit) error {
+	return e.audit(RuleAuditRecord{
+		Rule:    credit.rule,
+		Action:  action,
+		EntryID: credit.match.EntryID,
+		FeedID:  credit.match.FeedID,
+		Title:   credit.match.Title,
+	})
+}
+
+func (e *RuleEngine) audit(record RuleAuditRecord) error {
+	if e.auditLog == nil {
+		return nil
+	}
+	record.Time = e.now().UTC()
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := e.auditLog.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *RuleReport) String() string {
+	var b strings.Builder
+	mode := "Applied"
+	if r.DryRun {
+		mode = "Dry run"
+	}
+	fmt.Fprintf(&b, "%s: %d entries checked, %d matched\n", mode, r.Entries, len(r.Matches))
+	for _, match := range r.Matches {
+		actions := make([]string, len(match.Actions))
+		for i, action := range match.Actions {
+			actions[i] = string(action)
+		}
+		if len(actions) == 0 {
+			actions = []string{"no change"}
+		}
+		fmt.Fprintf(&b, "  entry %d (feed %d) %q: %s [%s]\n", match.EntryID, match.FeedID, match.Title,
+			strings.Join(actions, ", "), strings.Join(match.Rules, ", "))
+	}
+	tags := append([]RuleFeedTag(nil), r.Tag...)
+	sort.Slice(tags, func(i, j int) bool { return tags[i].FeedID < tags[j].FeedID })
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "  feed %d: tag %q [%s]\n", tag.FeedID, tag.Tag, tag.Rule)
+	}
+	fmt.Fprintf(&b, "mark read: %d, star: %d, tag: %d\n", len(r.MarkRead), len(r.Star), len(r.Tag))
+	return b.String()
+}
+
+func int64Set(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func chunkInt64s(ids []int64, size int) [][]int64 {
+	var chunks [][]int64
+	for len(ids) > size {
+		chunks = append(chunks, ids[:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
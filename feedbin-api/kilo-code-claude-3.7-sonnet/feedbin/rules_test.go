@@ -0,0 +1,190 @@
+package feedbin
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRulesYAML_MatchesJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		json string
+	}{
+		{
+			name: "mappings in a sequence",
+			yaml: `
+# Rules for the morning
+rules:
+  - name: sponsored
+    match:
+      title: "^(sponsored|ad):"  # quoted regex with a colon
+    actions: [mark_read]
+    stop: true
+  - name: podcasts
+    match:
+      has_enclosure: true
+      newer_than: 2w
+    actions:
+      - tag
+    tag: Podcasts
+`,
+			json: `{"rules": [
+				{"name": "sponsored", "match": {"title": "^(sponsored|ad):"}, "actions": ["mark_read"], "stop": true},
+				{"name": "podcasts", "match": {"has_enclosure": true, "newer_than": "2w"}, "actions": ["tag"], "tag": "Podcasts"}
+			]}`,
+		},
+		{
+			name: "hashes in quotes and flow lists",
+			yaml: `---
+rules:
+- name: 'C# news'
+  match:
+    feed_ids: [1, 2, 3]
+    tags: ["Dev", 'Work # later']
+    content: "#(golang|rust)\\b"
+    author: 'O''Brien'
+  actions: [star, "mark_read"]
+...
+ignored: after the document end
+`,
+			json: `{"rules": [
+				{"name": "C# news", "match": {"feed_ids": [1, 2, 3], "tags": ["Dev", "Work # later"], "content": "#(golang|rust)\\b", "author": "O'Brien"}, "actions": ["star", "mark_read"]}
+			]}`,
+		},
+		{
+			name: "empty document",
+			yaml: "# nothing yet\n",
+			json: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromYAML, err := ParseRulesYAML([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("ParseRulesYAML returned error: %v", err)
+			}
+			fromJSON, err := ParseRulesJSON([]byte(tt.json))
+			if err != nil {
+				t.Fatalf("ParseRulesJSON returned error: %v", err)
+			}
+			got, _ := json.Marshal(fromYAML)
+			want, _ := json.Marshal(fromJSON)
+			if string(got) != string(want) {
+				t.Errorf("YAML rules = %s\nJSON rules = %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRulesYAML_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"tab indentation", "rules:\n\t- name: x\n", "tabs"},
+		{"anchor", "rules: &all\n  - name: x\n", "anchors"},
+		{"alias", "rules: *all\n", "anchors"},
+		{"tag", "rules:\n  - name: !!str x\n", "tags"},
+		{"flow mapping", "rules:\n  - match: {title: x}\n", "flow mappings"},
+		{"block scalar", "rules:\n  - name: |\n      x\n", "multi-line"},
+		{"bad indentation", "rules:\n  - name: x\n     match: y\n", "indentation"},
+		{"duplicate key", "rules:\n  - name: x\n    name: y\n", "duplicate key"},
+		{"unknown field", "rules:\n  - name: x\n    when: y\n", "unknown field"},
+		{"rule matching everything", "rules:\n  - name: x\n    actions: [mark_read]\n", "no match conditions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRulesYAML([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseRulesYAML error = %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRuleEngine_Evaluate(t *testing.T) {
+	rules, err := ParseRulesYAML([]byte(`
+rules:
+  - name: sponsored
+    match:
+      title: sponsored
+    actions: [mark_read]
+    stop: true
+  - name: news feed
+    match:
+      feed_ids: [1]
+    actions: [mark_read, star]
+  - name: news title
+    match:
+      title: news
+    actions: [mark_read, star]
+  - name: podcasts
+    match:
+      has_enclosure: true
+    actions: [tag]
+    tag: Podcasts
+`))
+	if err != nil {
+		t.Fatalf("ParseRulesYAML returned error: %v", err)
+	}
+
+	title := func(s string) *string { return &s }
+	enclosure := &Enclosure{URL: "https://example.com/episode.mp3"}
+	entries := []*Entry{
+		{ID: 1, FeedID: 1, Title: title("Sponsored: a new phone")},
+		{ID: 2, FeedID: 1, Title: title("News of the day")},
+		{ID: 3, FeedID: 1, Title: title("Yesterday's news")},
+		{ID: 4, FeedID: 2, Title: title("Episode 1"), Enclosure: enclosure},
+		{ID: 5, FeedID: 2, Title: title("Episode 2"), Enclosure: enclosure},
+		{ID: 6, FeedID: 3, Title: title("Unrelated")},
+	}
+	// Entry 3 is already read and starred; feed 3 is already a podcast
+	unread := []int64{1, 2, 4, 5, 6}
+	starred := []int64{3}
+	taggings := []*Tagging{{FeedID: 3, Name: "podcasts"}}
+
+	engine := NewRuleEngine(nil, rules, WithRuleClock(func() time.Time { return time.Unix(0, 0) }))
+	report := engine.Evaluate(entries, taggings, unread, starred)
+
+	if report.Entries != 6 || len(report.Matches) != 5 {
+		t.Errorf("Entries = %d, matches = %d, want 6 and 5", report.Entries, len(report.Matches))
+	}
+	if got, want := int64s(report.MarkRead), "1,2"; got != want {
+		t.Errorf("MarkRead = %s, want %s", got, want)
+	}
+	if got, want := int64s(report.Star), "2"; got != want {
+		t.Errorf("Star = %s, want %s (stop must keep entry 1 from being starred)", got, want)
+	}
+	if len(report.Tag) != 1 || report.Tag[0].FeedID != 2 || report.Tag[0].Tag != "Podcasts" {
+		t.Errorf("Tag = %+v, want feed 2 tagged once", report.Tag)
+	}
+
+	// The first matching rule is credited, later ones are still listed
+	if credit := report.markReadBy[2]; credit.rule != "news feed" {
+		t.Errorf("mark read of entry 2 credited to %q, want \"news feed\"", credit.rule)
+	}
+	if got := strings.Join(report.Matches[1].Rules, ","); got != "news feed,news title" {
+		t.Errorf("entry 2 matched %s", got)
+	}
+	if got := strings.Join(report.Matches[0].Rules, ","); got != "sponsored" {
+		t.Errorf("entry 1 matched %s, want only the stopping rule", got)
+	}
+	if report.Matches[2].EntryID != 3 || len(report.Matches[2].Actions) != 0 {
+		t.Errorf("entry 3 is already read and starred, got actions %v", report.Matches[2].Actions)
+	}
+}
+
+func int64s(ids []int64) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(s, ",")
+}
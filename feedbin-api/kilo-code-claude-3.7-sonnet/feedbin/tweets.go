@@ -0,0 +1,144 @@
+package feedbin
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	ModeExtended = "extended"
+
+	// TweetLookupBatchSize is the most IDs Twitter's statuses/lookup API
+	// accepts in one request.
+	TweetLookupBatchSize = 100
+)
+
+// TweetLookup loads tweets by ID, e.g. from Twitter's statuses/lookup API
+// using the user's own Twitter credentials. Feedbin only provides the IDs,
+// as Twitter's terms do not allow it to redistribute tweet data. It is
+// called with at most TweetLookupBatchSize IDs; tweets that no longer exist
+// may be left out of the result.
+type TweetLookup interface {
+	LookupTweets(ids []int64) ([]*Tweet, error)
+}
+
+type TweetLookupFunc func(ids []int64) ([]*Tweet, error)
+
+func (f TweetLookupFunc) LookupTweets(ids []int64) ([]*Tweet, error) {
+	return f(ids)
+}
+
+// EntryTweets holds the Twitter data of an entry fetched in extended mode.
+type EntryTweets struct {
+	EntryID int64
+	FeedID  int64
+	TweetID int64
+	// ThreadIDs are the IDs of the tweets in the thread, including TweetID,
+	// oldest first.
+	ThreadIDs         []int64
+	ExtractedArticles []*ExtractedArticle
+}
+
+// NewEntryTweets returns the Twitter data of an entry, or false if the entry
+// is not a tweet. The entry must have been fetched with WithMode(ModeExtended).
+func NewEntryTweets(entry *Entry) (*EntryTweets, bool) {
+	if entry == nil || entry.TwitterID == nil {
+		return nil, false
+	}
+	return &EntryTweets{
+		EntryID:           entry.ID,
+		FeedID:            entry.FeedID,
+		TweetID:           *entry.TwitterID,
+		ThreadIDs:         sortTweetIDs(append([]int64{*entry.TwitterID}, entry.TwitterThreadIDs...)),
+		ExtractedArticles: entry.ExtractedArticles,
+	}, true
+}
+
+// GetEntryTweets fetches entries in extended mode and returns the Twitter
+// data of those that are tweets.
+func (c *Client) GetEntryTweets(opts ...RequestOption) ([]*EntryTweets, error) {
+	opts = append(opts[:len(opts):len(opts)], WithMode(ModeExtended))
+	entries, err := c.GetEntries(opts...)
+	if err != nil {
+		return nil, err
+	}
+	var tweets []*EntryTweets
+	for _, entry := range entries {
+		if t, ok := NewEntryTweets(entry); ok {
+			tweets = append(tweets, t)
+		}
+	}
+	return tweets, nil
+}
+
+// TweetIDs returns the IDs of every tweet in the threads, without
+// duplicates, oldest first.
+func TweetIDs(entries []*EntryTweets) []int64 {
+	var ids []int64
+	for _, entry := range entries {
+		ids = append(ids, entry.ThreadIDs...)
+	}
+	return sortTweetIDs(ids)
+}
+
+// BatchTweetIDs splits IDs into groups of TweetLookupBatchSize, the size of
+// one statuses/lookup request.
+func BatchTweetIDs(ids []int64) [][]int64 {
+	return chunkInt64s(ids, TweetLookupBatchSize)
+}
+
+type TweetThread struct {
+	Entry *EntryTweets
+	// Tweet is the entry's own tweet, or nil if the lookup did not return it.
+	Tweet *Tweet
+	// Thread holds the tweets of the thread that were found, oldest first.
+	Thread []*Tweet
+	// Missing lists the thread IDs the lookup did not return, e.g. because
+	// the tweets were deleted.
+	Missing []int64
+}
+
+// LoadTweets looks up the tweets of entries in batches and assembles each
+// entry's thread. Every tweet is looked up once, even when it appears in
+// several threads.
+func LoadTweets(lookup TweetLookup, entries []*EntryTweets) ([]*TweetThread, error) {
+	found := make(map[int64]*Tweet)
+	for i, batch := range BatchTweetIDs(TweetIDs(entries)) {
+		tweets, err := lookup.LookupTweets(batch)
+		if err != nil {
+			return nil, fmt.Errorf("looking up tweets (batch %d): %w", i+1, err)
+		}
+		for _, tweet := range tweets {
+			if tweet != nil {
+				found[tweet.ID] = tweet
+			}
+		}
+	}
+
+	threads := make([]*TweetThread, 0, len(entries))
+	for _, entry := range entries {
+		thread := &TweetThread{Entry: entry, Tweet: found[entry.TweetID]}
+		for _, id := range entry.ThreadIDs {
+			if tweet, ok := found[id]; ok {
+				thread.Thread = append(thread.Thread, tweet)
+			} else {
+				thread.Missing = append(thread.Missing, id)
+			}
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// sortTweetIDs sorts and deduplicates IDs in place. Tweet IDs increase over
+// time, so this puts them in chronological order.
+func sortTweetIDs(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	unique := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,113 @@
+package feedbin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func tweetEntry(id, tweetID int64, threadIDs ...int64) *Entry {
+	return &Entry{ID: id, FeedID: 1, TwitterID: &tweetID, TwitterThreadIDs: threadIDs}
+}
+
+func TestNewEntryTweets(t *testing.T) {
+	tweets, ok := NewEntryTweets(tweetEntry(1, 5, 9, 3, 5, 3))
+	if !ok {
+		t.Fatal("NewEntryTweets returned false for a tweet")
+	}
+	if want := []int64{3, 5, 9}; !reflect.DeepEqual(tweets.ThreadIDs, want) {
+		t.Errorf("ThreadIDs = %v, want %v", tweets.ThreadIDs, want)
+	}
+
+	if _, ok := NewEntryTweets(&Entry{ID: 2}); ok {
+		t.Error("NewEntryTweets returned true for an entry without a tweet")
+	}
+}
+
+func TestLoadTweets(t *testing.T) {
+	// A 250 tweet thread and a second thread made of three of its tweets
+	var long []int64
+	for id := int64(250); id >= 2; id-- {
+		long = append(long, id)
+	}
+	entries := []*EntryTweets{}
+	for _, entry := range []*Entry{tweetEntry(1, 1, long...), tweetEntry(2, 14, 10, 7, 14)} {
+		tweets, _ := NewEntryTweets(entry)
+		entries = append(entries, tweets)
+	}
+
+	// Every seventh tweet no longer exists
+	lookups := make(map[int64]int)
+	var batchSizes []int
+	lookup := TweetLookupFunc(func(ids []int64) ([]*Tweet, error) {
+		batchSizes = append(batchSizes, len(ids))
+		var tweets []*Tweet
+		for _, id := range ids {
+			lookups[id]++
+			if id%7 != 0 {
+				tweets = append(tweets, &Tweet{ID: id})
+			}
+		}
+		return tweets, nil
+	})
+
+	threads, err := LoadTweets(lookup, entries)
+	if err != nil {
+		t.Fatalf("LoadTweets returned error: %v", err)
+	}
+
+	if want := []int{100, 100, 50}; !reflect.DeepEqual(batchSizes, want) {
+		t.Errorf("batch sizes = %v, want %v", batchSizes, want)
+	}
+	for id, n := range lookups {
+		if n != 1 {
+			t.Errorf("tweet %d looked up %d times", id, n)
+		}
+	}
+
+	if len(threads) != 2 {
+		t.Fatalf("LoadTweets returned %d threads, want 2", len(threads))
+	}
+	long1 := threads[0]
+	if long1.Tweet == nil || long1.Tweet.ID != 1 {
+		t.Errorf("Tweet = %+v, want tweet 1", long1.Tweet)
+	}
+	if len(long1.Thread) != 250-35 || long1.Thread[0].ID != 1 || long1.Thread[len(long1.Thread)-1].ID != 250 {
+		t.Errorf("unexpected thread of %d tweets", len(long1.Thread))
+	}
+	if len(long1.Missing) != 35 || long1.Missing[0] != 7 {
+		t.Errorf("Missing = %v", long1.Missing)
+	}
+
+	short := threads[1]
+	if short.Tweet != nil {
+		t.Errorf("Tweet = %+v, want nil for a missing tweet", short.Tweet)
+	}
+	if !reflect.DeepEqual(short.Missing, []int64{7, 14}) || len(short.Thread) != 1 || short.Thread[0].ID != 10 {
+		t.Errorf("Thread = %v, Missing = %v", short.Thread, short.Missing)
+	}
+}
+
+func TestBatchTweetIDs(t *testing.T) {
+	ids := make([]int64, 250)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	batches := BatchTweetIDs(ids)
+	if len(batches) != 3 || len(batches[0]) != 100 || len(batches[1]) != 100 || len(batches[2]) != 50 {
+		t.Errorf("BatchTweetIDs split 250 IDs into %d batches", len(batches))
+	}
+	if batches[2][0] != 201 || batches[2][49] != 250 {
+		t.Errorf("last batch = %v", batches[2])
+	}
+}
+
+func TestLoadTweets_Error(t *testing.T) {
+	tweets, _ := NewEntryTweets(tweetEntry(1, 1))
+	lookup := TweetLookupFunc(func(ids []int64) ([]*Tweet, error) {
+		return nil, errors.New("rate limited")
+	})
+	if _, err := LoadTweets(lookup, []*EntryTweets{tweets}); err == nil {
+		t.Error("Expected LoadTweets to return the lookup error")
+	}
+}
@@ -0,0 +1,322 @@
+package feedbin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML decodes the subset of YAML used by rule files into the same
+// values encoding/json produces: block mappings and sequences, single-line
+// flow sequences, comments, and plain or quoted scalars. Anchors, tags,
+// flow mappings and multi-line strings are rejected.
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("yaml: line %d: tabs are not allowed for indentation", i+1)
+		}
+		text = stripYAMLComment(text)
+		if text == "" || text == "---" {
+			continue
+		}
+		if text == "..." {
+			break
+		}
+		lines = append(lines, yamlLine{indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text, num: i + 1})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	v, err := p.parseNode(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return v, nil
+}
+
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	num := 0
+	if p.i < len(p.lines) {
+		num = p.lines[p.i].num
+	} else if len(p.lines) > 0 {
+		num = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	if isYAMLSequenceItem(p.lines[p.i].text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(p.lines[p.i].text); ok {
+		return p.parseMapping(indent)
+	}
+	v, err := parseYAMLScalar(p.lines[p.i].text)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	p.i++
+	return v, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if !isYAMLSequenceItem(line.text) {
+			break
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		if rest == "" {
+			p.i++
+			if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+				item, err := p.parseNode(p.lines[p.i].indent)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			} else {
+				items = append(items, nil)
+			}
+			continue
+		}
+
+		// "- key: value" starts a mapping whose keys line up with "key"
+		p.lines[p.i] = yamlLine{indent: indent + len(line.text) - len(rest), text: rest, num: line.num}
+		item, err := p.parseNode(p.lines[p.i].indent)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.i < len(p.lines) {
+		line := p.lines[p.i]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if isYAMLSequenceItem(line.text) {
+			break
+		}
+
+		key, value, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", got %q", line.text)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.i++
+
+		if value != "" {
+			v, err := parseYAMLScalar(value)
+			if err != nil {
+				p.i--
+				return nil, p.errorf("%v", err)
+			}
+			m[key] = v
+			continue
+		}
+
+		switch {
+		case p.i < len(p.lines) && p.lines[p.i].indent > indent:
+			v, err := p.parseNode(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLSequenceItem(p.lines[p.i].text):
+			// Sequences may sit at the same indentation as their key
+			v, err := p.parseSequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		default:
+			m[key] = nil
+		}
+	}
+	return m, nil
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value" at the first colon outside quotes that is
+// followed by a space or the end of the line.
+func splitYAMLKey(text string) (key, value string, ok bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			key = strings.TrimSpace(text[:i])
+			if unquoted, err := parseYAMLScalar(key); err == nil {
+				if s, isString := unquoted.(string); isString {
+					key = s
+				}
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" [,:-", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return strings.TrimRight(text[:i], " ")
+		}
+	}
+	return text
+}
+
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		return parseYAMLFlowSequence(text)
+	case strings.HasPrefix(text, "{"):
+		return nil, fmt.Errorf("flow mappings are not supported")
+	case strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">"):
+		return nil, fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") || strings.HasPrefix(text, "!"):
+		return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+	case strings.HasPrefix(text, `"`):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return s, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		inner := text[1 : len(text)-1]
+		if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return strings.ReplaceAll(inner, "''", "'"), nil
+	}
+
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXpP_") {
+		return f, nil
+	}
+	return text, nil
+}
+
+func parseYAMLFlowSequence(text string) (interface{}, error) {
+	if !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("flow sequences must end on the same line: %s", text)
+	}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	items := []interface{}{}
+	if inner == "" {
+		return items, nil
+	}
+
+	var quote byte
+	start := 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			c := inner[i]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				} else if c == '\\' && quote == '"' {
+					i++
+				}
+				continue
+			}
+			if c == '"' || c == '\'' {
+				quote = c
+				continue
+			}
+			if c == '[' || c == '{' {
+				return nil, fmt.Errorf("nested flow collections are not supported")
+			}
+			if c != ',' {
+				continue
+			}
+		}
+		item := strings.TrimSpace(inner[start:i])
+		if item == "" {
+			return nil, fmt.Errorf("empty item in flow sequence %s", text)
+		}
+		v, err := parseYAMLScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		start = i + 1
+	}
+	return items, nil
+}
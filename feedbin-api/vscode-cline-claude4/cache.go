@@ -0,0 +1,360 @@
+package feedbin
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheMaxBytes is the size limit of the LRU cache created by EnableCache
+const DefaultCacheMaxBytes = 32 << 20
+
+// DefaultFileCacheMaxBytes is the default size limit of a FileCache
+const DefaultFileCacheMaxBytes = 64 << 20
+
+// CacheStats holds the cache counters of a client
+type CacheStats struct {
+	// Hits is the number of lookups that found a cached response
+	Hits int64
+
+	// Misses is the number of lookups that found nothing in the cache
+	Misses int64
+
+	// Revalidations is the number of 304 Not Modified responses answered from the cache
+	Revalidations int64
+}
+
+// cacheCounters tracks CacheStats with atomic counters
+type cacheCounters struct {
+	hits          atomic.Int64
+	misses        atomic.Int64
+	revalidations atomic.Int64
+}
+
+// CacheStats returns the client's cache hit, miss and revalidation counters
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:          c.stats.hits.Load(),
+		Misses:        c.stats.misses.Load(),
+		Revalidations: c.stats.revalidations.Load(),
+	}
+}
+
+// cacheGet looks up key in the client's cache and updates the hit and miss counters
+func (c *Client) cacheGet(key string) (*CachedResponse, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+
+	cached, found := c.cache.Get(key)
+	if found {
+		c.stats.hits.Add(1)
+	} else {
+		c.stats.misses.Add(1)
+	}
+
+	return cached, found
+}
+
+// LRUCache implements CacheManager with a size-bounded in-memory cache.
+// When the total size of the cached bodies exceeds the limit, the least
+// recently used responses are evicted. It is safe for concurrent use.
+type LRUCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// lruItem is an element of LRUCache.order
+type lruItem struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewLRUCache creates a new LRU cache holding at most maxBytes of response bodies.
+// A maxBytes of zero or less uses DefaultCacheMaxBytes.
+func NewLRUCache(maxBytes int64) *LRUCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultCacheMaxBytes
+	}
+
+	return &LRUCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get retrieves a cached response and marks it as recently used
+func (l *LRUCache) Get(key string) (*CachedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, found := l.items[key]
+	if !found {
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruItem).response, true
+}
+
+// Set stores a response in the cache, evicting old responses if needed.
+// Responses larger than the whole cache are not stored.
+func (l *LRUCache) Set(key string, response *CachedResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, found := l.items[key]; found {
+		l.remove(elem)
+	}
+
+	size := int64(len(response.Data))
+	if size > l.maxBytes {
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, response: response})
+	l.size += size
+
+	for l.size > l.maxBytes {
+		l.remove(l.order.Back())
+	}
+}
+
+// Len returns the number of cached responses
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// Size returns the total size of the cached bodies in bytes
+func (l *LRUCache) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.size
+}
+
+// remove deletes an element; the caller must hold l.mu
+func (l *LRUCache) remove(elem *list.Element) {
+	item := l.order.Remove(elem).(*lruItem)
+	delete(l.items, item.key)
+	l.size -= int64(len(item.response.Data))
+}
+
+// FileCache implements CacheManager by storing responses on disk, so that
+// ETag and Last-Modified validators survive between runs. Each response is
+// written to its own file, named after a hash of the cache key.
+//
+// When the total size of the files exceeds the limit, the least recently
+// used responses are removed, going by the modification times of the files,
+// which Get updates.
+//
+// Cache keys are request URLs and do not include credentials, so use a
+// separate directory for each account. FileCache is safe for concurrent use.
+type FileCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	size     int64
+}
+
+// fileCacheEntry is the on-disk format of a FileCache entry
+type fileCacheEntry struct {
+	Key      string          `json:"key"`
+	Response *CachedResponse `json:"response"`
+}
+
+// NewFileCache creates a file cache in dir holding at most maxBytes of files,
+// creating the directory if needed. A maxBytes of zero or less uses
+// DefaultFileCacheMaxBytes. Responses left by an earlier run count towards
+// the limit and are evicted first if it is lower now.
+func NewFileCache(dir string, maxBytes int64) (*FileCache, error) {
+	if dir == "" {
+		return nil, &ValidationError{Field: "dir", Message: "cache directory is required"}
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultFileCacheMaxBytes
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	f := &FileCache{dir: dir, maxBytes: maxBytes}
+
+	files, err := f.files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, file := range files {
+		f.size += file.size
+	}
+	f.evict(files)
+
+	return f, nil
+}
+
+// Get retrieves a cached response from disk. Unreadable or corrupt files are
+// treated as missing.
+func (f *FileCache) Get(key string) (*CachedResponse, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key || entry.Response == nil {
+		return nil, false
+	}
+
+	// Mark the response as recently used
+	now := time.Now()
+	os.Chtimes(f.path(key), now, now)
+
+	return entry.Response, true
+}
+
+// Set stores a response on disk. The file is written to a temporary file and
+// renamed, so a crash never leaves a partial entry behind. Write errors are
+// ignored, as a failed cache write only costs a future download. Responses
+// larger than the whole cache are not stored.
+func (f *FileCache) Set(key string, response *CachedResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.Marshal(fileCacheEntry{Key: key, Response: response})
+	if err != nil || int64(len(data)) > f.maxBytes {
+		return
+	}
+
+	path := f.path(key)
+	var oldSize int64
+	if info, err := os.Stat(path); err == nil {
+		oldSize = info.Size()
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	f.size += int64(len(data)) - oldSize
+	if f.size > f.maxBytes {
+		if files, err := f.files(); err == nil {
+			f.evict(files)
+		}
+	}
+}
+
+// Clear removes every cached response from disk, along with any
+// temporary files left behind by an interrupted write
+func (f *FileCache) Clear() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, pattern := range []string{"*.json", ".tmp-*"} {
+		matches, err := filepath.Glob(filepath.Join(f.dir, pattern))
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	f.size = 0
+	return nil
+}
+
+// Size returns the total size of the cache files in bytes
+func (f *FileCache) Size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.size
+}
+
+// fileCacheFile is a cache file found on disk
+type fileCacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the cache files, least recently used first; the caller must
+// hold f.mu
+func (f *FileCache) files() ([]fileCacheFile, error) {
+	matches, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]fileCacheFile, 0, len(matches))
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		files = append(files, fileCacheFile{path: match, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	return files, nil
+}
+
+// evict removes the least recently used files until the cache fits in
+// maxBytes. files must be sorted as returned by files; the caller must hold
+// f.mu.
+func (f *FileCache) evict(files []fileCacheFile) {
+	for _, file := range files {
+		if f.size <= f.maxBytes {
+			return
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		f.size -= file.size
+	}
+}
+
+// path returns the file used to store key
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
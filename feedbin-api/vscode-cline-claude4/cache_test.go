@@ -0,0 +1,195 @@
+package feedbin
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUCache_Eviction(t *testing.T) {
+	cache := NewLRUCache(10)
+	cache.Set("a", &CachedResponse{Data: []byte("aaaa")})
+	cache.Set("b", &CachedResponse{Data: []byte("bbbb")})
+
+	// Using a makes b the least recently used response
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) found nothing")
+	}
+	cache.Set("c", &CachedResponse{Data: []byte("cccc")})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+	if cache.Len() != 2 || cache.Size() != 8 {
+		t.Errorf("Len = %d, Size = %d, want 2 and 8", cache.Len(), cache.Size())
+	}
+
+	// Replacing a response updates the size
+	cache.Set("a", &CachedResponse{Data: []byte("a")})
+	if cache.Size() != 5 {
+		t.Errorf("Size after replacing a = %d, want 5", cache.Size())
+	}
+
+	// Responses larger than the cache are not stored
+	cache.Set("big", &CachedResponse{Data: []byte("0123456789x")})
+	if _, ok := cache.Get("big"); ok || cache.Len() != 2 {
+		t.Error("a response larger than the cache was stored")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileCache returned error: %v", err)
+	}
+
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache.Set("https://api.feedbin.com/v2/tags.json", &CachedResponse{
+		Data:         []byte(`[{"id":1}]`),
+		ETag:         `"abc"`,
+		LastModified: modified,
+	})
+
+	// No temporary files are left behind
+	matches, _ := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	if len(matches) != 0 {
+		t.Errorf("Set left temporary files: %v", matches)
+	}
+
+	// A new cache on the same directory reads the response back
+	reloaded, err := NewFileCache(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileCache returned error: %v", err)
+	}
+	if reloaded.Size() != cache.Size() || cache.Size() == 0 {
+		t.Errorf("reloaded Size = %d, want %d", reloaded.Size(), cache.Size())
+	}
+	cached, ok := reloaded.Get("https://api.feedbin.com/v2/tags.json")
+	if !ok {
+		t.Fatal("Get after reload found nothing")
+	}
+	if string(cached.Data) != `[{"id":1}]` || cached.ETag != `"abc"` || !cached.LastModified.Equal(modified) {
+		t.Errorf("Get after reload = %+v", cached)
+	}
+
+	// Corrupt files are treated as missing
+	path := reloaded.path("https://api.feedbin.com/v2/tags.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reloaded.Get("https://api.feedbin.com/v2/tags.json"); ok {
+		t.Error("Get returned a corrupt entry")
+	}
+
+	// Clear removes responses and leftover temporary files
+	if err := os.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Clear left %d files", len(entries))
+	}
+}
+
+func TestFileCache_Eviction(t *testing.T) {
+	dir := t.TempDir()
+	response := func(s string) *CachedResponse {
+		return &CachedResponse{Data: []byte(strings.Repeat(s, 100))}
+	}
+
+	// Size the cache to hold two responses
+	probe, err := NewFileCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe.Set("a", response("a"))
+	fileSize := probe.Size()
+
+	cache, err := NewFileCache(dir, 2*fileSize+fileSize/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set("a", response("a"))
+	time.Sleep(10 * time.Millisecond)
+	cache.Set("b", response("b"))
+	time.Sleep(10 * time.Millisecond)
+
+	// Using a makes b the least recently used response
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Get(a) found nothing")
+	}
+	time.Sleep(10 * time.Millisecond)
+	cache.Set("c", response("c"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+	if cache.Size() != 2*fileSize {
+		t.Errorf("Size = %d, want %d", cache.Size(), 2*fileSize)
+	}
+
+	// Reopening with a lower limit evicts down to it
+	smaller, err := NewFileCache(dir, fileSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if smaller.Size() != fileSize {
+		t.Errorf("Size after reopening = %d, want %d", smaller.Size(), fileSize)
+	}
+	if _, ok := smaller.Get("c"); !ok {
+		t.Error("the most recently used response should have been kept")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/subscriptions.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":1,"feed_id":2,"title":"Example"}]`))
+	})
+
+	cache, err := NewFileCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, mux, &Config{Cache: cache})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		subs, _, err := client.GetSubscriptions(ctx, nil)
+		if err != nil {
+			t.Fatalf("GetSubscriptions returned error: %v", err)
+		}
+		if len(subs) != 1 || subs[0].Title != "Example" {
+			t.Errorf("GetSubscriptions = %+v", subs)
+		}
+	}
+
+	want := CacheStats{Hits: 2, Misses: 1, Revalidations: 2}
+	if stats := client.CacheStats(); stats != want {
+		t.Errorf("CacheStats = %+v, want %+v", stats, want)
+	}
+}
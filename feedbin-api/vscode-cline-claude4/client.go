@@ -55,6 +55,7 @@ type Client struct {
 	password   string
 	userAgent  string
 	cache      CacheManager
+	stats      cacheCounters
 }
 
 // Config holds configuration options for the Feedbin client
@@ -74,8 +75,12 @@ type Config struct {
 	// UserAgent is a custom user agent string (optional)
 	UserAgent string
 
-	// EnableCache enables HTTP caching with ETag/Last-Modified headers
+	// EnableCache enables HTTP caching with ETag/Last-Modified headers,
+	// using an LRUCache of DefaultCacheMaxBytes unless Cache is set
 	EnableCache bool
+
+	// Cache is a custom cache, such as a FileCache (optional, enables caching)
+	Cache CacheManager
 }
 
 // NewClient creates a new Feedbin API client with the given configuration
@@ -110,9 +115,9 @@ func NewClient(config *Config) *Client {
 		userAgent = DefaultUserAgent
 	}
 
-	var cache CacheManager
-	if config.EnableCache {
-		cache = NewMemoryCache()
+	cache := config.Cache
+	if cache == nil && config.EnableCache {
+		cache = NewLRUCache(DefaultCacheMaxBytes)
 	}
 
 	return &Client{
@@ -170,8 +175,10 @@ func (c *Client) newRequest(ctx context.Context, method, path string, params url
 // doRequest executes an HTTP request and handles the response
 func (c *Client) doRequest(req *http.Request, result interface{}) (*http.Response, error) {
 	// Check cache for GET requests
+	var cached *CachedResponse
 	if req.Method == http.MethodGet && c.cache != nil {
-		if cached, found := c.cache.Get(req.URL.String()); found {
+		if found, ok := c.cacheGet(req.URL.String()); ok {
+			cached = found
 			if cached.ETag != "" {
 				req.Header.Set("If-None-Match", cached.ETag)
 			}
@@ -188,13 +195,12 @@ func (c *Client) doRequest(req *http.Request, result interface{}) (*http.Respons
 	defer resp.Body.Close()
 
 	// Handle 304 Not Modified
-	if resp.StatusCode == http.StatusNotModified && c.cache != nil {
-		if cached, found := c.cache.Get(req.URL.String()); found {
-			if result != nil {
-				return resp, json.Unmarshal(cached.Data, result)
-			}
-			return resp, nil
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.stats.revalidations.Add(1)
+		if result != nil {
+			return resp, json.Unmarshal(cached.Data, result)
 		}
+		return resp, nil
 	}
 
 	// Read response body
@@ -56,32 +56,20 @@ func (c *Client) GetFeedEntries(ctx context.Context, feedID int, opts *EntryOpti
 }
 
 // GetEntriesByIDs retrieves specific entries by their IDs.
-// Maximum of 100 entries can be requested at once.
+// Any number of IDs can be given; they are fetched in batches of
+// MaxEntriesPerRequest using HydrateEntries. Entries are returned in the
+// order of ids and IDs the server did not return are skipped.
 func (c *Client) GetEntriesByIDs(ctx context.Context, ids []int) ([]Entry, error) {
 	if len(ids) == 0 {
 		return []Entry{}, nil
 	}
 
-	if len(ids) > MaxEntriesPerRequest {
-		return nil, fmt.Errorf("too many entry IDs: maximum %d allowed, got %d", MaxEntriesPerRequest, len(ids))
-	}
-
-	// Convert IDs to comma-separated string
-	idStrings := make([]string, len(ids))
-	for i, id := range ids {
-		idStrings[i] = strconv.Itoa(id)
-	}
-
-	params := url.Values{}
-	params.Set("ids", strings.Join(idStrings, ","))
-
-	var entries []Entry
-	_, err := c.get(ctx, "entries.json", params, &entries)
+	result, err := c.HydrateEntries(ctx, ids, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return entries, nil
+	return result.Entries, nil
 }
 
 // GetEntriesExtended retrieves entries with extended metadata.
@@ -0,0 +1,289 @@
+package feedbin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHydrationWorkers is the default number of concurrent requests used by HydrateEntries
+const DefaultHydrationWorkers = 4
+
+// DefaultHydrationMaxAge is how long HydrateEntries reuses cached entries by default
+const DefaultHydrationMaxAge = 15 * time.Minute
+
+// hydrationKeyPrefix keeps hydrated entries apart from cached HTTP responses
+const hydrationKeyPrefix = "hydrate:"
+
+// HydrateOptions holds options for entry hydration
+type HydrateOptions struct {
+	// Workers is the number of concurrent requests (optional, defaults to DefaultHydrationWorkers)
+	Workers int
+
+	// BatchSize is the number of IDs per request (optional, capped at MaxEntriesPerRequest)
+	BatchSize int
+
+	// Mode is passed to the API as the mode parameter, e.g. "extended" (optional)
+	Mode string
+
+	// IncludeOriginal, IncludeEnclosure and IncludeContentDiff are passed to the API
+	IncludeOriginal    bool
+	IncludeEnclosure   bool
+	IncludeContentDiff bool
+
+	// MaxAge is how long cached entries are reused before they are fetched
+	// again (optional, defaults to DefaultHydrationMaxAge). A negative value
+	// always fetches the entries.
+	MaxAge time.Duration
+}
+
+// HydrateResult holds the outcome of HydrateEntries
+type HydrateResult struct {
+	// Entries are the entries that were found, in the order their IDs were given
+	Entries []Entry
+
+	// MissingIDs are the requested IDs the server did not return
+	MissingIDs []int
+
+	// FailedIDs are the requested IDs whose request failed or was never sent
+	// because of an earlier error or cancellation. They may still exist.
+	FailedIDs []int
+
+	// CachedCount is the number of entries served from the client's cache
+	CachedCount int
+}
+
+// HydrateEntries retrieves full entries for any number of IDs, such as the
+// results of GetUnreadEntries or GetStarredEntries.
+//
+// The IDs are split into requests of at most MaxEntriesPerRequest IDs that run
+// on a pool of workers. Duplicate IDs are fetched once. When caching is
+// enabled, entries hydrated less than MaxAge ago are not requested again and
+// fetched entries are added to the cache. These lookups are not counted in
+// CacheStats.
+//
+// If a request fails, the remaining requests are cancelled and the error is
+// returned together with the entries retrieved so far; the IDs that were not
+// retrieved are reported in FailedIDs.
+func (c *Client) HydrateEntries(ctx context.Context, ids []int, opts *HydrateOptions) (*HydrateResult, error) {
+	if opts == nil {
+		opts = &HydrateOptions{}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultHydrationWorkers
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 || batchSize > MaxEntriesPerRequest {
+		batchSize = MaxEntriesPerRequest
+	}
+
+	// Keep the caller's order and drop duplicates
+	order := make([]int, 0, len(ids))
+	found := make(map[int]Entry, len(ids))
+	fromCache := make(map[int]bool)
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			order = append(order, id)
+		}
+	}
+
+	result := &HydrateResult{Entries: []Entry{}}
+
+	// Skip entries that are already cached
+	var toFetch []int
+	for _, id := range order {
+		if entry, ok := c.cachedEntry(id, opts); ok {
+			found[id] = entry
+			fromCache[id] = true
+			result.CachedCount++
+			continue
+		}
+		toFetch = append(toFetch, id)
+	}
+
+	var batches [][]int
+	for start := 0; start < len(toFetch); start += batchSize {
+		end := start + batchSize
+		if end > len(toFetch) {
+			end = len(toFetch)
+		}
+		batches = append(batches, toFetch[start:end])
+	}
+
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan []int)
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		answered = make(map[int]bool, len(toFetch))
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				// Drain the remaining batches without sending them once
+				// hydration is cancelled
+				if ctx.Err() != nil {
+					continue
+				}
+				entries, err := c.fetchEntriesBatch(ctx, batch, opts)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					for _, entry := range entries {
+						found[entry.ID] = entry
+					}
+					for _, id := range batch {
+						answered[id] = true
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, batch := range batches {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- batch:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	now := time.Now()
+	for _, id := range order {
+		entry, ok := found[id]
+		switch {
+		case ok:
+			result.Entries = append(result.Entries, entry)
+			if !fromCache[id] {
+				c.cacheEntry(entry, opts, now)
+			}
+		case answered[id]:
+			result.MissingIDs = append(result.MissingIDs, id)
+		default:
+			result.FailedIDs = append(result.FailedIDs, id)
+		}
+	}
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return result, fmt.Errorf("failed to hydrate entries: %w", firstErr)
+	}
+
+	return result, nil
+}
+
+// fetchEntriesBatch retrieves a single batch of at most MaxEntriesPerRequest entries
+func (c *Client) fetchEntriesBatch(ctx context.Context, ids []int, opts *HydrateOptions) ([]Entry, error) {
+	params := c.buildEntryParams(opts.entryOptions(ids))
+
+	var entries []Entry
+	_, err := c.get(ctx, "entries.json", params, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// entryOptions converts hydration options to EntryOptions for the given IDs
+func (opts *HydrateOptions) entryOptions(ids []int) *EntryOptions {
+	return &EntryOptions{
+		IDs:                ids,
+		Mode:               opts.Mode,
+		IncludeOriginal:    opts.IncludeOriginal,
+		IncludeEnclosure:   opts.IncludeEnclosure,
+		IncludeContentDiff: opts.IncludeContentDiff,
+	}
+}
+
+// hydratedEntry is the cached form of an entry fetched by HydrateEntries
+type hydratedEntry struct {
+	Entry     Entry     `json:"entry"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// maxAge returns the effective cache lifetime of hydrated entries
+func (opts *HydrateOptions) maxAge() time.Duration {
+	if opts.MaxAge == 0 {
+		return DefaultHydrationMaxAge
+	}
+	return opts.MaxAge
+}
+
+// entryCacheKey returns the cache key of a hydrated entry. Hydrated entries
+// have no validators, so they are kept apart from the responses of GetEntry,
+// which doRequest revalidates.
+func (c *Client) entryCacheKey(id int, opts *HydrateOptions) string {
+	params := c.buildEntryParams(opts.entryOptions(nil))
+	if len(params) == 0 {
+		params = nil
+	}
+
+	return hydrationKeyPrefix + c.buildURL(fmt.Sprintf("entries/%d.json", id), params)
+}
+
+// cachedEntry returns an entry hydrated less than MaxAge ago from the
+// client's cache, if caching is enabled
+func (c *Client) cachedEntry(id int, opts *HydrateOptions) (Entry, bool) {
+	if c.cache == nil || opts.maxAge() < 0 {
+		return Entry{}, false
+	}
+
+	cached, found := c.cache.Get(c.entryCacheKey(id, opts))
+	if !found {
+		return Entry{}, false
+	}
+
+	var hydrated hydratedEntry
+	if err := json.Unmarshal(cached.Data, &hydrated); err != nil {
+		return Entry{}, false
+	}
+	if time.Since(hydrated.FetchedAt) >= opts.maxAge() {
+		return Entry{}, false
+	}
+
+	return hydrated.Entry, true
+}
+
+// cacheEntry stores an entry in the client's cache, if caching is enabled
+func (c *Client) cacheEntry(entry Entry, opts *HydrateOptions, fetchedAt time.Time) {
+	if c.cache == nil || opts.maxAge() < 0 {
+		return
+	}
+
+	data, err := json.Marshal(hydratedEntry{Entry: entry, FetchedAt: fetchedAt})
+	if err != nil {
+		return
+	}
+
+	c.cache.Set(c.entryCacheKey(entry.ID, opts), &CachedResponse{Data: data})
+}
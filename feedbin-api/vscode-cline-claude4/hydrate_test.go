@@ -0,0 +1,218 @@
+package feedbin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// entriesServer answers entries.json?ids=... with the requested entries in
+// reverse order, leaving out the IDs in missing, and records the size of
+// each request
+type entriesServer struct {
+	mu      sync.Mutex
+	batches []int
+	missing map[int]bool
+	handle  func(r *http.Request) // Called before answering (optional)
+}
+
+func (s *entriesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var ids []int
+	if raw := r.URL.Query().Get("ids"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			id, _ := strconv.Atoi(field)
+			ids = append(ids, id)
+		}
+	} else if strings.HasPrefix(r.URL.Path, "/v2/entries/") {
+		id, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v2/entries/"), ".json"))
+		ids = []int{id}
+	}
+
+	s.mu.Lock()
+	s.batches = append(s.batches, len(ids))
+	s.mu.Unlock()
+
+	if s.handle != nil {
+		s.handle(r)
+	}
+
+	entries := []Entry{}
+	for i := len(ids) - 1; i >= 0; i-- {
+		if !s.missing[ids[i]] {
+			entries = append(entries, Entry{ID: ids[i], FeedID: 1})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasPrefix(r.URL.Path, "/v2/entries/") {
+		json.NewEncoder(w).Encode(entries[0])
+		return
+	}
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (s *entriesServer) requests() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batches := append([]int(nil), s.batches...)
+	sort.Ints(batches)
+	return batches
+}
+
+func newTestClient(t *testing.T, handler http.Handler, config *Config) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = &Config{}
+	}
+	config.Username = "user@example.com"
+	config.Password = "password"
+	config.BaseURL = server.URL + "/v2/"
+	return NewClient(config)
+}
+
+func entryIDs(entries []Entry) []int {
+	ids := make([]int, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func TestHydrateEntries(t *testing.T) {
+	server := &entriesServer{missing: map[int]bool{42: true, 200: true}}
+	client := newTestClient(t, server, nil)
+
+	// 250 unique IDs in descending order, with duplicates
+	var ids, want []int
+	for id := 250; id >= 1; id-- {
+		ids = append(ids, id)
+		if !server.missing[id] {
+			want = append(want, id)
+		}
+	}
+	ids = append(ids, 7, 250, 42)
+
+	result, err := client.HydrateEntries(context.Background(), ids, &HydrateOptions{Workers: 3})
+	if err != nil {
+		t.Fatalf("HydrateEntries returned error: %v", err)
+	}
+
+	if got := entryIDs(result.Entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("HydrateEntries returned entries in order %v, want %v", got, want)
+	}
+	if want := []int{200, 42}; !reflect.DeepEqual(result.MissingIDs, want) {
+		t.Errorf("MissingIDs = %v, want %v", result.MissingIDs, want)
+	}
+	if len(result.FailedIDs) != 0 {
+		t.Errorf("FailedIDs = %v, want none", result.FailedIDs)
+	}
+	if got, want := server.requests(), []int{50, 100, 100}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HydrateEntries sent batches of %v IDs, want %v", got, want)
+	}
+}
+
+func TestHydrateEntries_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := &entriesServer{handle: func(*http.Request) { cancel() }}
+	client := newTestClient(t, server, nil)
+
+	ids := make([]int, 250)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	result, err := client.HydrateEntries(ctx, ids, &HydrateOptions{Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("HydrateEntries returned error %v, want context.Canceled", err)
+	}
+
+	if got := server.requests(); len(got) != 1 {
+		t.Errorf("HydrateEntries sent %d requests after cancellation, want 1", len(got))
+	}
+	if len(result.MissingIDs) != 0 {
+		t.Errorf("MissingIDs = %v, want none for unsent batches", result.MissingIDs)
+	}
+	if got := append(entryIDs(result.Entries), result.FailedIDs...); !reflect.DeepEqual(got, ids) {
+		t.Errorf("Entries and FailedIDs = %v, want every requested ID once", got)
+	}
+	if len(result.FailedIDs) < 150 || result.FailedIDs[len(result.FailedIDs)-1] != 250 {
+		t.Errorf("FailedIDs = %v, want at least the unsent IDs 101-250", result.FailedIDs)
+	}
+}
+
+func TestHydrateEntries_Error(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/entries.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"unavailable"}`, http.StatusServiceUnavailable)
+	})
+	client := newTestClient(t, mux, nil)
+
+	result, err := client.HydrateEntries(context.Background(), []int{3, 1, 2}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("HydrateEntries returned error %v, want a 503 APIError", err)
+	}
+	if want := []int{3, 1, 2}; !reflect.DeepEqual(result.FailedIDs, want) {
+		t.Errorf("FailedIDs = %v, want %v", result.FailedIDs, want)
+	}
+	if len(result.MissingIDs) != 0 {
+		t.Errorf("MissingIDs = %v, want none", result.MissingIDs)
+	}
+}
+
+func TestHydrateEntries_Cache(t *testing.T) {
+	server := &entriesServer{}
+	client := newTestClient(t, server, &Config{EnableCache: true})
+	ctx := context.Background()
+
+	if _, err := client.HydrateEntries(ctx, []int{1, 2}, nil); err != nil {
+		t.Fatalf("HydrateEntries returned error: %v", err)
+	}
+
+	result, err := client.HydrateEntries(ctx, []int{2, 1, 3}, nil)
+	if err != nil {
+		t.Fatalf("HydrateEntries returned error: %v", err)
+	}
+	if result.CachedCount != 2 {
+		t.Errorf("CachedCount = %d, want 2", result.CachedCount)
+	}
+	if got := server.requests(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("HydrateEntries sent batches of %v IDs, want only the uncached entry", got)
+	}
+	// Only the two batch requests count, not the lookups of hydrated entries
+	if stats := client.CacheStats(); stats != (CacheStats{Misses: 2}) {
+		t.Errorf("CacheStats = %+v, want only the batch requests counted", stats)
+	}
+
+	// Hydrated entries are not served in place of GetEntry responses
+	if _, err := client.GetEntry(ctx, 1); err != nil {
+		t.Fatalf("GetEntry returned error: %v", err)
+	}
+	if got := len(server.requests()); got != 3 {
+		t.Errorf("GetEntry was answered from hydrated entries")
+	}
+
+	// A negative MaxAge always fetches
+	result, err = client.HydrateEntries(ctx, []int{1, 2}, &HydrateOptions{MaxAge: -1})
+	if err != nil {
+		t.Fatalf("HydrateEntries returned error: %v", err)
+	}
+	if result.CachedCount != 0 || len(server.requests()) != 4 {
+		t.Errorf("HydrateEntries with a negative MaxAge used the cache")
+	}
+}
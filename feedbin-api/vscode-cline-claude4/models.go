@@ -2,6 +2,7 @@ package feedbin
 
 import (
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -193,13 +194,15 @@ type CacheManager interface {
 
 // CachedResponse represents a cached HTTP response
 type CachedResponse struct {
-	Data         []byte
-	ETag         string
-	LastModified time.Time
+	Data         []byte    `json:"data"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"last_modified,omitempty"`
 }
 
-// MemoryCache implements CacheManager using in-memory storage
+// MemoryCache implements CacheManager using unbounded in-memory storage.
+// It is safe for concurrent use. See LRUCache for a size-bounded cache.
 type MemoryCache struct {
+	mu    sync.RWMutex
 	cache map[string]*CachedResponse
 }
 
@@ -212,12 +215,18 @@ func NewMemoryCache() *MemoryCache {
 
 // Get retrieves a cached response
 func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	response, found := m.cache[key]
 	return response, found
 }
 
 // Set stores a response in the cache
 func (m *MemoryCache) Set(key string, response *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.cache[key] = response
 }
 
@@ -273,6 +282,11 @@ type UnreadEntriesRequest struct {
 	UnreadEntries []int `json:"unread_entries"`
 }
 
+// RecentlyReadEntriesRequest represents a request to create recently read entries
+type RecentlyReadEntriesRequest struct {
+	RecentlyReadEntries []int `json:"recently_read_entries"`
+}
+
 // StarredEntriesRequest represents a request to star entries
 type StarredEntriesRequest struct {
 	StarredEntries []int `json:"starred_entries"`
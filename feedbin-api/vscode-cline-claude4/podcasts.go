@@ -0,0 +1,671 @@
+package feedbin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPodcastPlaylistName is the file name of the playlist written by
+// SyncPodcasts, without its extension.
+const DefaultPodcastPlaylistName = "podcasts"
+
+// partialSuffix is appended to the file name of unfinished downloads.
+const partialSuffix = ".part"
+
+// maxSlugLength is the maximum length of the title part of episode file names.
+const maxSlugLength = 60
+
+// PodcastEpisode is an entry with an audio or video enclosure.
+type PodcastEpisode struct {
+	Entry Entry
+
+	// URL and Type are the enclosure URL and MIME type
+	URL  string
+	Type string
+
+	// Length is the enclosure_length in bytes, or 0 when the feed does not give it
+	Length int64
+
+	// Duration is the parsed itunes_duration, or 0 when the feed does not give it
+	Duration time.Duration
+}
+
+// NewPodcastEpisode returns the episode of an entry fetched with
+// include_enclosure or in extended mode. It returns false if the entry has
+// no enclosure URL.
+func NewPodcastEpisode(entry Entry) (PodcastEpisode, bool) {
+	if entry.Enclosure == nil || strings.TrimSpace(entry.Enclosure.EnclosureURL) == "" {
+		return PodcastEpisode{}, false
+	}
+
+	episode := PodcastEpisode{
+		Entry:    entry,
+		URL:      strings.TrimSpace(entry.Enclosure.EnclosureURL),
+		Type:     strings.TrimSpace(entry.Enclosure.EnclosureType),
+		Duration: parseItunesDuration(entry.Enclosure.ItunesDuration),
+	}
+	if length, err := strconv.ParseInt(strings.TrimSpace(entry.Enclosure.EnclosureLength), 10, 64); err == nil && length > 0 {
+		episode.Length = length
+	}
+
+	return episode, true
+}
+
+// Title returns the entry title, or the enclosure file name for untitled entries.
+func (e *PodcastEpisode) Title() string {
+	if e.Entry.Title != nil && strings.TrimSpace(*e.Entry.Title) != "" {
+		return strings.Join(strings.Fields(*e.Entry.Title), " ")
+	}
+	return path.Base(enclosurePath(e.URL))
+}
+
+// FileName returns the local file name of the episode. It starts with the
+// entry ID so that it stays stable across runs, and uses only ASCII letters,
+// digits and dashes because many car stereos and media players cannot show
+// other characters.
+func (e *PodcastEpisode) FileName() string {
+	name := strconv.Itoa(e.Entry.ID)
+	if slug := slugify(e.Title()); slug != "" {
+		name += "-" + slug
+	}
+	return name + e.extension()
+}
+
+// extension returns the file extension of the enclosure, taken from its URL
+// or else from its MIME type.
+func (e *PodcastEpisode) extension() string {
+	ext := strings.ToLower(path.Ext(enclosurePath(e.URL)))
+	if len(ext) >= 2 && len(ext) <= 5 && slugify(ext[1:]) == ext[1:] {
+		return ext
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(e.Type)
+	switch mediaType {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/x-m4a", "audio/m4a":
+		return ".m4a"
+	case "video/mp4":
+		return ".mp4"
+	case "audio/ogg":
+		return ".ogg"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// enclosurePath returns the path of an enclosure URL without its query.
+func enclosurePath(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	return strings.TrimSuffix(rawURL, "/")
+}
+
+// asciiFolds maps accented Latin letters to the ASCII letters they are based on.
+var asciiFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i",
+	'î': "i", 'ï': "i", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o",
+	'ö': "o", 'ø': "o", 'œ': "oe", 'ß': "ss", 'ù': "u", 'ú': "u", 'û': "u",
+	'ü': "u", 'ý': "y", 'ÿ': "y",
+}
+
+// slugify lowercases s, folds accented letters to ASCII and replaces
+// everything but ASCII letters and digits with single dashes.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if fold, ok := asciiFolds[r]; ok {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteString(fold)
+			continue
+		}
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			if b.Len() >= maxSlugLength {
+				break
+			}
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// parseItunesDuration parses an itunes_duration given as seconds, MM:SS or
+// HH:MM:SS. It returns 0 for values it cannot parse.
+func parseItunesDuration(s string) time.Duration {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+
+	var seconds float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 {
+			return 0
+		}
+		// Only the seconds may have a fraction
+		if i < len(parts)-1 && value != float64(int64(value)) {
+			return 0
+		}
+		seconds = seconds*60 + value
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// GetUnreadPodcastEpisodes retrieves the unread entries that have an
+// enclosure, fetched in extended mode, ordered from oldest to newest.
+func (c *Client) GetUnreadPodcastEpisodes(ctx context.Context) ([]PodcastEpisode, error) {
+	unreadIDs, err := c.GetUnreadEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread entries: %w", err)
+	}
+
+	result, err := c.HydrateEntries(ctx, unreadIDs, &HydrateOptions{
+		Mode:             "extended",
+		IncludeEnclosure: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unread entries: %w", err)
+	}
+
+	episodes := []PodcastEpisode{}
+	for _, entry := range result.Entries {
+		if episode, ok := NewPodcastEpisode(entry); ok {
+			episodes = append(episodes, episode)
+		}
+	}
+
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Entry.Published.Before(episodes[j].Entry.Published)
+	})
+
+	return episodes, nil
+}
+
+// PodcastDownloadOptions holds options for downloading episodes
+type PodcastDownloadOptions struct {
+	// Dir is the directory the episodes are saved in (required)
+	Dir string
+
+	// HTTPClient is used for the downloads (optional). The default shares the
+	// client's transport but has no timeout, so use the context to bound a download.
+	HTTPClient *http.Client
+}
+
+// PodcastDownload is a downloaded episode
+type PodcastDownload struct {
+	Episode PodcastEpisode
+
+	// Path is the path of the downloaded file
+	Path string
+
+	// Size is the size of the file in bytes
+	Size int64
+
+	// Resumed reports whether a partial download was continued
+	Resumed bool
+
+	// Existing reports whether the file was already complete and nothing was downloaded
+	Existing bool
+
+	// LengthMismatch is set when the size of the file differs from the
+	// enclosure_length of the episode. The file is kept, since feeds often
+	// publish wrong or stale lengths.
+	LengthMismatch *EnclosureLengthError
+}
+
+// EnclosureLengthError describes a downloaded enclosure that does not have
+// the size given by enclosure_length. It is reported on PodcastDownload.
+type EnclosureLengthError struct {
+	EntryID  int
+	Expected int64
+	Actual   int64
+}
+
+// Error implements the error interface
+func (e *EnclosureLengthError) Error() string {
+	return fmt.Sprintf("enclosure of entry %d is %d bytes, expected %d", e.EntryID, e.Actual, e.Expected)
+}
+
+// DownloadPodcastEpisode downloads the enclosure of an episode into opts.Dir.
+//
+// The file is written with a ".part" suffix and renamed once complete. An
+// unfinished download is resumed with an HTTP range request; servers that
+// ignore the range send the whole file again. A download fails only when the
+// response is shorter than its Content-Length or Content-Range say, and the
+// partial file is then kept for the next attempt. A size that differs from
+// the enclosure_length is reported in LengthMismatch. Files that are already
+// complete are not downloaded again.
+//
+// Feedbin credentials are never sent, since enclosures are hosted elsewhere.
+func (c *Client) DownloadPodcastEpisode(ctx context.Context, episode PodcastEpisode, opts *PodcastDownloadOptions) (*PodcastDownload, error) {
+	if opts == nil || opts.Dir == "" {
+		return nil, &ValidationError{Field: "Dir", Message: "download directory is required"}
+	}
+
+	download := &PodcastDownload{
+		Episode: episode,
+		Path:    filepath.Join(opts.Dir, episode.FileName()),
+	}
+
+	// Only complete downloads are renamed to the download path
+	if info, err := os.Stat(download.Path); err == nil && info.Mode().IsRegular() {
+		download.Size = info.Size()
+		download.Existing = true
+		download.LengthMismatch = checkEnclosureLength(episode, info.Size())
+		return download, nil
+	}
+
+	partial := download.Path + partialSuffix
+	var offset int64
+	if info, err := os.Stat(partial); err == nil && info.Mode().IsRegular() {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, episode.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: c.httpClient.Transport}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download of entry %d failed: %w", episode.Entry.ID, err)
+	}
+	defer resp.Body.Close()
+
+	// total is the size of the whole enclosure according to the server, or
+	// -1 when it is not known
+	flags := os.O_WRONLY | os.O_CREATE
+	total := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+		total = resp.ContentLength
+	case http.StatusPartialContent:
+		start, end, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			os.Remove(partial)
+			return nil, fmt.Errorf("download of entry %d failed: unexpected Content-Range %q", episode.Entry.ID, resp.Header.Get("Content-Range"))
+		}
+		download.Resumed = true
+		flags |= os.O_APPEND
+		total = size
+		if total < 0 {
+			// Without a total, the range must at least be sent in full
+			total = end + 1
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file may already hold the whole enclosure
+		_, _, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if offset == 0 || err != nil || size != offset {
+			os.Remove(partial)
+			return nil, fmt.Errorf("download of entry %d failed: range not satisfiable, partial file removed", episode.Entry.ID)
+		}
+		download.Resumed = true
+		return download, finishDownload(download, partial, offset, size)
+	default:
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("download of entry %d failed: %s", episode.Entry.ID, resp.Status),
+			Response:   resp,
+			Retryable:  resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	file, err := os.OpenFile(partial, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download file: %w", err)
+	}
+
+	written, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Keep the partial file so the next attempt can resume it
+		return nil, fmt.Errorf("download of entry %d failed: %w", episode.Entry.ID, err)
+	}
+
+	return download, finishDownload(download, partial, offset+written, total)
+}
+
+// finishDownload moves a downloaded partial file to the download path. The
+// file is kept as a partial file when it is shorter or longer than total,
+// the size the server gave, so the next attempt can resume or restart it.
+func finishDownload(download *PodcastDownload, partial string, size, total int64) error {
+	if total >= 0 && size != total {
+		return fmt.Errorf("download of entry %d failed: received %d of %d bytes", download.Episode.Entry.ID, size, total)
+	}
+
+	if err := os.Rename(partial, download.Path); err != nil {
+		return fmt.Errorf("failed to save download: %w", err)
+	}
+
+	download.Size = size
+	download.LengthMismatch = checkEnclosureLength(download.Episode, size)
+	return nil
+}
+
+// checkEnclosureLength returns an EnclosureLengthError if size differs from
+// the enclosure_length of the episode.
+func checkEnclosureLength(episode PodcastEpisode, size int64) *EnclosureLengthError {
+	if episode.Length <= 0 || size == episode.Length {
+		return nil
+	}
+	return &EnclosureLengthError{EntryID: episode.Entry.ID, Expected: episode.Length, Actual: size}
+}
+
+// parseContentRange parses a Content-Range header of the form
+// "bytes start-end/total" or "bytes */total". The end is -1 for "*" and the
+// total is -1 when unknown.
+func parseContentRange(header string) (start, end, total int64, err error) {
+	spec := strings.TrimPrefix(strings.TrimSpace(header), "bytes ")
+	slash := strings.IndexByte(spec, '/')
+	if slash < 0 {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+
+	total = -1
+	if size := spec[slash+1:]; size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+		}
+	}
+
+	rangeSpec := spec[:slash]
+	if rangeSpec == "*" {
+		return 0, -1, total, nil
+	}
+	dash := strings.IndexByte(rangeSpec, '-')
+	if dash < 0 {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if start, err = strconv.ParseInt(rangeSpec[:dash], 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if end, err = strconv.ParseInt(rangeSpec[dash+1:], 10, 64); err != nil || end < start {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+
+	return start, end, total, nil
+}
+
+// PodcastPlaylistFormat is the file format of a podcast playlist
+type PodcastPlaylistFormat string
+
+const (
+	// PodcastPlaylistM3U is an extended M3U playlist
+	PodcastPlaylistM3U PodcastPlaylistFormat = "m3u"
+
+	// PodcastPlaylistJSON is a JSON array of PodcastPlaylistItem
+	PodcastPlaylistJSON PodcastPlaylistFormat = "json"
+)
+
+// PodcastPlaylistItem is an episode in a JSON playlist
+type PodcastPlaylistItem struct {
+	EntryID   int       `json:"entry_id"`
+	FeedID    int       `json:"feed_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author,omitempty"`
+	File      string    `json:"file"`
+	Size      int64     `json:"size"`
+	Duration  int       `json:"duration,omitempty"`
+	URL       string    `json:"url"`
+	EntryURL  string    `json:"entry_url,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Published time.Time `json:"published"`
+}
+
+// WriteM3UPlaylist writes the downloads as an extended M3U playlist. Files
+// are referenced by name, so the playlist must be saved in the download
+// directory.
+func WriteM3UPlaylist(w io.Writer, downloads []PodcastDownload) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, download := range downloads {
+		seconds := -1
+		if download.Episode.Duration > 0 {
+			seconds = int(download.Episode.Duration.Round(time.Second) / time.Second)
+		}
+
+		title := download.Episode.Title()
+		if download.Episode.Entry.Author != nil {
+			if author := strings.Join(strings.Fields(*download.Episode.Entry.Author), " "); author != "" {
+				title = author + " - " + title
+			}
+		}
+
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", seconds, title, filepath.Base(download.Path))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSONPlaylist writes the downloads as a JSON array of PodcastPlaylistItem.
+// Files are referenced by name, like in WriteM3UPlaylist.
+func WriteJSONPlaylist(w io.Writer, downloads []PodcastDownload) error {
+	items := make([]PodcastPlaylistItem, 0, len(downloads))
+	for _, download := range downloads {
+		episode := download.Episode
+		item := PodcastPlaylistItem{
+			EntryID:   episode.Entry.ID,
+			FeedID:    episode.Entry.FeedID,
+			Title:     episode.Title(),
+			File:      filepath.Base(download.Path),
+			Size:      download.Size,
+			Duration:  int(episode.Duration.Round(time.Second) / time.Second),
+			URL:       episode.URL,
+			EntryURL:  episode.Entry.URL,
+			Published: episode.Entry.Published,
+		}
+		if episode.Entry.Enclosure != nil {
+			item.Image = episode.Entry.Enclosure.ItunesImage
+		}
+		if episode.Entry.Author != nil {
+			item.Author = strings.TrimSpace(*episode.Entry.Author)
+		}
+		items = append(items, item)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+// PodcastSyncOptions holds options for SyncPodcasts
+type PodcastSyncOptions struct {
+	// Dir is the directory the episodes and playlist are saved in (required)
+	Dir string
+
+	// PlaylistName is the playlist file name without extension (optional,
+	// defaults to DefaultPodcastPlaylistName)
+	PlaylistName string
+
+	// PlaylistFormat is the playlist format (optional, defaults to PodcastPlaylistM3U)
+	PlaylistFormat PodcastPlaylistFormat
+
+	// HTTPClient is used for the downloads (optional, see PodcastDownloadOptions)
+	HTTPClient *http.Client
+
+	// SkipRecentlyRead disables marking downloaded episodes as recently read
+	SkipRecentlyRead bool
+}
+
+// PodcastSyncResult holds the outcome of SyncPodcasts
+type PodcastSyncResult struct {
+	// Downloads are the episodes in the playlist, from oldest to newest
+	Downloads []PodcastDownload
+
+	// Errors holds the download errors by entry ID
+	Errors map[int]error
+
+	// PlaylistPath is the path of the written playlist
+	PlaylistPath string
+
+	// MarkedRecentlyRead are the entry IDs that were marked as recently read
+	MarkedRecentlyRead []int
+}
+
+// SyncPodcasts downloads the enclosures of all unread podcast episodes into
+// opts.Dir, writes a playlist of the episodes there and marks the episodes
+// downloaded in this run as recently read.
+//
+// Episodes that fail to download are reported in the result's Errors and
+// left out of the playlist, and the sync continues with the next episode.
+// Partial downloads are resumed by the next sync. An error is returned if
+// the episodes cannot be listed, the playlist cannot be written, marking
+// fails or ctx is done, together with the result so far.
+func (c *Client) SyncPodcasts(ctx context.Context, opts *PodcastSyncOptions) (*PodcastSyncResult, error) {
+	if opts == nil || opts.Dir == "" {
+		return nil, &ValidationError{Field: "Dir", Message: "download directory is required"}
+	}
+
+	format := opts.PlaylistFormat
+	if format == "" {
+		format = PodcastPlaylistM3U
+	}
+	if format != PodcastPlaylistM3U && format != PodcastPlaylistJSON {
+		return nil, &ValidationError{Field: "PlaylistFormat", Message: "unsupported playlist format", Value: format}
+	}
+
+	playlistName := opts.PlaylistName
+	if playlistName == "" {
+		playlistName = DefaultPodcastPlaylistName
+	}
+
+	episodes, err := c.GetUnreadPodcastEpisodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	result := &PodcastSyncResult{
+		Downloads:    []PodcastDownload{},
+		Errors:       make(map[int]error),
+		PlaylistPath: filepath.Join(opts.Dir, playlistName+"."+string(format)),
+	}
+
+	downloadOpts := &PodcastDownloadOptions{
+		Dir:        opts.Dir,
+		HTTPClient: opts.HTTPClient,
+	}
+
+	var downloaded []int
+	for _, episode := range episodes {
+		download, err := c.DownloadPodcastEpisode(ctx, episode, downloadOpts)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return result, ctxErr
+			}
+			result.Errors[episode.Entry.ID] = err
+			continue
+		}
+
+		result.Downloads = append(result.Downloads, *download)
+		if !download.Existing {
+			downloaded = append(downloaded, episode.Entry.ID)
+		}
+	}
+
+	if err := writePlaylist(result.PlaylistPath, format, result.Downloads); err != nil {
+		return result, err
+	}
+
+	if opts.SkipRecentlyRead {
+		return result, nil
+	}
+
+	for i := 0; i < len(downloaded); i += MaxBulkOperationSize {
+		end := i + MaxBulkOperationSize
+		if end > len(downloaded) {
+			end = len(downloaded)
+		}
+
+		marked, err := c.MarkAsRecentlyRead(ctx, downloaded[i:end])
+		if err != nil {
+			return result, fmt.Errorf("failed to mark episodes as recently read: %w", err)
+		}
+		result.MarkedRecentlyRead = append(result.MarkedRecentlyRead, marked...)
+	}
+
+	return result, nil
+}
+
+// writePlaylist writes a playlist to a temporary file and renames it to
+// path, so that players never see a half-written playlist.
+func writePlaylist(path string, format PodcastPlaylistFormat, downloads []PodcastDownload) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".playlist-*")
+	if err != nil {
+		return fmt.Errorf("failed to write playlist: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if format == PodcastPlaylistJSON {
+		err = WriteJSONPlaylist(file, downloads)
+	} else {
+		err = WriteM3UPlaylist(file, downloads)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write playlist: %w", err)
+	}
+
+	return nil
+}
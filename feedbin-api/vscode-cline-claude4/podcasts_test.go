@@ -0,0 +1,298 @@
+package feedbin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// enclosureServer serves an enclosure with range support, recording the
+// Range header of each request. Setting handle replaces the default handler.
+type enclosureServer struct {
+	mu      sync.Mutex
+	content []byte
+	ranges  []string
+	handle  func(w http.ResponseWriter, r *http.Request)
+}
+
+func (s *enclosureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	s.mu.Unlock()
+
+	if s.handle != nil {
+		s.handle(w, r)
+		return
+	}
+	http.ServeContent(w, r, "episode.mp3", time.Time{}, bytes.NewReader(s.content))
+}
+
+func (s *enclosureServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.ranges...)
+}
+
+func newPodcastTest(t *testing.T, length int64) (*Client, *enclosureServer, PodcastEpisode, *PodcastDownloadOptions) {
+	t.Helper()
+
+	server := &enclosureServer{content: []byte(strings.Repeat("0123456789", 10))}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	client := NewClient(&Config{Username: "user@example.com", Password: "password"})
+	title := "Episode One"
+	episode := PodcastEpisode{
+		Entry:  Entry{ID: 7, Title: &title},
+		URL:    httpServer.URL + "/episode.mp3",
+		Type:   "audio/mpeg",
+		Length: length,
+	}
+
+	return client, server, episode, &PodcastDownloadOptions{Dir: t.TempDir()}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestDownloadPodcastEpisode(t *testing.T) {
+	client, server, episode, opts := newPodcastTest(t, 100)
+	ctx := context.Background()
+
+	download, err := client.DownloadPodcastEpisode(ctx, episode, opts)
+	if err != nil {
+		t.Fatalf("DownloadPodcastEpisode returned error: %v", err)
+	}
+	if download.Path != filepath.Join(opts.Dir, "7-episode-one.mp3") || download.Size != 100 {
+		t.Errorf("DownloadPodcastEpisode = %+v", download)
+	}
+	if download.LengthMismatch != nil || download.Resumed || download.Existing {
+		t.Errorf("DownloadPodcastEpisode = %+v, want a plain download", download)
+	}
+	if got := readFile(t, download.Path); got != string(server.content) {
+		t.Errorf("downloaded %q", got)
+	}
+	if _, err := os.Stat(download.Path + partialSuffix); !os.IsNotExist(err) {
+		t.Error("the partial file was left behind")
+	}
+
+	// Complete files are not downloaded again
+	again, err := client.DownloadPodcastEpisode(ctx, episode, opts)
+	if err != nil || !again.Existing || again.Size != 100 {
+		t.Errorf("second DownloadPodcastEpisode = %+v, %v", again, err)
+	}
+	if got := len(server.requests()); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+func TestDownloadPodcastEpisode_LengthMismatch(t *testing.T) {
+	// The feed claims a length the file does not have
+	client, server, episode, opts := newPodcastTest(t, 4096)
+	ctx := context.Background()
+
+	download, err := client.DownloadPodcastEpisode(ctx, episode, opts)
+	if err != nil {
+		t.Fatalf("DownloadPodcastEpisode returned error: %v", err)
+	}
+	want := EnclosureLengthError{EntryID: 7, Expected: 4096, Actual: 100}
+	if download.LengthMismatch == nil || *download.LengthMismatch != want {
+		t.Errorf("LengthMismatch = %v, want %v", download.LengthMismatch, want)
+	}
+	if got := readFile(t, download.Path); got != string(server.content) {
+		t.Errorf("the file was not kept, got %q", got)
+	}
+
+	// The kept file counts as complete on the next run
+	again, err := client.DownloadPodcastEpisode(ctx, episode, opts)
+	if err != nil || !again.Existing || again.LengthMismatch == nil {
+		t.Errorf("second DownloadPodcastEpisode = %+v, %v", again, err)
+	}
+	if got := len(server.requests()); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+func TestDownloadPodcastEpisode_Resume(t *testing.T) {
+	tests := []struct {
+		name     string
+		partial  int
+		handle   func(s *enclosureServer) func(http.ResponseWriter, *http.Request)
+		resumed  bool
+		wantErr  bool
+		keepPart bool
+	}{
+		{name: "206", partial: 40, resumed: true},
+		{name: "416 complete", partial: 100, resumed: true},
+		{name: "416 longer than the enclosure", partial: 120, wantErr: true},
+		{
+			name:    "range ignored",
+			partial: 40,
+			handle: func(s *enclosureServer) func(http.ResponseWriter, *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Write(s.content)
+				}
+			},
+		},
+		{
+			name:    "wrong Content-Range",
+			partial: 40,
+			handle: func(s *enclosureServer) func(http.ResponseWriter, *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Range", "bytes 10-99/100")
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write(s.content[10:])
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "range shorter than the total",
+			partial: 40,
+			handle: func(s *enclosureServer) func(http.ResponseWriter, *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Range", "bytes 40-59/100")
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write(s.content[40:60])
+				}
+			},
+			wantErr:  true,
+			keepPart: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server, episode, opts := newPodcastTest(t, 100)
+			if tt.handle != nil {
+				server.handle = tt.handle(server)
+			}
+
+			path := filepath.Join(opts.Dir, episode.FileName())
+			partial := bytes.Repeat([]byte("x"), tt.partial)
+			copy(partial, server.content)
+			if err := os.WriteFile(path+partialSuffix, partial, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			download, err := client.DownloadPodcastEpisode(context.Background(), episode, opts)
+			if got := server.requests(); len(got) != 1 || got[0] != "bytes="+strconv.Itoa(tt.partial)+"-" {
+				t.Errorf("Range headers = %q", got)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DownloadPodcastEpisode = %+v, want an error", download)
+				}
+				_, statErr := os.Stat(path + partialSuffix)
+				if kept := statErr == nil; kept != tt.keepPart {
+					t.Errorf("partial file kept = %v, want %v", kept, tt.keepPart)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DownloadPodcastEpisode returned error: %v", err)
+			}
+			if download.Resumed != tt.resumed || download.Size != 100 || download.LengthMismatch != nil {
+				t.Errorf("DownloadPodcastEpisode = %+v", download)
+			}
+			if got := readFile(t, path); got != string(server.content) {
+				t.Errorf("downloaded %q", got)
+			}
+		})
+	}
+}
+
+func TestDownloadPodcastEpisode_Truncated(t *testing.T) {
+	client, server, episode, opts := newPodcastTest(t, 100)
+
+	// The connection drops after half of the announced body
+	server.handle = func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write(server.content[:50])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+
+	path := filepath.Join(opts.Dir, episode.FileName())
+	if _, err := client.DownloadPodcastEpisode(context.Background(), episode, opts); err == nil {
+		t.Fatal("DownloadPodcastEpisode of a truncated response returned no error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("a truncated download was saved as complete")
+	}
+	if got := readFile(t, path+partialSuffix); got != string(server.content[:50]) {
+		t.Errorf("partial file = %q, want the received half", got)
+	}
+
+	// The next attempt resumes it
+	server.handle = nil
+	download, err := client.DownloadPodcastEpisode(context.Background(), episode, opts)
+	if err != nil {
+		t.Fatalf("DownloadPodcastEpisode returned error: %v", err)
+	}
+	if !download.Resumed || readFile(t, path) != string(server.content) {
+		t.Errorf("resumed download = %+v", download)
+	}
+	if got := server.requests(); got[len(got)-1] != "bytes=50-" {
+		t.Errorf("Range headers = %q", got)
+	}
+}
+
+func TestDownloadPodcastEpisode_HTTPError(t *testing.T) {
+	client, server, episode, opts := newPodcastTest(t, 100)
+	server.handle = func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusServiceUnavailable)
+	}
+
+	_, err := client.DownloadPodcastEpisode(context.Background(), episode, opts)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || !apiErr.Retryable {
+		t.Errorf("DownloadPodcastEpisode returned error %v, want a retryable 503 APIError", err)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header            string
+		start, end, total int64
+		wantErr           bool
+	}{
+		{header: "bytes 0-99/100", start: 0, end: 99, total: 100},
+		{header: "bytes 40-99/*", start: 40, end: 99, total: -1},
+		{header: "bytes */100", start: 0, end: -1, total: 100},
+		{header: "bytes 50-40/100", wantErr: true},
+		{header: "bytes 40-/100", wantErr: true},
+		{header: "bytes 40-99", wantErr: true},
+		{header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		start, end, total, err := parseContentRange(tt.header)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseContentRange(%q) error = %v", tt.header, err)
+			continue
+		}
+		if !tt.wantErr && (start != tt.start || end != tt.end || total != tt.total) {
+			t.Errorf("parseContentRange(%q) = %d, %d, %d, want %d, %d, %d", tt.header, start, end, total, tt.start, tt.end, tt.total)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package feedbin
 
 import (
 	"context"
+	"fmt"
 )
 
 // GetRecentlyReadEntries retrieves all recently read entry IDs for the authenticated user.
@@ -15,3 +16,28 @@ func (c *Client) GetRecentlyReadEntries(ctx context.Context) ([]int, error) {
 
 	return entryIDs, nil
 }
+
+// MarkAsRecentlyRead adds the specified entry IDs to the recently read history.
+// Returns the entry IDs of the recently read records that were created.
+// Maximum of 1000 entry IDs per request.
+func (c *Client) MarkAsRecentlyRead(ctx context.Context, entryIDs []int) ([]int, error) {
+	if len(entryIDs) == 0 {
+		return []int{}, nil
+	}
+
+	if len(entryIDs) > MaxBulkOperationSize {
+		return nil, fmt.Errorf("too many entry IDs: maximum %d allowed, got %d", MaxBulkOperationSize, len(entryIDs))
+	}
+
+	request := RecentlyReadEntriesRequest{
+		RecentlyReadEntries: entryIDs,
+	}
+
+	var result []int
+	_, err := c.post(ctx, "recently_read_entries.json", nil, request, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
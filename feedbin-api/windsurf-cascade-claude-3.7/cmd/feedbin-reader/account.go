@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/feedbin/client"
+)
+
+const (
+	// maxEntriesPerRequest is the number of IDs entries.json accepts at once
+	maxEntriesPerRequest = 100
+
+	// maxChangesPerRequest is the number of IDs the unread and starred
+	// endpoints accept at once
+	maxChangesPerRequest = 1000
+)
+
+// account serializes calls to the Feedbin client, which keeps caching
+// headers from the last response and is not safe for concurrent use.
+type account struct {
+	fb *feedbin.Feedbin
+	mu sync.Mutex
+}
+
+// fresh drops the caching headers the client kept from the last response.
+// They belong to whatever was requested last, and the reader always wants
+// current data, so this is called before every request.
+func (a *account) fresh() *feedbin.Feedbin {
+	a.fb.Client.LastETag = ""
+	a.fb.Client.LastModified = ""
+	return a.fb
+}
+
+// library is the locally known state of the account
+type library struct {
+	subs     []feedbin.Subscription
+	feeds    map[int]*feedbin.Subscription
+	tags     []string
+	tagFeeds map[string][]int
+	feedTags map[int][]string
+	unread   map[int]bool
+	starred  map[int]bool
+	entries  map[int]*feedbin.Entry
+}
+
+// newLibrary indexes subscriptions and taggings
+func newLibrary(subs []feedbin.Subscription, taggings []feedbin.Tagging, unread, starred []int) *library {
+	lib := &library{
+		subs:     subs,
+		feeds:    make(map[int]*feedbin.Subscription, len(subs)),
+		tagFeeds: make(map[string][]int),
+		feedTags: make(map[int][]string),
+		unread:   make(map[int]bool, len(unread)),
+		starred:  make(map[int]bool, len(starred)),
+		entries:  make(map[int]*feedbin.Entry),
+	}
+
+	sort.Slice(lib.subs, func(i, j int) bool {
+		return strings.ToLower(lib.subs[i].Title) < strings.ToLower(lib.subs[j].Title)
+	})
+	for i := range lib.subs {
+		lib.feeds[lib.subs[i].FeedID] = &lib.subs[i]
+	}
+
+	for _, tagging := range taggings {
+		if _, ok := lib.feeds[tagging.FeedID]; !ok {
+			continue
+		}
+		if _, ok := lib.tagFeeds[tagging.Name]; !ok {
+			lib.tags = append(lib.tags, tagging.Name)
+		}
+		lib.tagFeeds[tagging.Name] = append(lib.tagFeeds[tagging.Name], tagging.FeedID)
+		lib.feedTags[tagging.FeedID] = append(lib.feedTags[tagging.FeedID], tagging.Name)
+	}
+	sort.Slice(lib.tags, func(i, j int) bool {
+		return strings.ToLower(lib.tags[i]) < strings.ToLower(lib.tags[j])
+	})
+	for _, feedIDs := range lib.tagFeeds {
+		sort.Slice(feedIDs, func(i, j int) bool {
+			return strings.ToLower(lib.feeds[feedIDs[i]].Title) < strings.ToLower(lib.feeds[feedIDs[j]].Title)
+		})
+	}
+
+	for _, id := range unread {
+		lib.unread[id] = true
+	}
+	for _, id := range starred {
+		lib.starred[id] = true
+	}
+
+	return lib
+}
+
+// addEntries adds entries to the local cache
+func (lib *library) addEntries(entries []feedbin.Entry) {
+	for i := range entries {
+		lib.entries[entries[i].ID] = &entries[i]
+	}
+}
+
+// feedTitle returns the title of the subscription to a feed
+func (lib *library) feedTitle(feedID int) string {
+	if sub, ok := lib.feeds[feedID]; ok {
+		return sub.Title
+	}
+	return fmt.Sprintf("feed %d", feedID)
+}
+
+// unreadCounts returns the number of cached unread entries per feed
+func (lib *library) unreadCounts() map[int]int {
+	counts := make(map[int]int)
+	for id := range lib.unread {
+		if entry, ok := lib.entries[id]; ok {
+			counts[entry.FeedID]++
+		}
+	}
+	return counts
+}
+
+// loadLibrary fetches subscriptions, taggings, unread and starred IDs, and
+// the unread entries themselves so unread counts can be shown per feed.
+// progress is called after each page of entries.
+func (a *account) loadLibrary(progress func(loaded, total int)) (*library, error) {
+	lib, unread, err := a.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := a.entriesByID(unread, progress)
+	if err != nil {
+		return nil, err
+	}
+	lib.addEntries(entries)
+
+	return lib, nil
+}
+
+// loadIndex fetches everything but the entries and returns the unread IDs
+func (a *account) loadIndex() (*library, []int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	subs, err := a.fresh().Subscriptions.List(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taggings, err := a.fresh().Taggings.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unread, err := a.fresh().UnreadEntries.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	starred, err := a.fresh().StarredEntries.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newLibrary(subs, taggings, unread, starred), unread, nil
+}
+
+// entriesByID fetches entries by ID in pages of maxEntriesPerRequest
+func (a *account) entriesByID(ids []int, progress func(loaded, total int)) ([]feedbin.Entry, error) {
+	var entries []feedbin.Entry
+	for start := 0; start < len(ids); start += maxEntriesPerRequest {
+		end := min(start+maxEntriesPerRequest, len(ids))
+
+		a.mu.Lock()
+		page, err := a.fresh().Entries.List(&feedbin.EntryListOptions{IDs: ids[start:end]})
+		a.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, page...)
+		if progress != nil {
+			progress(end, len(ids))
+		}
+	}
+	return entries, nil
+}
+
+// feedEntries fetches the most recent entries of a feed
+func (a *account) feedEntries(feedID int) ([]feedbin.Entry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.fresh().Entries.ListByFeed(feedID, nil)
+}
+
+// setRead marks entries as read or unread in chunks of maxChangesPerRequest
+func (a *account) setRead(ids []int, read bool) error {
+	return a.chunked(ids, func(chunk []int) (err error) {
+		if read {
+			_, err = a.fresh().UnreadEntries.Delete(chunk)
+		} else {
+			_, err = a.fresh().UnreadEntries.Create(chunk)
+		}
+		return err
+	})
+}
+
+// setStarred stars or unstars entries in chunks of maxChangesPerRequest
+func (a *account) setStarred(ids []int, starred bool) error {
+	return a.chunked(ids, func(chunk []int) (err error) {
+		if starred {
+			_, err = a.fresh().StarredEntries.Create(chunk)
+		} else {
+			_, err = a.fresh().StarredEntries.Delete(chunk)
+		}
+		return err
+	})
+}
+
+// chunked calls fn with consecutive chunks of ids while holding the lock
+func (a *account) chunked(ids []int, fn func([]int) error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for start := 0; start < len(ids); start += maxChangesPerRequest {
+		end := min(start+maxChangesPerRequest, len(ids))
+		if err := fn(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fullContent fetches the extracted article behind an entry's
+// ExtractedContentURL. The URL is already signed, so no credentials are sent.
+func (a *account) fullContent(entry *feedbin.Entry) (string, error) {
+	if entry.ExtractedContentURL == "" {
+		return "", fmt.Errorf("no full content available for this entry")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, entry.ExtractedContentURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", feedbin.UserAgent)
+
+	resp, err := a.fb.Client.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := feedbin.CheckResponse(resp); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Content *string `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Content == nil || *result.Content == "" {
+		return "", fmt.Errorf("full content is empty")
+	}
+	return *result.Content, nil
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// block is a paragraph of article text
+type block struct {
+	// first prefixes the first line and rest the following ones, for
+	// list bullets and quotes
+	first, rest string
+
+	text string
+
+	// pre keeps line breaks and spacing
+	pre bool
+
+	// tight blocks are not separated from the previous block by a blank line
+	tight bool
+
+	heading bool
+}
+
+// textLine is a line of rendered article text
+type textLine struct {
+	text string
+	bold bool
+}
+
+// blockElements start a new paragraph
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "footer": true, "header": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "tr": true, "ul": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// skippedElements are dropped along with their content
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "iframe": true,
+	"head": true, "template": true, "svg": true,
+}
+
+// htmlRenderer turns entry HTML into blocks
+type htmlRenderer struct {
+	blocks []block
+	text   strings.Builder
+
+	quote   int
+	lists   []int // item counter per open list, -1 for unordered lists
+	bullet  string
+	pre     int
+	skip    int
+	heading int
+	tight   bool
+
+	links     []string
+	linkIndex map[string]int
+	anchors   []string
+}
+
+// renderHTML converts entry HTML into paragraphs of plain text, followed by
+// the numbered links referenced from the text. base resolves relative links.
+func renderHTML(src string, base *url.URL) []block {
+	r := &htmlRenderer{linkIndex: make(map[string]int)}
+
+	for _, tok := range tokenizeHTML(src) {
+		switch tok.kind {
+		case tokenStart:
+			r.start(tok.name, tok.attrs, base)
+		case tokenEnd:
+			r.end(tok.name)
+		case tokenText:
+			if r.skip == 0 {
+				r.text.WriteString(tok.text)
+			}
+		}
+	}
+	r.flush()
+
+	if len(r.links) > 0 {
+		r.blocks = append(r.blocks, block{text: "Links", heading: true})
+		for i, link := range r.links {
+			r.blocks = append(r.blocks, block{text: fmt.Sprintf("[%d] %s", i+1, link), tight: i > 0})
+		}
+	}
+
+	return r.blocks
+}
+
+// start handles an opening tag
+func (r *htmlRenderer) start(name string, attrs map[string]string, base *url.URL) {
+	if skippedElements[name] {
+		r.skip++
+		return
+	}
+	if r.skip > 0 {
+		return
+	}
+
+	if blockElements[name] {
+		r.flush()
+	}
+
+	switch name {
+	case "br":
+		r.flush()
+		r.tight = true
+	case "hr":
+		r.flush()
+		r.blocks = append(r.blocks, block{text: "* * *"})
+	case "blockquote":
+		r.quote++
+	case "pre":
+		r.pre++
+	case "ul":
+		r.lists = append(r.lists, -1)
+	case "ol":
+		r.lists = append(r.lists, 0)
+	case "li":
+		r.bullet = "• "
+		if n := len(r.lists); n > 0 && r.lists[n-1] >= 0 {
+			r.lists[n-1]++
+			r.bullet = fmt.Sprintf("%d. ", r.lists[n-1])
+		}
+		r.tight = true
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.heading++
+	case "img":
+		if alt := strings.TrimSpace(attrs["alt"]); alt != "" {
+			r.text.WriteString(" [image: " + alt + "] ")
+		} else {
+			r.text.WriteString(" [image] ")
+		}
+	case "a":
+		r.anchors = append(r.anchors, resolveLink(attrs["href"], base))
+	}
+}
+
+// end handles a closing tag
+func (r *htmlRenderer) end(name string) {
+	if skippedElements[name] {
+		if r.skip > 0 {
+			r.skip--
+		}
+		return
+	}
+	if r.skip > 0 {
+		return
+	}
+
+	switch name {
+	case "a":
+		if n := len(r.anchors); n > 0 {
+			if href := r.anchors[n-1]; href != "" {
+				r.text.WriteString(fmt.Sprintf(" [%d]", r.linkNumber(href)))
+			}
+			r.anchors = r.anchors[:n-1]
+		}
+	case "blockquote":
+		r.flush()
+		r.quote = max(r.quote-1, 0)
+	case "pre":
+		r.flush()
+		r.pre = max(r.pre-1, 0)
+	case "ul", "ol":
+		r.flush()
+		if n := len(r.lists); n > 0 {
+			r.lists = r.lists[:n-1]
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.flush()
+		r.heading = max(r.heading-1, 0)
+	default:
+		if blockElements[name] {
+			r.flush()
+		}
+	}
+}
+
+// flush ends the current paragraph
+func (r *htmlRenderer) flush() {
+	raw := r.text.String()
+	r.text.Reset()
+
+	text := strings.Join(strings.Fields(raw), " ")
+	if r.pre > 0 {
+		text = strings.Trim(strings.ReplaceAll(raw, "\t", "    "), "\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	indent := strings.Repeat("│ ", r.quote)
+	if n := len(r.lists); n > 1 {
+		indent += strings.Repeat("  ", n-1)
+	}
+
+	b := block{
+		first:   indent,
+		rest:    indent,
+		text:    text,
+		pre:     r.pre > 0,
+		tight:   r.tight,
+		heading: r.heading > 0,
+	}
+	if r.bullet != "" {
+		b.first += r.bullet
+		b.rest += strings.Repeat(" ", utf8.RuneCountInString(r.bullet))
+		r.bullet = ""
+	}
+
+	r.blocks = append(r.blocks, b)
+	r.tight = false
+}
+
+// linkNumber returns the 1-based number of a link, adding it if needed
+func (r *htmlRenderer) linkNumber(href string) int {
+	if n, ok := r.linkIndex[href]; ok {
+		return n
+	}
+	r.links = append(r.links, href)
+	r.linkIndex[href] = len(r.links)
+	return len(r.links)
+}
+
+// resolveLink returns href as an absolute http(s) URL, or "" for links that
+// cannot be opened, like fragments and javascript: URLs
+func resolveLink(href string, base *url.URL) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || href == "" {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// wrapBlocks lays out blocks in lines of at most width characters
+func wrapBlocks(blocks []block, width int) []textLine {
+	var lines []textLine
+	for i, b := range blocks {
+		if i > 0 && !b.tight {
+			lines = append(lines, textLine{})
+		}
+
+		prefix := b.first
+		avail := max(width-utf8.RuneCountInString(b.first), 10)
+
+		var paragraphs []string
+		if b.pre {
+			paragraphs = strings.Split(b.text, "\n")
+		} else {
+			paragraphs = []string{b.text}
+		}
+
+		for _, p := range paragraphs {
+			var wrapped []string
+			if b.pre {
+				wrapped = hardWrap(p, avail)
+			} else {
+				wrapped = wordWrap(p, avail)
+			}
+			for _, w := range wrapped {
+				lines = append(lines, textLine{text: prefix + w, bold: b.heading})
+				prefix = b.rest
+			}
+		}
+	}
+	return lines
+}
+
+// wordWrap breaks text at spaces into lines of at most width characters,
+// splitting words that are longer than a line
+func wordWrap(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineLen := 0
+
+	for _, word := range strings.Fields(text) {
+		wordLen := utf8.RuneCountInString(word)
+		if lineLen > 0 && lineLen+1+wordLen > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineLen = 0
+		}
+		for wordLen > width {
+			var head string
+			head, word = splitRunes(word, width)
+			lines = append(lines, head)
+			wordLen -= width
+		}
+		if lineLen > 0 {
+			line.WriteByte(' ')
+			lineLen++
+		}
+		line.WriteString(word)
+		lineLen += wordLen
+	}
+	if lineLen > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// hardWrap breaks preformatted text every width characters
+func hardWrap(text string, width int) []string {
+	lines := []string{}
+	for utf8.RuneCountInString(text) > width {
+		var head string
+		head, text = splitRunes(text, width)
+		lines = append(lines, head)
+	}
+	return append(lines, text)
+}
+
+// splitRunes splits s after n runes
+func splitRunes(s string, n int) (string, string) {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos], s[pos:]
+		}
+		i++
+	}
+	return s, ""
+}
+
+// tokenKind identifies an HTML token
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenStart
+	tokenEnd
+)
+
+// htmlToken is a text run or a tag. Names are lowercase and text and
+// attribute values are unescaped.
+type htmlToken struct {
+	kind  tokenKind
+	name  string
+	attrs map[string]string
+	text  string
+}
+
+// rawTextElements contain text that is not markup
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// tokenizeHTML splits HTML into tokens. It is lenient the way browsers are:
+// unquoted attributes, unclosed and stray tags are all accepted, and a "<"
+// that does not start a tag is text.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, htmlToken{kind: tokenText, text: html.UnescapeString(t)})
+		}
+	}
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			text(s)
+			break
+		}
+		text(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[4+end+3:]
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		closing := strings.HasPrefix(s, "</")
+		nameStart := 1
+		if closing {
+			nameStart = 2
+		}
+		nameEnd := nameStart
+		for nameEnd < len(s) && isNameByte(s[nameEnd]) {
+			nameEnd++
+		}
+		if nameEnd == nameStart || !isLetter(s[nameStart]) {
+			text("<")
+			s = s[1:]
+			continue
+		}
+		name := strings.ToLower(s[nameStart:nameEnd])
+
+		attrs, rest := parseAttrs(s[nameEnd:])
+		s = rest
+		if closing {
+			tokens = append(tokens, htmlToken{kind: tokenEnd, name: name})
+			continue
+		}
+		tokens = append(tokens, htmlToken{kind: tokenStart, name: name, attrs: attrs})
+
+		if rawTextElements[name] {
+			end := indexFold(s, "</"+name)
+			if end < 0 {
+				end = len(s)
+			}
+			if name == "textarea" || name == "title" {
+				text(s[:end])
+			}
+			s = s[end:]
+		}
+	}
+	return tokens
+}
+
+// parseAttrs reads attributes up to the end of a tag and returns them with
+// the input following the tag
+func parseAttrs(s string) (map[string]string, string) {
+	attrs := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t\r\n\f/")
+		if s == "" {
+			return attrs, s
+		}
+		if s[0] == '>' {
+			return attrs, s[1:]
+		}
+
+		end := strings.IndexAny(s, " \t\r\n\f/=>")
+		if end < 0 {
+			end = len(s)
+		}
+		name := strings.ToLower(s[:end])
+		s = strings.TrimLeft(s[end:], " \t\r\n\f")
+
+		value := ""
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\r\n\f")
+			if s != "" && (s[0] == '"' || s[0] == '\'') {
+				quote := s[0]
+				end := strings.IndexByte(s[1:], quote)
+				if end < 0 {
+					value, s = s[1:], ""
+				} else {
+					value, s = s[1:1+end], s[2+end:]
+				}
+			} else {
+				end := strings.IndexAny(s, " \t\r\n\f>")
+				if end < 0 {
+					end = len(s)
+				}
+				value, s = s[:end], s[end:]
+			}
+		}
+		if _, ok := attrs[name]; !ok && name != "" {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+}
+
+// indexFold is strings.Index ignoring ASCII case
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// isNameByte reports whether c can be part of a tag name
+func isNameByte(c byte) bool {
+	return isLetter(c) || c >= '0' && c <= '9' || c == '-' || c == ':'
+}
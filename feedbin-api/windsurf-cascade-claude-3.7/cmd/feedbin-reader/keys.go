@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// keyCode identifies a key that is not a printable character
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyTab
+	keyBacktab
+	keyEscape
+	keyBackspace
+	keyCtrlC
+	keyCtrlL
+)
+
+// key is a key press. r is set for keyRune.
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// escapeSequences maps the sequences terminals send after ESC
+var escapeSequences = map[string]keyCode{
+	"[A": keyUp, "[B": keyDown, "[C": keyRight, "[D": keyLeft,
+	"OA": keyUp, "OB": keyDown, "OC": keyRight, "OD": keyLeft,
+	"[H": keyHome, "[F": keyEnd, "OH": keyHome, "OF": keyEnd,
+	"[1~": keyHome, "[4~": keyEnd, "[7~": keyHome, "[8~": keyEnd,
+	"[5~": keyPageUp, "[6~": keyPageDown, "[Z": keyBacktab,
+}
+
+// readKeys reads key presses from r and sends them on ch until r fails
+func readKeys(r io.Reader, ch chan<- []key) {
+	defer close(ch)
+
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			ch <- parseKeys(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys decodes the bytes of one read. Terminals write an escape
+// sequence in a single write, so a lone ESC at the end is the Escape key.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			code, n := parseEscape(b[1:])
+			keys = append(keys, key{code: code})
+			b = b[1+n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{code: keyEnter})
+		case c == '\t':
+			keys = append(keys, key{code: keyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case c == 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		case c == 0x0c:
+			keys = append(keys, key{code: keyCtrlL})
+		case c < 0x20:
+			// other control characters are ignored
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{code: keyRune, r: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// parseEscape decodes the sequence following ESC and returns the key and
+// the number of bytes used. Unknown sequences are consumed and reported as
+// Escape so their bytes are not taken for typed characters.
+func parseEscape(b []byte) (keyCode, int) {
+	if len(b) == 0 || (b[0] != '[' && b[0] != 'O') {
+		return keyEscape, 0
+	}
+
+	// CSI and SS3 sequences end with a byte in the range @ to ~
+	end := bytes.IndexFunc(b[1:], func(r rune) bool { return r >= '@' && r <= '~' })
+	if end < 0 {
+		return keyEscape, len(b)
+	}
+	seq := string(b[:end+2])
+	if code, ok := escapeSequences[seq]; ok {
+		return code, len(seq)
+	}
+	return keyEscape, len(seq)
+}
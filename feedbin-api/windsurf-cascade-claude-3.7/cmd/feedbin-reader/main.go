@@ -0,0 +1,132 @@
+// Command feedbin-reader is an interactive terminal reader for Feedbin.
+//
+// The screen has three panes: tags and feeds with their unread counts, the
+// entries of the selected tag or feed, and the article, rendered from the
+// entry HTML as wrapped text. Tab moves between panes, j/k and the arrow
+// keys move within them, and enter opens the selection.
+//
+//	m  mark read/unread      s  star/unstar
+//	o  open in browser       f  fetch full content (press again to go back)
+//	n  next entry            p  previous entry
+//	r  refresh               q  quit
+//
+// Read and starred changes show immediately and are written back to Feedbin
+// in the background; changes still pending on quit are sent before exiting.
+//
+// Credentials are read from FEEDBIN_USERNAME and FEEDBIN_PASSWORD. Links
+// are opened with $BROWSER when it is set.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/feedbin/client"
+)
+
+func main() {
+	// Get credentials from environment variables
+	username := os.Getenv("FEEDBIN_USERNAME")
+	password := os.Getenv("FEEDBIN_PASSWORD")
+
+	if username == "" || password == "" {
+		log.Fatal("FEEDBIN_USERNAME and FEEDBIN_PASSWORD environment variables must be set")
+	}
+
+	a := &account{fb: feedbin.New(username, password)}
+
+	fmt.Fprint(os.Stderr, "Loading subscriptions…")
+	lib, err := a.loadLibrary(func(loaded, total int) {
+		fmt.Fprintf(os.Stderr, "\rLoading unread entries %d/%d…", loaded, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.Fatalf("Error loading account: %v", err)
+	}
+
+	if err := run(a, lib); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run shows the reader until the user quits and then writes back the
+// changes the background sync has not sent yet
+func run(a *account, lib *library) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := newUI(ctx, a, lib)
+	u.syncer = newSyncer(a, func(err error) {
+		u.post(func() { u.syncDone(err) })
+	})
+
+	synced := make(chan struct{})
+	go func() {
+		u.syncer.run(ctx)
+		close(synced)
+	}()
+
+	err := interact(u)
+	cancel()
+	<-synced
+
+	if pending := u.syncer.pending(); pending > 0 {
+		fmt.Fprintf(os.Stderr, "Saving %d changes…\n", pending)
+		if flushErr := u.syncer.flush(); flushErr != nil {
+			return fmt.Errorf("saving changes: %w", flushErr)
+		}
+	}
+	return err
+}
+
+// interact runs the event loop on the terminal
+func interact(u *ui) error {
+	fd := int(os.Stdin.Fd())
+	restore, err := makeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("setting up terminal: %w", err)
+	}
+	defer restore()
+
+	out := os.Stdout
+	fmt.Fprint(out, altScreen+hideCursor+clearScreen)
+	defer fmt.Fprint(out, styleReset+showCursor+mainScreen)
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+	defer signal.Stop(resize)
+
+	keys := make(chan []key)
+	go readKeys(os.Stdin, keys)
+
+	for {
+		if width, height, err := terminalSize(fd); err == nil && width > 0 && height > 0 {
+			u.width, u.height = width, height
+		}
+		if _, err := fmt.Fprint(out, u.render()); err != nil {
+			return err
+		}
+
+		select {
+		case batch, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range batch {
+				if k.code == keyCtrlL {
+					fmt.Fprint(out, clearScreen)
+				}
+				if u.handleKey(k) {
+					return nil
+				}
+			}
+		case fn := <-u.events:
+			fn()
+		case <-resize:
+			fmt.Fprint(out, clearScreen)
+		}
+	}
+}
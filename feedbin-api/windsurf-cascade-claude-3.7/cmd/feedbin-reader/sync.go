@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// syncDelay is how long changes are collected before they are sent
+	syncDelay = 500 * time.Millisecond
+
+	// syncRetryMax is the longest wait between retries of a failed sync
+	syncRetryMax = time.Minute
+)
+
+// syncer writes read and starred changes back to Feedbin in the background.
+// Changes are applied to the library by the caller right away; the syncer
+// only remembers the latest wanted state of each entry, so toggling an entry
+// several times before a sync sends just its final state.
+type syncer struct {
+	account *account
+
+	// onFlush is called from the sync goroutine with the result of each sync
+	onFlush func(error)
+
+	mu      sync.Mutex
+	read    map[int]bool
+	starred map[int]bool
+	wake    chan struct{}
+}
+
+// newSyncer returns a syncer for the account
+func newSyncer(a *account, onFlush func(error)) *syncer {
+	return &syncer{
+		account: a,
+		onFlush: onFlush,
+		read:    make(map[int]bool),
+		starred: make(map[int]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// setRead queues marking an entry as read or unread
+func (s *syncer) setRead(id int, read bool) {
+	s.mu.Lock()
+	s.read[id] = read
+	s.mu.Unlock()
+	s.notify()
+}
+
+// setStarred queues starring or unstarring an entry
+func (s *syncer) setStarred(id int, starred bool) {
+	s.mu.Lock()
+	s.starred[id] = starred
+	s.mu.Unlock()
+	s.notify()
+}
+
+// pending returns the number of changes not yet written back
+func (s *syncer) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.read) + len(s.starred)
+}
+
+// overlay applies the pending changes to a freshly loaded library, so a
+// refresh does not undo changes that have not been written back yet
+func (s *syncer) overlay(lib *library) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, read := range s.read {
+		setFlag(lib.unread, id, !read)
+	}
+	for id, starred := range s.starred {
+		setFlag(lib.starred, id, starred)
+	}
+}
+
+// notify wakes the sync goroutine without blocking
+func (s *syncer) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run syncs pending changes until ctx is done, retrying failed syncs with
+// exponential backoff. Changes made after ctx is done are left for flush.
+func (s *syncer) run(ctx context.Context) {
+	retry := time.Duration(0)
+	for {
+		var wait <-chan time.Time
+		if retry > 0 {
+			wait = time.After(retry)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-wait:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(syncDelay):
+		}
+
+		err := s.flush()
+		s.onFlush(err)
+		if err != nil {
+			retry = min(max(2*retry, 2*time.Second), syncRetryMax)
+			continue
+		}
+		retry = 0
+	}
+}
+
+// flush sends all pending changes. Changes that fail are queued again
+// unless a newer change to the same entry was made in the meantime.
+func (s *syncer) flush() error {
+	s.mu.Lock()
+	read, starred := s.read, s.starred
+	s.read, s.starred = make(map[int]bool), make(map[int]bool)
+	s.mu.Unlock()
+
+	var firstErr error
+	send := func(changes map[int]bool, queue *map[int]bool, want bool, apply func([]int, bool) error) {
+		ids := idsWith(changes, want)
+		if len(ids) == 0 {
+			return
+		}
+		if err := apply(ids, want); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			s.mu.Lock()
+			for _, id := range ids {
+				if _, newer := (*queue)[id]; !newer {
+					(*queue)[id] = want
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	send(read, &s.read, true, s.account.setRead)
+	send(read, &s.read, false, s.account.setRead)
+	send(starred, &s.starred, true, s.account.setStarred)
+	send(starred, &s.starred, false, s.account.setStarred)
+
+	return firstErr
+}
+
+// idsWith returns the sorted IDs whose value is want
+func idsWith(changes map[int]bool, want bool) []int {
+	var ids []int
+	for id, value := range changes {
+		if value == want {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// setFlag adds id to set when on is true and removes it otherwise
+func setFlag(set map[int]bool, id int, on bool) {
+	if on {
+		set[id] = true
+	} else {
+		delete(set, id)
+	}
+}
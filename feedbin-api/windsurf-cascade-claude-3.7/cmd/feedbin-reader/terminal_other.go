@@ -0,0 +1,21 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw is not supported on this platform
+func makeRaw(fd int) (func() error, error) {
+	return nil, errors.New("feedbin-reader needs a Unix terminal")
+}
+
+// terminalSize returns a conventional size
+func terminalSize(fd int) (int, int, error) {
+	return 80, 24, nil
+}
+
+// notifyResize does nothing on this platform
+func notifyResize(ch chan<- os.Signal) {}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/feedbin/client"
+)
+
+// pane identifies one of the three panes
+type pane int
+
+const (
+	paneFeeds pane = iota
+	paneEntries
+	paneArticle
+)
+
+// nodeKind identifies what a row in the feeds pane lists
+type nodeKind int
+
+const (
+	nodeUnread nodeKind = iota
+	nodeStarred
+	nodeTag
+	nodeFeed
+)
+
+// node is a row in the feeds pane
+type node struct {
+	kind   nodeKind
+	label  string
+	tag    string
+	feedID int
+	depth  int
+}
+
+// articleKey identifies how the article lines were rendered
+type articleKey struct {
+	id    int
+	width int
+	full  bool
+}
+
+// ui is the state of the reader. It is only touched by the goroutine
+// running the event loop; background work posts functions on events.
+type ui struct {
+	ctx     context.Context
+	account *account
+	syncer  *syncer
+	lib     *library
+	events  chan func()
+
+	width, height int
+	focus         pane
+
+	nodes      []node
+	nodeCursor int
+	nodeOffset int
+
+	opened      node
+	list        []int
+	listCursor  int
+	listOffset  int
+	listLoading bool
+
+	article       int
+	articleLines  []textLine
+	articleKey    articleKey
+	articleOffset int
+	full          map[int]string
+	showFull      map[int]bool
+
+	status  string
+	loading bool
+}
+
+// newUI returns a reader showing the unread entries of lib
+func newUI(ctx context.Context, a *account, lib *library) *ui {
+	u := &ui{
+		ctx:      ctx,
+		account:  a,
+		lib:      lib,
+		events:   make(chan func(), 16),
+		width:    80,
+		height:   24,
+		full:     make(map[int]string),
+		showFull: make(map[int]bool),
+	}
+	u.rebuildNodes()
+	u.openNode(u.nodes[0])
+	return u
+}
+
+// post runs fn on the event loop. It gives up once the reader has quit.
+func (u *ui) post(fn func()) {
+	select {
+	case u.events <- fn:
+	case <-u.ctx.Done():
+	}
+}
+
+// async runs work in the background and then the function it returns on
+// the event loop
+func (u *ui) async(work func() func()) {
+	go func() {
+		u.post(work())
+	}()
+}
+
+// syncDone is called after each background sync
+func (u *ui) syncDone(err error) {
+	if err != nil {
+		u.status = fmt.Sprintf("Sync failed, will retry: %v", err)
+	}
+}
+
+// rebuildNodes lists Unread, Starred, each tag followed by its feeds, and
+// then the untagged feeds, keeping the cursor on the same row
+func (u *ui) rebuildNodes() {
+	var current node
+	if u.nodeCursor < len(u.nodes) {
+		current = u.nodes[u.nodeCursor]
+	}
+
+	nodes := []node{
+		{kind: nodeUnread, label: "Unread"},
+		{kind: nodeStarred, label: "Starred"},
+	}
+	for _, tag := range u.lib.tags {
+		nodes = append(nodes, node{kind: nodeTag, label: tag, tag: tag})
+		for _, feedID := range u.lib.tagFeeds[tag] {
+			nodes = append(nodes, node{kind: nodeFeed, label: u.lib.feedTitle(feedID), tag: tag, feedID: feedID, depth: 1})
+		}
+	}
+	for _, sub := range u.lib.subs {
+		if len(u.lib.feedTags[sub.FeedID]) == 0 {
+			nodes = append(nodes, node{kind: nodeFeed, label: sub.Title, feedID: sub.FeedID})
+		}
+	}
+
+	u.nodes = nodes
+	u.nodeCursor = 0
+	for i, n := range nodes {
+		if sameNode(n, current) {
+			u.nodeCursor = i
+			break
+		}
+	}
+}
+
+// sameNode reports whether two rows list the same entries
+func sameNode(a, b node) bool {
+	return a.kind == b.kind && a.tag == b.tag && a.feedID == b.feedID
+}
+
+// openNode lists the entries of a node, fetching what is not cached
+func (u *ui) openNode(n node) {
+	u.opened = n
+	u.list = u.entriesFor(n)
+	u.listCursor, u.listOffset = 0, 0
+	u.listLoading = false
+
+	switch n.kind {
+	case nodeFeed:
+		u.listLoading = true
+		u.async(func() func() {
+			entries, err := u.account.feedEntries(n.feedID)
+			return func() {
+				u.listLoaded(n, entries, err)
+			}
+		})
+	case nodeStarred:
+		var missing []int
+		for id := range u.lib.starred {
+			if _, ok := u.lib.entries[id]; !ok {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) == 0 {
+			return
+		}
+		// Entry IDs grow over time, so the highest are the most recent
+		sort.Sort(sort.Reverse(sort.IntSlice(missing)))
+		missing = missing[:min(len(missing), maxEntriesPerRequest)]
+
+		u.listLoading = true
+		u.async(func() func() {
+			entries, err := u.account.entriesByID(missing, nil)
+			return func() {
+				u.listLoaded(n, entries, err)
+			}
+		})
+	}
+}
+
+// listLoaded adds fetched entries and updates the list if n is still open
+func (u *ui) listLoaded(n node, entries []feedbin.Entry, err error) {
+	if err != nil {
+		u.status = fmt.Sprintf("Loading entries failed: %v", err)
+	}
+	u.lib.addEntries(entries)
+	if sameNode(n, u.opened) {
+		u.listLoading = false
+		u.refreshList()
+	}
+}
+
+// refreshList recomputes the open list, keeping the selected entry
+func (u *ui) refreshList() {
+	selected := u.selectedEntry()
+	u.list = u.entriesFor(u.opened)
+	u.listCursor = 0
+	if selected != nil {
+		for i, id := range u.list {
+			if id == selected.ID {
+				u.listCursor = i
+				break
+			}
+		}
+	}
+}
+
+// entriesFor returns the cached entries of a node, newest first
+func (u *ui) entriesFor(n node) []int {
+	var tagFeeds map[int]bool
+	if n.kind == nodeTag {
+		tagFeeds = make(map[int]bool)
+		for _, feedID := range u.lib.tagFeeds[n.tag] {
+			tagFeeds[feedID] = true
+		}
+	}
+
+	var ids []int
+	for id, entry := range u.lib.entries {
+		var include bool
+		switch n.kind {
+		case nodeUnread:
+			include = u.lib.unread[id]
+		case nodeStarred:
+			include = u.lib.starred[id]
+		case nodeTag:
+			include = tagFeeds[entry.FeedID]
+		case nodeFeed:
+			include = entry.FeedID == n.feedID
+		}
+		if include {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := u.lib.entries[ids[i]], u.lib.entries[ids[j]]
+		if !a.Published.Equal(b.Published) {
+			return a.Published.After(b.Published)
+		}
+		return a.ID > b.ID
+	})
+	return ids
+}
+
+// selectedEntry returns the entry under the cursor of the entry list
+func (u *ui) selectedEntry() *feedbin.Entry {
+	if u.listCursor < 0 || u.listCursor >= len(u.list) {
+		return nil
+	}
+	return u.lib.entries[u.list[u.listCursor]]
+}
+
+// current returns the entry actions apply to: the one being read when the
+// article pane has focus, otherwise the one under the cursor
+func (u *ui) current() *feedbin.Entry {
+	if u.focus == paneArticle {
+		if entry, ok := u.lib.entries[u.article]; ok {
+			return entry
+		}
+	}
+	return u.selectedEntry()
+}
+
+// showArticle opens the selected entry and marks it as read
+func (u *ui) showArticle() {
+	entry := u.selectedEntry()
+	if entry == nil {
+		return
+	}
+	u.article = entry.ID
+	u.articleOffset = 0
+	u.focus = paneArticle
+	if u.lib.unread[entry.ID] {
+		u.setRead(entry, true)
+	}
+}
+
+// setRead marks an entry read or unread locally and queues the change
+func (u *ui) setRead(entry *feedbin.Entry, read bool) {
+	setFlag(u.lib.unread, entry.ID, !read)
+	u.syncer.setRead(entry.ID, read)
+}
+
+// toggleRead flips the read state of the current entry
+func (u *ui) toggleRead() {
+	if entry := u.current(); entry != nil {
+		u.setRead(entry, u.lib.unread[entry.ID])
+	}
+}
+
+// toggleStarred flips the starred state of the current entry
+func (u *ui) toggleStarred() {
+	entry := u.current()
+	if entry == nil {
+		return
+	}
+	starred := !u.lib.starred[entry.ID]
+	setFlag(u.lib.starred, entry.ID, starred)
+	u.syncer.setStarred(entry.ID, starred)
+}
+
+// openURL opens the current entry in the browser
+func (u *ui) openURL() {
+	entry := u.current()
+	if entry == nil {
+		return
+	}
+	if entry.URL == "" {
+		u.status = "This entry has no URL"
+		return
+	}
+	if err := openBrowser(entry.URL); err != nil {
+		u.status = fmt.Sprintf("Opening %s failed: %v", entry.URL, err)
+		return
+	}
+	u.status = "Opened " + entry.URL
+}
+
+// toggleFull switches the article between the feed content and the full
+// content extracted from the web page, fetching it the first time
+func (u *ui) toggleFull() {
+	if u.focus != paneArticle {
+		u.showArticle()
+	}
+	entry := u.current()
+	if entry == nil {
+		return
+	}
+	if u.showFull[entry.ID] {
+		delete(u.showFull, entry.ID)
+		return
+	}
+	if _, ok := u.full[entry.ID]; ok {
+		u.showFull[entry.ID] = true
+		return
+	}
+
+	u.status = "Fetching full content…"
+	u.async(func() func() {
+		content, err := u.account.fullContent(entry)
+		return func() {
+			if err != nil {
+				u.status = fmt.Sprintf("Fetching full content failed: %v", err)
+				return
+			}
+			u.status = ""
+			u.full[entry.ID] = content
+			u.showFull[entry.ID] = true
+			if u.article == entry.ID {
+				u.articleOffset = 0
+			}
+		}
+	})
+}
+
+// refresh reloads the account in the background
+func (u *ui) refresh() {
+	if u.loading {
+		return
+	}
+	u.loading = true
+	u.status = "Refreshing…"
+	u.async(func() func() {
+		lib, err := u.account.loadLibrary(nil)
+		return func() {
+			u.loading = false
+			if err != nil {
+				u.status = fmt.Sprintf("Refresh failed: %v", err)
+				return
+			}
+
+			// Keep read entries that were loaded before
+			for id, entry := range u.lib.entries {
+				if _, ok := lib.entries[id]; !ok {
+					lib.entries[id] = entry
+				}
+			}
+			u.syncer.overlay(lib)
+			u.lib = lib
+			u.rebuildNodes()
+			u.refreshList()
+			u.status = fmt.Sprintf("Refreshed at %s", time.Now().Format("15:04"))
+		}
+	})
+}
+
+// articleText returns the article lines for the current width
+func (u *ui) articleText(width int) []textLine {
+	entry, ok := u.lib.entries[u.article]
+	if !ok {
+		return nil
+	}
+
+	key := articleKey{id: entry.ID, width: width, full: u.showFull[entry.ID]}
+	if key == u.articleKey && u.articleLines != nil {
+		return u.articleLines
+	}
+
+	var lines []textLine
+	lines = append(lines, wrapBlocks([]block{{text: deref(entry.Title, "(untitled)"), heading: true}}, width)...)
+
+	meta := []string{u.lib.feedTitle(entry.FeedID)}
+	if author := deref(entry.Author, ""); author != "" {
+		meta = append(meta, author)
+	}
+	meta = append(meta, entry.Published.Local().Format("Jan 2, 2006 15:04"))
+	lines = append(lines, wrapBlocks([]block{{text: strings.Join(meta, " · ")}}, width)...)
+	if entry.URL != "" {
+		lines = append(lines, wrapBlocks([]block{{text: entry.URL, pre: true}}, width)...)
+	}
+	lines = append(lines, textLine{})
+
+	content := deref(entry.Content, deref(entry.Summary, ""))
+	if key.full {
+		content = u.full[entry.ID]
+	}
+	base, _ := url.Parse(entry.URL)
+	lines = append(lines, wrapBlocks(renderHTML(content, base), width)...)
+
+	u.articleKey, u.articleLines = key, lines
+	return lines
+}
+
+// deref returns *s, or fallback if s is nil or blank
+func deref(s *string, fallback string) string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return fallback
+	}
+	return *s
+}
+
+// openBrowser opens url with $BROWSER or the platform's opener
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, url)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// handleKey applies a key press and reports whether the reader should quit
+func (u *ui) handleKey(k key) bool {
+	u.status = ""
+
+	switch {
+	case k.code == keyCtrlC, k.code == keyRune && k.r == 'q':
+		return true
+	case k.code == keyTab:
+		u.focus = (u.focus + 1) % 3
+		return false
+	case k.code == keyBacktab:
+		u.focus = (u.focus + 2) % 3
+		return false
+	}
+
+	if k.code == keyRune {
+		switch k.r {
+		case 'm':
+			u.toggleRead()
+			return false
+		case 's':
+			u.toggleStarred()
+			return false
+		case 'o':
+			u.openURL()
+			return false
+		case 'f':
+			u.toggleFull()
+			return false
+		case 'r':
+			u.refresh()
+			return false
+		case 'n':
+			u.step(1)
+			return false
+		case 'p':
+			u.step(-1)
+			return false
+		}
+	}
+
+	switch u.focus {
+	case paneFeeds:
+		u.handleFeedsKey(k)
+	case paneEntries:
+		u.handleEntriesKey(k)
+	case paneArticle:
+		u.handleArticleKey(k)
+	}
+	return false
+}
+
+// handleFeedsKey handles keys for the feeds pane
+func (u *ui) handleFeedsKey(k key) {
+	last := len(u.nodes) - 1
+	switch {
+	case isKey(k, keyDown, 'j'):
+		u.nodeCursor = min(u.nodeCursor+1, last)
+	case isKey(k, keyUp, 'k'):
+		u.nodeCursor = max(u.nodeCursor-1, 0)
+	case isKey(k, keyHome, 'g'):
+		u.nodeCursor = 0
+	case isKey(k, keyEnd, 'G'):
+		u.nodeCursor = last
+	case isKey(k, keyPageDown, ' '):
+		u.nodeCursor = min(u.nodeCursor+u.height-2, last)
+	case isKey(k, keyPageUp, 'b'):
+		u.nodeCursor = max(u.nodeCursor-(u.height-2), 0)
+	case isKey(k, keyEnter, 0), isKey(k, keyRight, 'l'):
+		u.openNode(u.nodes[u.nodeCursor])
+		u.focus = paneEntries
+	}
+}
+
+// handleEntriesKey handles keys for the entry list
+func (u *ui) handleEntriesKey(k key) {
+	_, height := u.layout()
+	last := max(len(u.list)-1, 0)
+	switch {
+	case isKey(k, keyDown, 'j'):
+		u.listCursor = min(u.listCursor+1, last)
+	case isKey(k, keyUp, 'k'):
+		u.listCursor = max(u.listCursor-1, 0)
+	case isKey(k, keyHome, 'g'):
+		u.listCursor = 0
+	case isKey(k, keyEnd, 'G'):
+		u.listCursor = last
+	case isKey(k, keyPageDown, ' '):
+		u.listCursor = min(u.listCursor+height, last)
+	case isKey(k, keyPageUp, 'b'):
+		u.listCursor = max(u.listCursor-height, 0)
+	case isKey(k, keyEnter, 0), isKey(k, keyRight, 'l'):
+		u.showArticle()
+	case isKey(k, keyLeft, 'h'), isKey(k, keyEscape, 0):
+		u.focus = paneFeeds
+	}
+}
+
+// handleArticleKey handles keys for the article pane
+func (u *ui) handleArticleKey(k key) {
+	page := max(u.articleHeight()-2, 1)
+	switch {
+	case isKey(k, keyDown, 'j'), isKey(k, keyEnter, 0):
+		u.articleOffset++
+	case isKey(k, keyUp, 'k'):
+		u.articleOffset = max(u.articleOffset-1, 0)
+	case isKey(k, keyPageDown, ' '):
+		u.articleOffset += page
+	case isKey(k, keyPageUp, 'b'):
+		u.articleOffset = max(u.articleOffset-page, 0)
+	case isKey(k, keyHome, 'g'):
+		u.articleOffset = 0
+	case isKey(k, keyEnd, 'G'):
+		u.articleOffset = len(u.articleText(u.articleWidth()))
+	case isKey(k, keyLeft, 'h'), isKey(k, keyEscape, 0):
+		u.focus = paneEntries
+	}
+}
+
+// step opens the next or previous entry in the list
+func (u *ui) step(delta int) {
+	next := u.listCursor + delta
+	if u.focus != paneArticle && u.article == 0 {
+		next = u.listCursor
+	}
+	if next < 0 || next >= len(u.list) {
+		return
+	}
+	u.listCursor = next
+	u.showArticle()
+}
+
+// isKey reports whether k is the given special key, or the rune r if r is
+// not zero
+func isKey(k key, code keyCode, r rune) bool {
+	if k.code == code {
+		return true
+	}
+	return r != 0 && k.code == keyRune && k.r == r
+}
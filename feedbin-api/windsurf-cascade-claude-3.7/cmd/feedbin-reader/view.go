@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used for drawing
+const (
+	styleReset     = "\x1b[0m"
+	styleBold      = "\x1b[1m"
+	styleDim       = "\x1b[2m"
+	styleUnderline = "\x1b[4m"
+	styleReverse   = "\x1b[7m"
+
+	clearScreen = "\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+	altScreen   = "\x1b[?1049h"
+	mainScreen  = "\x1b[?1049l"
+)
+
+// hints is shown in the status bar when there is no message
+const hints = "j/k move  tab pane  enter open  n/p next/prev  m read  s star  o browser  f full text  r refresh  q quit"
+
+// layout returns the width of the feeds pane and the height of the entry
+// list. The article takes the rest of the right side below a separator.
+func (u *ui) layout() (feedsWidth, listHeight int) {
+	feedsWidth = min(max(u.width/4, 16), 36)
+	listHeight = max((u.height-1)/3, 3)
+	return feedsWidth, listHeight
+}
+
+// articleWidth returns the width article text is wrapped to
+func (u *ui) articleWidth() int {
+	feedsWidth, _ := u.layout()
+	return max(u.width-feedsWidth-3, 10)
+}
+
+// articleHeight returns the number of article lines on screen
+func (u *ui) articleHeight() int {
+	_, listHeight := u.layout()
+	return max(u.height-listHeight-2, 1)
+}
+
+// render returns the escape sequences that draw the whole screen
+func (u *ui) render() string {
+	feedsWidth, listHeight := u.layout()
+	rightWidth := max(u.width-feedsWidth-1, 1)
+	rows := u.height - 1
+
+	u.nodeOffset = scrollTo(u.nodeCursor, u.nodeOffset, rows)
+	u.listOffset = scrollTo(u.listCursor, u.listOffset, listHeight)
+
+	article := u.articleText(u.articleWidth())
+	u.articleOffset = max(min(u.articleOffset, len(article)-u.articleHeight()), 0)
+
+	counts := u.lib.unreadCounts()
+
+	var b strings.Builder
+	for y := 0; y < rows; y++ {
+		fmt.Fprintf(&b, "\x1b[%d;1H", y+1)
+		b.WriteString(u.feedsRow(u.nodeOffset+y, feedsWidth, counts))
+		b.WriteString(styleDim + "│" + styleReset)
+
+		switch {
+		case y < listHeight:
+			b.WriteString(u.entryRow(u.listOffset+y, rightWidth))
+		case y == listHeight:
+			b.WriteString(styleDim + strings.Repeat("─", rightWidth) + styleReset)
+		default:
+			line := u.articleOffset + y - listHeight - 1
+			if line < len(article) {
+				style := ""
+				if article[line].bold {
+					style = styleBold
+				}
+				b.WriteString(cell(" "+article[line].text, rightWidth, style))
+			} else {
+				b.WriteString(cell("", rightWidth, ""))
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\x1b[%d;1H", u.height)
+	b.WriteString(u.statusRow())
+	return b.String()
+}
+
+// feedsRow renders row i of the feeds pane
+func (u *ui) feedsRow(i, width int, counts map[int]int) string {
+	if i >= len(u.nodes) {
+		return cell("", width, "")
+	}
+	n := u.nodes[i]
+
+	var count int
+	switch n.kind {
+	case nodeUnread:
+		count = len(u.lib.unread)
+	case nodeStarred:
+		count = len(u.lib.starred)
+	case nodeTag:
+		for _, feedID := range u.lib.tagFeeds[n.tag] {
+			count += counts[feedID]
+		}
+	case nodeFeed:
+		count = counts[n.feedID]
+	}
+
+	suffix := ""
+	if count > 0 {
+		suffix = " " + strconv.Itoa(count)
+	}
+	label := strings.Repeat("  ", n.depth) + n.label
+	label = truncate(label, width-utf8.RuneCountInString(suffix)-1)
+	text := " " + pad(label, width-utf8.RuneCountInString(suffix)-1) + suffix
+
+	style := ""
+	if n.kind != nodeFeed {
+		style = styleBold
+	}
+	if sameNode(n, u.opened) {
+		style += styleUnderline
+	}
+	if i == u.nodeCursor && u.focus == paneFeeds {
+		style += styleReverse
+	}
+	return cell(text, width, style)
+}
+
+// entryRow renders row i of the entry list
+func (u *ui) entryRow(i, width int) string {
+	if i >= len(u.list) {
+		if i == 0 {
+			message := " No entries"
+			if u.listLoading {
+				message = " Loading…"
+			}
+			return cell(message, width, styleDim)
+		}
+		return cell("", width, "")
+	}
+
+	entry := u.lib.entries[u.list[i]]
+	unread := u.lib.unread[entry.ID]
+
+	marks := []rune("   ")
+	if unread {
+		marks[1] = '●'
+	}
+	if u.lib.starred[entry.ID] {
+		marks[2] = '★'
+	}
+
+	text := string(marks) + " " + shortDate(entry.Published) + "  "
+	if u.opened.kind != nodeFeed {
+		text += pad(truncate(u.lib.feedTitle(entry.FeedID), 18), 18) + "  "
+	}
+	text += strings.Join(strings.Fields(deref(entry.Title, "(untitled)")), " ")
+
+	style := ""
+	if unread {
+		style = styleBold
+	}
+	if i == u.listCursor {
+		if u.focus == paneEntries {
+			style += styleReverse
+		} else {
+			style += styleUnderline
+		}
+	}
+	return cell(text, width, style)
+}
+
+// statusRow renders the status bar
+func (u *ui) statusRow() string {
+	text := u.status
+	if text == "" {
+		text = hints
+	}
+
+	right := ""
+	if pending := u.syncer.pending(); pending > 0 {
+		right = fmt.Sprintf("%d unsynced ", pending)
+	}
+
+	avail := u.width - utf8.RuneCountInString(right)
+	return cell(" "+pad(truncate(text, avail-1), avail-1)+right, u.width, styleReverse)
+}
+
+// shortDate formats a time as a time of day for today and a date otherwise
+func shortDate(t time.Time) string {
+	t = t.Local()
+	now := time.Now()
+	switch {
+	case t.Year() == now.Year() && t.YearDay() == now.YearDay():
+		return t.Format("15:04 ")
+	case t.Year() == now.Year():
+		return t.Format("Jan 02")
+	default:
+		return t.Format("2006  ")
+	}
+}
+
+// scrollTo returns the offset that keeps cursor within a window of height
+// rows starting at offset
+func scrollTo(cursor, offset, height int) int {
+	if cursor < offset {
+		return cursor
+	}
+	if cursor >= offset+height {
+		return cursor - height + 1
+	}
+	return offset
+}
+
+// cell draws text padded or truncated to width in style. Control
+// characters are replaced so feed content cannot send escape sequences.
+func cell(text string, width int, style string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r < 0xa0) {
+			return ' '
+		}
+		return r
+	}, text)
+	text = pad(truncate(text, width), width)
+	if style == "" {
+		return text
+	}
+	return style + text + styleReset
+}
+
+// truncate shortens s to at most width characters, ending with an ellipsis
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	head, _ := splitRunes(s, width-1)
+	return head + "…"
+}
+
+// pad fills s with spaces to width characters
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
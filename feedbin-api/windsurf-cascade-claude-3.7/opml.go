@@ -0,0 +1,287 @@
+package feedbin
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OPMLVersion is the OPML version written by the exporter
+const OPMLVersion = "2.0"
+
+// OPML represents an OPML document
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+// OPMLHead represents the head element of an OPML document
+type OPMLHead struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// OPMLBody represents the body element of an OPML document
+type OPMLBody struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline represents an outline element. Feeds have an XMLURL, folders
+// have child outlines instead.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline,omitempty"`
+}
+
+// OPMLFeed is a feed found in an OPML document, with the tags of the
+// folders it appears in
+type OPMLFeed struct {
+	Title   string
+	FeedURL string
+	SiteURL string
+	Tags    []string
+}
+
+// ExportOPML returns the subscriptions as an OPML document, with one folder
+// per tag
+func (f *Feedbin) ExportOPML() (*OPML, error) {
+	subscriptions, err := f.Subscriptions.List(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	taggings, err := f.Taggings.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOPML(subscriptions, taggings), nil
+}
+
+// NewOPML builds an OPML document from subscriptions and taggings.
+// Tagged feeds are placed in a folder per tag, and appear once in each of
+// their folders; untagged feeds are placed at the top level.
+func NewOPML(subscriptions []Subscription, taggings []Tagging) *OPML {
+	tagsByFeed := make(map[int][]string)
+	for _, tagging := range taggings {
+		tagsByFeed[tagging.FeedID] = append(tagsByFeed[tagging.FeedID], tagging.Name)
+	}
+
+	subs := append([]Subscription(nil), subscriptions...)
+	sort.SliceStable(subs, func(i, j int) bool {
+		return strings.ToLower(subs[i].Title) < strings.ToLower(subs[j].Title)
+	})
+
+	folders := make(map[string]*Outline)
+	var names []string
+	var untagged []Outline
+
+	for _, sub := range subs {
+		outline := feedOutline(sub)
+
+		tags := tagsByFeed[sub.FeedID]
+		if len(tags) == 0 {
+			untagged = append(untagged, outline)
+			continue
+		}
+
+		for _, name := range tags {
+			folder, ok := folders[name]
+			if !ok {
+				folder = &Outline{Text: name, Title: name}
+				folders[name] = folder
+				names = append(names, name)
+			}
+			folder.Outlines = append(folder.Outlines, outline)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+
+	doc := &OPML{
+		Version: OPMLVersion,
+		Head: OPMLHead{
+			Title:       "Feedbin Subscriptions",
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, name := range names {
+		doc.Body.Outlines = append(doc.Body.Outlines, *folders[name])
+	}
+	doc.Body.Outlines = append(doc.Body.Outlines, untagged...)
+
+	return doc
+}
+
+// feedOutline returns the outline of a single subscription
+func feedOutline(sub Subscription) Outline {
+	title := sub.Title
+	if title == "" {
+		title = sub.FeedURL
+	}
+
+	return Outline{
+		Text:    title,
+		Title:   title,
+		Type:    "rss",
+		XMLURL:  sub.FeedURL,
+		HTMLURL: sub.SiteURL,
+	}
+}
+
+// ParseOPML reads an OPML document. Besides UTF-8, documents declared as
+// ISO-8859-1, Windows-1252 or US-ASCII are accepted, as written by some
+// other feed readers.
+func ParseOPML(r io.Reader) (*OPML, error) {
+	doc := new(OPML)
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = opmlCharsetReader
+	if err := dec.Decode(doc); err != nil {
+		return nil, fmt.Errorf("feedbin: invalid OPML: %v", err)
+	}
+
+	return doc, nil
+}
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to runes; the other
+// bytes are the same as in ISO-8859-1. Unassigned bytes map to U+FFFD.
+var windows1252 = [32]rune{
+	'\u20AC', '\uFFFD', '\u201A', '\u0192', '\u201E', '\u2026', '\u2020', '\u2021',
+	'\u02C6', '\u2030', '\u0160', '\u2039', '\u0152', '\uFFFD', '\u017D', '\uFFFD',
+	'\uFFFD', '\u2018', '\u2019', '\u201C', '\u201D', '\u2022', '\u2013', '\u2014',
+	'\u02DC', '\u2122', '\u0161', '\u203A', '\u0153', '\uFFFD', '\u017E', '\u0178',
+}
+
+// opmlCharsetReader converts single-byte encodings to UTF-8 for the XML
+// decoder, which only reads UTF-8 itself
+func opmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	var table *[32]rune
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "l1", "us-ascii", "ascii":
+	case "windows-1252", "cp1252":
+		table = &windows1252
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(data))
+	for _, c := range data {
+		if table != nil && c >= 0x80 && c < 0xA0 {
+			buf.WriteRune(table[c-0x80])
+		} else {
+			buf.WriteRune(rune(c))
+		}
+	}
+
+	return strings.NewReader(buf.String()), nil
+}
+
+// WriteTo writes the document as indented XML, including the XML header
+func (o *OPML) WriteTo(w io.Writer) (int64, error) {
+	data, err := o.Bytes()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Bytes returns the document as indented XML, including the XML header
+func (o *OPML) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(o); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// String returns the document as XML, ready for ImportsService.Create
+func (o *OPML) String() string {
+	data, err := o.Bytes()
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// Feeds returns the feeds in the document, in document order. A feed that
+// appears in several folders is returned once, with the names of all its
+// folders as tags. Nested folders use the name of the innermost folder.
+func (o *OPML) Feeds() []OPMLFeed {
+	var feeds []OPMLFeed
+	index := make(map[string]int)
+
+	var walk func(outlines []Outline, tag string)
+	walk = func(outlines []Outline, tag string) {
+		for _, outline := range outlines {
+			if outline.XMLURL == "" {
+				name := outline.Title
+				if name == "" {
+					name = outline.Text
+				}
+				walk(outline.Outlines, name)
+				continue
+			}
+
+			i, ok := index[outline.XMLURL]
+			if !ok {
+				title := outline.Title
+				if title == "" {
+					title = outline.Text
+				}
+				i = len(feeds)
+				index[outline.XMLURL] = i
+				feeds = append(feeds, OPMLFeed{
+					Title:   title,
+					FeedURL: outline.XMLURL,
+					SiteURL: outline.HTMLURL,
+				})
+			}
+
+			if tag != "" && !containsString(feeds[i].Tags, tag) {
+				feeds[i].Tags = append(feeds[i].Tags, tag)
+			}
+		}
+	}
+	walk(o.Body.Outlines, "")
+
+	return feeds
+}
+
+// containsString reports whether s contains v
+func containsString(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
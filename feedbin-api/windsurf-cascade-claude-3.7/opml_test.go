@@ -0,0 +1,89 @@
+package feedbin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// TestOPMLRoundTrip exports subscriptions and taggings, parses the XML
+// back and checks that the feeds and their tags are unchanged
+func TestOPMLRoundTrip(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/subscriptions.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": 1, "feed_id": 10, "title": "Daring Fireball", "feed_url": "https://daringfireball.net/feeds/main", "site_url": "https://daringfireball.net/"},
+			{"id": 2, "feed_id": 20, "title": "Ars & \"Technica\"", "feed_url": "https://feeds.arstechnica.com/arstechnica/index?format=xml&x=1", "site_url": "https://arstechnica.com"},
+			{"id": 3, "feed_id": 30, "title": "Untagged", "feed_url": "https://example.com/feed.xml", "site_url": "https://example.com"}
+		]`))
+	})
+	mux.HandleFunc("/taggings.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": 1, "feed_id": 10, "name": "Apple"},
+			{"id": 2, "feed_id": 20, "name": "Tech"},
+			{"id": 3, "feed_id": 10, "name": "Tech"}
+		]`))
+	})
+
+	f := New("user", "pass")
+	f.Client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	doc, err := f.ExportOPML()
+	if err != nil {
+		t.Fatalf("ExportOPML returned error: %v", err)
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned error: %v", err)
+	}
+
+	parsed, err := ParseOPML(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseOPML returned error: %v", err)
+	}
+
+	want := []OPMLFeed{
+		{Title: "Daring Fireball", FeedURL: "https://daringfireball.net/feeds/main", SiteURL: "https://daringfireball.net/", Tags: []string{"Apple", "Tech"}},
+		{Title: "Ars & \"Technica\"", FeedURL: "https://feeds.arstechnica.com/arstechnica/index?format=xml&x=1", SiteURL: "https://arstechnica.com", Tags: []string{"Tech"}},
+		{Title: "Untagged", FeedURL: "https://example.com/feed.xml", SiteURL: "https://example.com"},
+	}
+	if got := parsed.Feeds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Feeds() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseOPML_Charset(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		title string
+	}{
+		{"utf-8", []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?><opml version=\"1.0\"><body><outline text=\"Caf\xc3\xa9\" xmlUrl=\"https://example.com/feed\"/></body></opml>"), "Café"},
+		{"iso-8859-1", []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><opml version=\"1.0\"><body><outline text=\"Caf\xe9\" xmlUrl=\"https://example.com/feed\"/></body></opml>"), "Café"},
+		{"windows-1252", []byte("<?xml version=\"1.0\" encoding=\"windows-1252\"?><opml version=\"1.0\"><body><outline text=\"\x93Quoted\x94\" xmlUrl=\"https://example.com/feed\"/></body></opml>"), "“Quoted”"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseOPML(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ParseOPML returned error: %v", err)
+			}
+			feeds := doc.Feeds()
+			if len(feeds) != 1 || feeds[0].Title != tt.title {
+				t.Errorf("Feeds() = %+v, want title %q", feeds, tt.title)
+			}
+		})
+	}
+
+	_, err := ParseOPML(bytes.NewReader([]byte(`<?xml version="1.0" encoding="EBCDIC"?><opml/>`)))
+	if err == nil {
+		t.Error("Expected an error for an unsupported charset")
+	}
+}
@@ -0,0 +1,139 @@
+package feedbinsync
+
+import (
+	"time"
+
+	feedbin "github.com/feedbin/feedbin-go"
+)
+
+// Attribute is an entry state that can be changed offline
+type Attribute string
+
+const (
+	// Unread is the unread state of an entry
+	Unread Attribute = "unread"
+
+	// Starred is the starred state of an entry
+	Starred Attribute = "starred"
+)
+
+// Change is a queued offline change to the state of an entry
+type Change struct {
+	EntryID   int       `json:"entry_id"`
+	Attribute Attribute `json:"attribute"`
+
+	// Value is the state the change sets, e.g. false for a read on Unread
+	Value bool `json:"value"`
+
+	// Base is the state last seen on the server when the change was queued
+	Base bool `json:"base"`
+
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// Cursor records how far previous syncs got, so later syncs only ask for changes
+type Cursor struct {
+	// LastSync is when the last successful sync started
+	LastSync time.Time `json:"last_sync"`
+
+	// LastFullSync is when subscriptions were last fetched without since
+	LastFullSync time.Time `json:"last_full_sync"`
+
+	// Subscriptions is the newest subscription created_at seen
+	Subscriptions time.Time `json:"subscriptions"`
+
+	// Entries is the newest entry created_at seen
+	Entries time.Time `json:"entries"`
+
+	// UpdatedEntries is the since value for the next GetUpdatedEntries call
+	UpdatedEntries time.Time `json:"updated_entries"`
+}
+
+// State is the local mirror of a Feedbin account, as persisted by a Store.
+//
+// Unread and Starred hold the server state as of the last sync; local
+// changes live in Pending until they are replayed.
+type State struct {
+	Subscriptions map[int]feedbin.Subscription `json:"subscriptions"`
+	Taggings      map[int]feedbin.Tagging      `json:"taggings"`
+	Entries       map[int]feedbin.Entry        `json:"entries"`
+	Unread        map[int]bool                 `json:"unread"`
+	Starred       map[int]bool                 `json:"starred"`
+	Pending       []Change                     `json:"pending"`
+	Cursor        Cursor                       `json:"cursor"`
+}
+
+// NewState returns an empty state
+func NewState() *State {
+	return &State{
+		Subscriptions: make(map[int]feedbin.Subscription),
+		Taggings:      make(map[int]feedbin.Tagging),
+		Entries:       make(map[int]feedbin.Entry),
+		Unread:        make(map[int]bool),
+		Starred:       make(map[int]bool),
+	}
+}
+
+// ensureMaps fills in maps missing from a decoded state
+func (s *State) ensureMaps() {
+	if s.Subscriptions == nil {
+		s.Subscriptions = make(map[int]feedbin.Subscription)
+	}
+	if s.Taggings == nil {
+		s.Taggings = make(map[int]feedbin.Tagging)
+	}
+	if s.Entries == nil {
+		s.Entries = make(map[int]feedbin.Entry)
+	}
+	if s.Unread == nil {
+		s.Unread = make(map[int]bool)
+	}
+	if s.Starred == nil {
+		s.Starred = make(map[int]bool)
+	}
+}
+
+// serverValue returns the last known server state of an entry
+func (s *State) serverValue(attr Attribute, entryID int) bool {
+	if attr == Starred {
+		return s.Starred[entryID]
+	}
+	return s.Unread[entryID]
+}
+
+// localValue returns the state of an entry with pending changes applied
+func (s *State) localValue(attr Attribute, entryID int) bool {
+	for i := len(s.Pending) - 1; i >= 0; i-- {
+		c := s.Pending[i]
+		if c.EntryID == entryID && c.Attribute == attr {
+			return c.Value
+		}
+	}
+	return s.serverValue(attr, entryID)
+}
+
+// queue records a local change. A pending change for the same entry and
+// attribute is replaced, keeping its base; changes that bring an entry back
+// to its base state cancel out.
+func (s *State) queue(attr Attribute, entryID int, value bool, now time.Time) {
+	base := s.serverValue(attr, entryID)
+	for i, c := range s.Pending {
+		if c.EntryID == entryID && c.Attribute == attr {
+			base = c.Base
+			s.Pending = append(s.Pending[:i], s.Pending[i+1:]...)
+			break
+		}
+	}
+
+	if value == base {
+		return
+	}
+
+	s.Pending = append(s.Pending, Change{
+		EntryID:   entryID,
+		Attribute: attr,
+		Value:     value,
+		Base:      base,
+		QueuedAt:  now,
+	})
+}
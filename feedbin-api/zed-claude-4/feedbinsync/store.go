@@ -0,0 +1,120 @@
+package feedbinsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the local mirror between runs
+type Store interface {
+	// Load returns the saved state, or an empty state if nothing was saved yet
+	Load() (*State, error)
+
+	// Save replaces the saved state
+	Save(state *State) error
+}
+
+// FileStore is a Store that keeps the state in a single JSON file
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a store that reads and writes the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load reads the state file. A missing file yields an empty state.
+func (f *FileStore) Load() (*State, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %v", err)
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to decode sync state: %v", err)
+	}
+	state.ensureMaps()
+	return state, nil
+}
+
+// Save writes the state to a temporary file and renames it over the state
+// file, so an interrupted save never leaves a truncated mirror behind.
+func (f *FileStore) Save(state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %v", err)
+	}
+
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create sync state directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write sync state: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write sync state: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write sync state: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write sync state: %v", err)
+	}
+	return nil
+}
+
+// MemoryStore is a Store that keeps a copy of the state in memory, for tests
+// and short-lived programs
+type MemoryStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load returns a copy of the saved state
+func (m *MemoryStore) Load() (*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := NewState()
+	if m.data == nil {
+		return state, nil
+	}
+	if err := json.Unmarshal(m.data, state); err != nil {
+		return nil, fmt.Errorf("failed to decode sync state: %v", err)
+	}
+	state.ensureMaps()
+	return state, nil
+}
+
+// Save stores a copy of the state
+func (m *MemoryStore) Save(state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync state: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = data
+	return nil
+}
@@ -0,0 +1,398 @@
+package feedbinsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	feedbin "github.com/feedbin/feedbin-go"
+)
+
+// Report describes what a Sync did
+type Report struct {
+	// Pushed are the queued changes sent to the server
+	Pushed []Change
+
+	// Converged are the queued changes the server already had
+	Converged []Change
+
+	// Rejected are the queued changes the server did not acknowledge
+	Rejected []Change
+
+	// FullSync is set when subscriptions were fetched without since
+	FullSync bool
+
+	SubscriptionsAdded   int
+	SubscriptionsRemoved int
+	EntriesAdded         int
+	EntriesUpdated       int
+}
+
+// Sync replays queued changes and pulls what changed on the server since the
+// last sync. If the server cannot be reached, the mirror and the queue are
+// left as they were and the error is returned, so Sync can simply be called
+// again once the network is back.
+func (s *Syncer) Sync(ctx context.Context) (*Report, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	start := s.opts.Now()
+	report := &Report{}
+
+	s.mu.Lock()
+	pending := append([]Change(nil), s.state.Pending...)
+	cursor := s.state.Cursor
+	known := make(map[int]bool, len(s.state.Entries))
+	for id := range s.state.Entries {
+		known[id] = true
+	}
+	s.mu.Unlock()
+
+	unread, err := s.client.GetUnreadEntries(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get unread entries: %w", err)
+	}
+	starred, err := s.client.GetStarredEntries(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get starred entries: %w", err)
+	}
+	server := map[Attribute]map[int]bool{
+		Unread:  idSet(unread),
+		Starred: idSet(starred),
+	}
+
+	resolved, pushErr := s.push(ctx, pending, server, report)
+
+	s.mu.Lock()
+	s.state.Unread = server[Unread]
+	s.state.Starred = server[Starred]
+	s.state.removePending(resolved)
+	saveErr := s.store.Save(s.state)
+	s.mu.Unlock()
+
+	if pushErr != nil {
+		return report, pushErr
+	}
+	if saveErr != nil {
+		return report, saveErr
+	}
+
+	if err := s.pull(ctx, start, cursor, known, server, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// push sends pending changes according to the conflict rules of the package
+// documentation and updates server with the acknowledged changes. It returns
+// the changes that are resolved and can leave the queue.
+func (s *Syncer) push(ctx context.Context, pending []Change, server map[Attribute]map[int]bool, report *Report) ([]Change, error) {
+	var resolved []Change
+
+	type group struct {
+		attr  Attribute
+		value bool
+	}
+	groups := []group{{Unread, false}, {Unread, true}, {Starred, true}, {Starred, false}}
+	toSend := make(map[group][]Change)
+
+	for _, c := range pending {
+		if server[c.Attribute][c.EntryID] == c.Value {
+			report.Converged = append(report.Converged, c)
+			resolved = append(resolved, c)
+			continue
+		}
+		g := group{c.Attribute, c.Value}
+		toSend[g] = append(toSend[g], c)
+	}
+
+	for _, g := range groups {
+		changes := toSend[g]
+		for start := 0; start < len(changes); start += maxBulkIDs {
+			end := start + maxBulkIDs
+			if end > len(changes) {
+				end = len(changes)
+			}
+			batch := changes[start:end]
+
+			ids := make([]int, len(batch))
+			for i, c := range batch {
+				ids[i] = c.EntryID
+			}
+
+			acked, err := s.send(ctx, g.attr, g.value, ids)
+			if err != nil {
+				return resolved, fmt.Errorf("failed to replay %s changes: %w", g.attr, err)
+			}
+
+			ackedSet := idSet(acked)
+			for _, c := range batch {
+				if !ackedSet[c.EntryID] {
+					report.Rejected = append(report.Rejected, c)
+				} else {
+					report.Pushed = append(report.Pushed, c)
+					if c.Value {
+						server[c.Attribute][c.EntryID] = true
+					} else {
+						delete(server[c.Attribute], c.EntryID)
+					}
+				}
+				resolved = append(resolved, c)
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// send calls the client method that sets attr to value on entryIDs
+func (s *Syncer) send(ctx context.Context, attr Attribute, value bool, entryIDs []int) ([]int, error) {
+	switch {
+	case attr == Unread && value:
+		return s.client.MarkAsUnread(ctx, entryIDs)
+	case attr == Unread:
+		return s.client.MarkAsRead(ctx, entryIDs)
+	case value:
+		return s.client.StarEntries(ctx, entryIDs)
+	default:
+		return s.client.UnstarEntries(ctx, entryIDs)
+	}
+}
+
+// pull fetches subscriptions, taggings and entries and merges them into the mirror
+func (s *Syncer) pull(ctx context.Context, start time.Time, cursor Cursor, known map[int]bool, server map[Attribute]map[int]bool, report *Report) error {
+	full := cursor.LastFullSync.IsZero() || start.Sub(cursor.LastFullSync) >= s.opts.FullSyncInterval
+	report.FullSync = full
+
+	subsOpts := &feedbin.SubscriptionOptions{}
+	if !full && !cursor.Subscriptions.IsZero() {
+		subsOpts.Since = feedbin.Time(cursor.Subscriptions)
+	}
+	subs, err := s.fetchSubscriptions(ctx, subsOpts)
+	if err != nil {
+		return err
+	}
+
+	taggings, err := s.client.GetTaggings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get taggings: %w", err)
+	}
+
+	entriesSince := cursor.Entries
+	if entriesSince.IsZero() {
+		entriesSince = start.Add(-s.opts.InitialWindow)
+	}
+	entries, err := s.fetchEntries(ctx, entriesSince)
+	if err != nil {
+		return err
+	}
+
+	fetched := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		fetched[entry.ID] = true
+	}
+
+	// Entries changed after publication, for the entries already mirrored
+	var refetch []int
+	if !cursor.UpdatedEntries.IsZero() {
+		updated, err := s.client.GetUpdatedEntries(ctx, &feedbin.UpdatedEntriesOptions{Since: feedbin.Time(cursor.UpdatedEntries)})
+		if err != nil {
+			return fmt.Errorf("failed to get updated entries: %w", err)
+		}
+		for _, id := range updated {
+			if known[id] && !fetched[id] {
+				refetch = append(refetch, id)
+				fetched[id] = true
+			}
+		}
+	}
+	updatedCount := len(refetch)
+
+	// Unread and starred entries outside the entries window
+	for _, attr := range []Attribute{Unread, Starred} {
+		for _, id := range sortedIDs(server[attr]) {
+			if !known[id] && !fetched[id] {
+				refetch = append(refetch, id)
+				fetched[id] = true
+			}
+		}
+	}
+
+	byID, err := s.fetchEntriesByIDs(ctx, refetch)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, byID...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state
+	previous := state.Subscriptions
+	if full {
+		for id := range previous {
+			if !containsSubscription(subs, id) {
+				report.SubscriptionsRemoved++
+			}
+		}
+		state.Subscriptions = make(map[int]feedbin.Subscription, len(subs))
+	}
+	for _, sub := range subs {
+		if _, ok := previous[sub.ID]; !ok {
+			report.SubscriptionsAdded++
+		}
+		state.Subscriptions[sub.ID] = sub
+		if sub.CreatedAt.After(state.Cursor.Subscriptions) {
+			state.Cursor.Subscriptions = sub.CreatedAt
+		}
+	}
+
+	state.Taggings = make(map[int]feedbin.Tagging, len(taggings))
+	for _, tagging := range taggings {
+		state.Taggings[tagging.ID] = tagging
+	}
+
+	for _, entry := range entries {
+		if _, ok := state.Entries[entry.ID]; !ok {
+			report.EntriesAdded++
+		}
+		state.Entries[entry.ID] = entry
+		if entry.CreatedAt.After(state.Cursor.Entries) {
+			state.Cursor.Entries = entry.CreatedAt
+		}
+	}
+	report.EntriesUpdated = updatedCount
+
+	if full {
+		state.pruneUnsubscribed()
+		state.Cursor.LastFullSync = start
+	}
+	state.Cursor.UpdatedEntries = start.Add(-updatedOverlap)
+	state.Cursor.LastSync = start
+
+	return s.store.Save(state)
+}
+
+// fetchSubscriptions gets every page of subscriptions matching opts
+func (s *Syncer) fetchSubscriptions(ctx context.Context, opts *feedbin.SubscriptionOptions) ([]feedbin.Subscription, error) {
+	subs, pagination, err := s.client.GetSubscriptions(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	for pagination != nil && pagination.HasNext() {
+		var page []feedbin.Subscription
+		page, pagination, err = s.client.GetSubscriptionsFromURL(ctx, pagination.Next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+		}
+		subs = append(subs, page...)
+	}
+
+	return subs, nil
+}
+
+// fetchEntries gets every page of entries created after since
+func (s *Syncer) fetchEntries(ctx context.Context, since time.Time) ([]feedbin.Entry, error) {
+	entries, pagination, err := s.client.GetEntries(ctx, &feedbin.EntryOptions{
+		Since:   feedbin.Time(since),
+		PerPage: feedbin.Int(s.opts.PerPage),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+
+	for pagination != nil && pagination.HasNext() {
+		var page []feedbin.Entry
+		page, pagination, err = s.client.GetEntriesFromURL(ctx, pagination.Next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entries: %w", err)
+		}
+		entries = append(entries, page...)
+	}
+
+	return entries, nil
+}
+
+// fetchEntriesByIDs gets entries in requests of at most 100 IDs
+func (s *Syncer) fetchEntriesByIDs(ctx context.Context, ids []int) ([]feedbin.Entry, error) {
+	var entries []feedbin.Entry
+	for start := 0; start < len(ids); start += maxEntryIDs {
+		end := start + maxEntryIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch, err := s.client.GetEntriesByIDs(ctx, ids[start:end], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entries by ID: %w", err)
+		}
+		entries = append(entries, batch...)
+	}
+	return entries, nil
+}
+
+// removePending drops resolved changes from the queue. Changes that were
+// replaced by a newer local change while the sync was running are kept.
+func (s *State) removePending(resolved []Change) {
+	if len(resolved) == 0 {
+		return
+	}
+
+	type key struct {
+		entryID  int
+		attr     Attribute
+		value    bool
+		queuedAt int64
+	}
+	keyOf := func(c Change) key {
+		return key{c.EntryID, c.Attribute, c.Value, c.QueuedAt.UnixNano()}
+	}
+
+	done := make(map[key]bool, len(resolved))
+	for _, c := range resolved {
+		done[keyOf(c)] = true
+	}
+
+	kept := s.Pending[:0]
+	for _, c := range s.Pending {
+		if !done[keyOf(c)] {
+			kept = append(kept, c)
+		}
+	}
+	s.Pending = kept
+}
+
+// pruneUnsubscribed drops entries of feeds that are no longer subscribed,
+// except starred entries, which stay available on Feedbin too
+func (s *State) pruneUnsubscribed() {
+	feeds := make(map[int]bool, len(s.Subscriptions))
+	for _, sub := range s.Subscriptions {
+		feeds[sub.FeedID] = true
+	}
+
+	for id, entry := range s.Entries {
+		if !feeds[entry.FeedID] && !s.Starred[id] {
+			delete(s.Entries, id)
+		}
+	}
+}
+
+// containsSubscription reports whether subs has a subscription with the given ID
+func containsSubscription(subs []feedbin.Subscription, id int) bool {
+	for _, sub := range subs {
+		if sub.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// idSet converts a list of IDs to a set
+func idSet(ids []int) map[int]bool {
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,245 @@
+package feedbinsync
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	feedbin "github.com/feedbin/feedbin-go"
+	"github.com/feedbin/feedbin-go/feedbintest"
+)
+
+// clock is a manually advanced time source shared by the fake server and the syncer
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type fixture struct {
+	srv    *feedbintest.Server
+	client *feedbin.Client
+	clock  *clock
+	feed   feedbintest.Feed
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	srv := feedbintest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.SetClock(clk.Now)
+	srv.AddUser("test@example.com", "password")
+	srv.AddUser("other@example.com", "password")
+
+	client := feedbin.NewClientWithHTTPClient("test@example.com", "password", srv.Client())
+	if err := client.SetBaseURL(srv.URL); err != nil {
+		t.Fatalf("SetBaseURL() error = %v", err)
+	}
+
+	feed := srv.AddFeed(feedbintest.Feed{Title: "Example", FeedURL: "https://example.com/feed.xml"})
+	if _, _, err := client.CreateSubscription(context.Background(), feed.FeedURL); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	if _, err := client.CreateTagging(context.Background(), feed.ID, "Tech"); err != nil {
+		t.Fatalf("CreateTagging() error = %v", err)
+	}
+
+	return &fixture{srv: srv, client: client, clock: clk, feed: feed}
+}
+
+func (f *fixture) addEntries(t *testing.T, n int) []int {
+	t.Helper()
+
+	var ids []int
+	for i := 0; i < n; i++ {
+		f.clock.Advance(time.Minute)
+		entry, err := f.srv.AddEntry(f.feed.ID, feedbintest.Entry{Title: feedbintest.String("Entry")})
+		if err != nil {
+			t.Fatalf("AddEntry() error = %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func (f *fixture) newSyncer(t *testing.T, store Store) *Syncer {
+	t.Helper()
+
+	syncer, err := New(f.client, store, &Options{PerPage: 2, Now: f.clock.Now})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return syncer
+}
+
+func TestInitialAndIncrementalSync(t *testing.T) {
+	f := newFixture(t)
+	ids := f.addEntries(t, 3)
+	syncer := f.newSyncer(t, NewMemoryStore())
+	ctx := context.Background()
+
+	report, err := syncer.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !report.FullSync || report.SubscriptionsAdded != 1 || report.EntriesAdded != 3 {
+		t.Errorf("Unexpected first sync report: %+v", report)
+	}
+	if got := len(syncer.Taggings()); got != 1 {
+		t.Errorf("Expected 1 tagging, got %d", got)
+	}
+	if got := syncer.UnreadEntryIDs(); len(got) != 3 {
+		t.Errorf("Expected 3 unread entries, got %v", got)
+	}
+
+	// A new entry and an edit to an existing one
+	f.clock.Advance(time.Hour)
+	newIDs := f.addEntries(t, 1)
+	if err := f.srv.UpdateEntry(ids[0], func(e *feedbintest.Entry) { e.Title = feedbintest.String("Edited") }); err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+
+	report, err = syncer.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.FullSync {
+		t.Error("Expected an incremental sync")
+	}
+	if report.EntriesAdded != 1 || report.EntriesUpdated != 1 {
+		t.Errorf("Unexpected incremental sync report: %+v", report)
+	}
+	if _, ok := syncer.Entry(newIDs[0]); !ok {
+		t.Errorf("Expected entry %d to be mirrored", newIDs[0])
+	}
+	if entry, _ := syncer.Entry(ids[0]); entry.Title == nil || *entry.Title != "Edited" {
+		t.Errorf("Expected entry %d to be refreshed, got %+v", ids[0], entry.Title)
+	}
+}
+
+func TestOfflineChangesAreReplayed(t *testing.T) {
+	f := newFixture(t)
+	ids := f.addEntries(t, 3)
+	store := NewFileStore(filepath.Join(t.TempDir(), "mirror.json"))
+	syncer := f.newSyncer(t, store)
+	ctx := context.Background()
+
+	if _, err := syncer.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Go offline
+	if err := f.client.SetBaseURL("http://127.0.0.1:1"); err != nil {
+		t.Fatalf("SetBaseURL() error = %v", err)
+	}
+
+	if err := syncer.MarkAsRead(ids[:2]); err != nil {
+		t.Fatalf("MarkAsRead() error = %v", err)
+	}
+	if err := syncer.StarEntries(ids[2:]); err != nil {
+		t.Fatalf("StarEntries() error = %v", err)
+	}
+	// Read then unread again cancels out
+	syncer.MarkAsRead(ids[2:])
+	syncer.MarkAsUnread(ids[2:])
+
+	if got := syncer.UnreadEntryIDs(); len(got) != 1 || got[0] != ids[2] {
+		t.Errorf("Expected only entry %d unread locally, got %v", ids[2], got)
+	}
+	if got := len(syncer.Pending()); got != 3 {
+		t.Fatalf("Expected 3 pending changes, got %d", got)
+	}
+
+	if _, err := syncer.Sync(ctx); err == nil {
+		t.Fatal("Expected Sync() to fail while offline")
+	}
+	if got := len(syncer.Pending()); got != 3 {
+		t.Fatalf("Expected pending changes to survive a failed sync, got %d", got)
+	}
+
+	// Restart from the file store and reconnect
+	if err := f.client.SetBaseURL(f.srv.URL); err != nil {
+		t.Fatalf("SetBaseURL() error = %v", err)
+	}
+	syncer = f.newSyncer(t, store)
+
+	report, err := syncer.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(report.Pushed) != 3 || len(syncer.Pending()) != 0 {
+		t.Errorf("Expected 3 pushed changes and an empty queue, got %+v", report)
+	}
+
+	if unread := f.srv.UnreadEntryIDs("test@example.com"); len(unread) != 1 || unread[0] != ids[2] {
+		t.Errorf("Expected server unread [%d], got %v", ids[2], unread)
+	}
+	if starred := f.srv.StarredEntryIDs("test@example.com"); len(starred) != 1 || starred[0] != ids[2] {
+		t.Errorf("Expected server starred [%d], got %v", ids[2], starred)
+	}
+}
+
+func TestConflictRules(t *testing.T) {
+	f := newFixture(t)
+	ids := f.addEntries(t, 2)
+	syncer := f.newSyncer(t, NewMemoryStore())
+	ctx := context.Background()
+
+	if _, err := syncer.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Queue a read for both entries, then read the first one on another device
+	if err := syncer.MarkAsRead(ids); err != nil {
+		t.Fatalf("MarkAsRead() error = %v", err)
+	}
+	if _, err := f.client.MarkAsRead(ctx, ids[:1]); err != nil {
+		t.Fatalf("MarkAsRead() error = %v", err)
+	}
+
+	// Queue a star for an entry the account cannot see
+	other := f.srv.AddFeed(feedbintest.Feed{Title: "Other", FeedURL: "https://other.example.com/feed.xml"})
+	hidden, err := f.srv.AddEntry(other.ID, feedbintest.Entry{})
+	if err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+	if err := syncer.StarEntries([]int{hidden.ID}); err != nil {
+		t.Fatalf("StarEntries() error = %v", err)
+	}
+
+	report, err := syncer.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if len(report.Converged) != 1 || report.Converged[0].EntryID != ids[0] {
+		t.Errorf("Expected entry %d to converge, got %+v", ids[0], report.Converged)
+	}
+	if len(report.Pushed) != 1 || report.Pushed[0].EntryID != ids[1] {
+		t.Errorf("Expected entry %d to be pushed, got %+v", ids[1], report.Pushed)
+	}
+	if len(report.Rejected) != 1 || report.Rejected[0].EntryID != hidden.ID {
+		t.Errorf("Expected entry %d to be rejected, got %+v", hidden.ID, report.Rejected)
+	}
+	if len(syncer.Pending()) != 0 {
+		t.Errorf("Expected an empty queue, got %+v", syncer.Pending())
+	}
+	if syncer.IsStarred(hidden.ID) {
+		t.Errorf("Expected rejected star on entry %d to be dropped", hidden.ID)
+	}
+}
@@ -0,0 +1,297 @@
+// Package feedbinsync mirrors a Feedbin account into a local store so it can
+// be read and changed offline.
+//
+// A Syncer keeps subscriptions, taggings, entries and the unread and starred
+// entry IDs in a Store. The first Sync downloads the account; later syncs use
+// since on GetSubscriptions and GetEntries plus GetUpdatedEntries, so they only
+// transfer what changed:
+//
+//	client := feedbin.NewClient("user@example.com", "password")
+//	syncer, err := feedbinsync.New(client, feedbinsync.NewFileStore("feedbin.json"), nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	// Works offline: the change is applied locally and queued
+//	syncer.MarkAsRead([]int{4087})
+//
+//	// Replays queued changes, then pulls what changed on the server
+//	report, err := syncer.Sync(ctx)
+//
+// Conflicts:
+//
+// Every queued change remembers the state the server had when it was queued
+// (its base). Several changes to the same entry and attribute collapse into
+// one, and a change that brings an entry back to its base is dropped. On sync,
+// the current server state decides what happens to each change:
+//
+//   - the server still has the base state: the local change wins and is sent
+//     with MarkAsRead, MarkAsUnread, StarEntries or UnstarEntries
+//   - the server already has the new state, because another client made the
+//     same change: the change is dropped and reported as converged
+//   - the server does not acknowledge the entry, e.g. because its feed was
+//     unsubscribed: the change is dropped and reported as rejected
+//
+// Changes that fail with a network or server error stay queued for the next sync.
+package feedbinsync
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	feedbin "github.com/feedbin/feedbin-go"
+)
+
+const (
+	// DefaultInitialWindow is how far back the first sync fetches entries
+	DefaultInitialWindow = 30 * 24 * time.Hour
+
+	// DefaultFullSyncInterval is how often subscriptions are fetched without since,
+	// to notice renamed and deleted subscriptions
+	DefaultFullSyncInterval = 24 * time.Hour
+
+	// DefaultPerPage is the page size used to fetch entries
+	DefaultPerPage = 100
+
+	// maxBulkIDs is the limit of entry IDs per unread/starred request
+	maxBulkIDs = 1000
+
+	// maxEntryIDs is the limit of IDs per GetEntriesByIDs request
+	maxEntryIDs = 100
+
+	// updatedOverlap is subtracted from the sync start time used as the next
+	// GetUpdatedEntries since value, to absorb clock skew with the server
+	updatedOverlap = 5 * time.Minute
+)
+
+// Options configures a Syncer
+type Options struct {
+	// InitialWindow is how far back the first sync fetches entries. Unread and
+	// starred entries are always fetched, whatever their age.
+	InitialWindow time.Duration
+
+	// FullSyncInterval is how often subscriptions are fetched in full
+	FullSyncInterval time.Duration
+
+	// PerPage is the page size used to fetch entries
+	PerPage int
+
+	// Now returns the current time (optional, defaults to time.Now)
+	Now func() time.Time
+}
+
+// Syncer mirrors a Feedbin account into a Store. It is safe for concurrent
+// use; local changes can be made while a sync is running.
+type Syncer struct {
+	client *feedbin.Client
+	store  Store
+	opts   Options
+
+	syncMu sync.Mutex // serializes Sync calls
+
+	mu    sync.Mutex // guards state
+	state *State
+}
+
+// New loads the mirror from store and returns a Syncer for client
+func New(client *feedbin.Client, store Store, opts *Options) (*Syncer, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.InitialWindow <= 0 {
+		o.InitialWindow = DefaultInitialWindow
+	}
+	if o.FullSyncInterval <= 0 {
+		o.FullSyncInterval = DefaultFullSyncInterval
+	}
+	if o.PerPage <= 0 {
+		o.PerPage = DefaultPerPage
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	state.ensureMaps()
+
+	return &Syncer{
+		client: client,
+		store:  store,
+		opts:   o,
+		state:  state,
+	}, nil
+}
+
+// MarkAsRead marks entries as read locally and queues the change
+func (s *Syncer) MarkAsRead(entryIDs []int) error {
+	return s.queue(Unread, entryIDs, false)
+}
+
+// MarkAsUnread marks entries as unread locally and queues the change
+func (s *Syncer) MarkAsUnread(entryIDs []int) error {
+	return s.queue(Unread, entryIDs, true)
+}
+
+// StarEntries stars entries locally and queues the change
+func (s *Syncer) StarEntries(entryIDs []int) error {
+	return s.queue(Starred, entryIDs, true)
+}
+
+// UnstarEntries unstars entries locally and queues the change
+func (s *Syncer) UnstarEntries(entryIDs []int) error {
+	return s.queue(Starred, entryIDs, false)
+}
+
+// queue applies a local change to entryIDs and saves the queue
+func (s *Syncer) queue(attr Attribute, entryIDs []int, value bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.opts.Now()
+	for _, id := range entryIDs {
+		s.state.queue(attr, id, value, now)
+	}
+	return s.store.Save(s.state)
+}
+
+// Pending returns the queued changes, oldest first
+func (s *Syncer) Pending() []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Change(nil), s.state.Pending...)
+}
+
+// LastSync returns when the last successful sync started, or the zero time
+func (s *Syncer) LastSync() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.Cursor.LastSync
+}
+
+// Subscriptions returns the mirrored subscriptions, ordered by ID
+func (s *Syncer) Subscriptions() []feedbin.Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]feedbin.Subscription, 0, len(s.state.Subscriptions))
+	for _, sub := range s.state.Subscriptions {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs
+}
+
+// Taggings returns the mirrored taggings, ordered by ID
+func (s *Syncer) Taggings() []feedbin.Tagging {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taggings := make([]feedbin.Tagging, 0, len(s.state.Taggings))
+	for _, tagging := range s.state.Taggings {
+		taggings = append(taggings, tagging)
+	}
+	sort.Slice(taggings, func(i, j int) bool { return taggings[i].ID < taggings[j].ID })
+	return taggings
+}
+
+// Entry returns a mirrored entry
+func (s *Syncer) Entry(id int) (feedbin.Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.state.Entries[id]
+	return entry, ok
+}
+
+// Entries returns the mirrored entries, newest first
+func (s *Syncer) Entries() []feedbin.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]feedbin.Entry, 0, len(s.state.Entries))
+	for _, entry := range s.state.Entries {
+		entries = append(entries, entry)
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// UnreadEntryIDs returns the IDs of unread entries, including local changes
+func (s *Syncer) UnreadEntryIDs() []int {
+	return s.localIDs(Unread)
+}
+
+// StarredEntryIDs returns the IDs of starred entries, including local changes
+func (s *Syncer) StarredEntryIDs() []int {
+	return s.localIDs(Starred)
+}
+
+// IsUnread reports whether an entry is unread, including local changes
+func (s *Syncer) IsUnread(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.localValue(Unread, id)
+}
+
+// IsStarred reports whether an entry is starred, including local changes
+func (s *Syncer) IsStarred(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.localValue(Starred, id)
+}
+
+// localIDs returns the sorted IDs that have attr set once pending changes are applied
+func (s *Syncer) localIDs(attr Attribute) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := make(map[int]bool)
+	server := s.state.Unread
+	if attr == Starred {
+		server = s.state.Starred
+	}
+	for id := range server {
+		set[id] = true
+	}
+	for _, c := range s.state.Pending {
+		if c.Attribute != attr {
+			continue
+		}
+		if c.Value {
+			set[c.EntryID] = true
+		} else {
+			delete(set, c.EntryID)
+		}
+	}
+
+	return sortedIDs(set)
+}
+
+// sortedIDs returns the keys of set in ascending order
+func sortedIDs(set map[int]bool) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// sortEntries orders entries by created_at descending, newest first
+func sortEntries(entries []feedbin.Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID > entries[j].ID
+		}
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+}
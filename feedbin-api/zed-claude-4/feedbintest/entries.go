@@ -0,0 +1,255 @@
+package feedbintest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entryFilter holds the parsed query parameters of an entries request
+type entryFilter struct {
+	since   time.Time
+	ids     map[int]bool
+	read    *bool
+	starred *bool
+	render  renderOptions
+}
+
+// renderOptions controls which optional entry keys are included
+type renderOptions struct {
+	extended           bool
+	includeOriginal    bool
+	includeEnclosure   bool
+	includeContentDiff bool
+}
+
+// handleEntries serves /entries.json and /entries/:id.json
+func (s *Server) handleEntries(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	if len(rest) == 0 {
+		filter, err := parseEntryFilter(r.URL.Query(), true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.listEntries(w, r, u, s.subscribedFeeds(u), filter, false)
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok || len(rest) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+	rec, found := s.entries[id]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.subscribedFeeds(u)[rec.FeedID] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	filter, err := parseEntryFilter(r.URL.Query(), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, renderEntry(rec, filter.render))
+}
+
+// handleFeeds serves /feeds/:id.json and /feeds/:id/entries.json
+func (s *Server) handleFeeds(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+	if len(rest) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	feed, found := s.feeds[id]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(rest) == 1:
+		writeJSON(w, http.StatusOK, feed)
+	case len(rest) == 2 && rest[1] == "entries.json":
+		if !s.subscribedFeeds(u)[feed.ID] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		filter, err := parseEntryFilter(r.URL.Query(), false)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.listEntries(w, r, u, map[int]bool{feed.ID: true}, filter, true)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listEntries writes one page of the entries in feeds matching filter. Pages
+// past the end answer 404 when strictPages is set, as feed entries do.
+func (s *Server) listEntries(w http.ResponseWriter, r *http.Request, u *account, feeds map[int]bool, filter entryFilter, strictPages bool) {
+	page, perPage, err := pageParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var matched []*entryRecord
+	for _, rec := range s.entries {
+		if !feeds[rec.FeedID] {
+			continue
+		}
+		if filter.ids != nil && !filter.ids[rec.ID] {
+			continue
+		}
+		if !filter.since.IsZero() && !rec.CreatedAt.After(filter.since) {
+			continue
+		}
+		if filter.read != nil && *filter.read == u.unread[rec.ID] {
+			continue
+		}
+		if filter.starred != nil && *filter.starred != u.starred[rec.ID] {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+	sortEntries(matched)
+
+	start, end, lastPage := pageBounds(len(matched), page, perPage)
+	if strictPages && page > lastPage {
+		http.NotFound(w, r)
+		return
+	}
+
+	out := make([]Entry, 0, end-start)
+	for _, rec := range matched[start:end] {
+		out = append(out, renderEntry(rec, filter.render))
+	}
+
+	setPaginationHeaders(w, r, len(matched), page, lastPage)
+	writeJSON(w, http.StatusOK, out)
+}
+
+// sortEntries orders entries by created_at descending, newest first
+func sortEntries(entries []*entryRecord) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID > entries[j].ID
+		}
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+}
+
+// parseEntryFilter parses the query parameters shared by the entries endpoints
+func parseEntryFilter(query url.Values, allowIDs bool) (entryFilter, error) {
+	var f entryFilter
+
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid since parameter %q", v)
+		}
+		f.since = t
+	}
+
+	if v := query.Get("ids"); v != "" && allowIDs {
+		parts := strings.Split(v, ",")
+		if len(parts) > MaxEntryIDs {
+			return f, fmt.Errorf("a maximum of %d ids can be requested", MaxEntryIDs)
+		}
+		f.ids = make(map[int]bool, len(parts))
+		for _, p := range parts {
+			id, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				return f, fmt.Errorf("invalid id %q", p)
+			}
+			f.ids[id] = true
+		}
+	}
+
+	var err error
+	if f.read, err = parseOptionalBool(query, "read"); err != nil {
+		return f, err
+	}
+	if f.starred, err = parseOptionalBool(query, "starred"); err != nil {
+		return f, err
+	}
+
+	f.render.extended = query.Get("mode") == "extended"
+	f.render.includeOriginal = query.Get("include_original") == "true"
+	f.render.includeEnclosure = query.Get("include_enclosure") == "true"
+	f.render.includeContentDiff = query.Get("include_content_diff") == "true"
+
+	return f, nil
+}
+
+func parseOptionalBool(query url.Values, key string) (*bool, error) {
+	v := query.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter %q", key, v)
+	}
+	return &b, nil
+}
+
+// renderEntry returns the API representation of an entry, dropping the
+// optional keys the request did not ask for
+func renderEntry(rec *entryRecord, opts renderOptions) Entry {
+	out := rec.Entry
+	out.ContentDiff = nil
+
+	if !opts.extended && !opts.includeOriginal {
+		out.Original = nil
+	}
+	if !opts.extended && !opts.includeEnclosure {
+		out.Enclosure = nil
+	}
+	if opts.includeContentDiff && rec.Original != nil {
+		out.ContentDiff = String(contentDiff(rec.Original.Content, rec.Content))
+	}
+
+	return out
+}
+
+// contentDiff produces a minimal HTML diff in the markup Feedbin uses
+func contentDiff(before, after *string) string {
+	var b, a string
+	if before != nil {
+		b = *before
+	}
+	if after != nil {
+		a = *after
+	}
+	if a == b {
+		return `<div class="inline-diff">` + a + `</div>`
+	}
+	return `<div class="inline-diff"><del class="diff-del">` + html.EscapeString(b) +
+		`</del><ins class="diff-ins">` + html.EscapeString(a) + `</ins></div>`
+}
@@ -0,0 +1,137 @@
+package feedbintest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// handleUnreadEntries serves /unread_entries.json and its POST delete alternative
+func (s *Server) handleUnreadEntries(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.visibleIDs(u, u.unread))
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.applyBulk(w, r, u, "unread_entries", func(id int) { u.unread[id] = true })
+	case len(rest) == 0 && r.Method == http.MethodDelete,
+		len(rest) == 1 && rest[0] == "delete.json" && r.Method == http.MethodPost:
+		s.applyBulk(w, r, u, "unread_entries", func(id int) { delete(u.unread, id) })
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleStarredEntries serves /starred_entries.json and its POST delete alternative
+func (s *Server) handleStarredEntries(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.visibleIDs(u, u.starred))
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.applyBulk(w, r, u, "starred_entries", func(id int) { u.starred[id] = true })
+	case len(rest) == 0 && r.Method == http.MethodDelete,
+		len(rest) == 1 && rest[0] == "delete.json" && r.Method == http.MethodPost:
+		s.applyBulk(w, r, u, "starred_entries", func(id int) { delete(u.starred, id) })
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleUpdatedEntries serves /updated_entries.json and its POST delete alternative
+func (s *Server) handleUpdatedEntries(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		var since time.Time
+		if v := r.URL.Query().Get("since"); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				http.Error(w, "invalid since parameter", http.StatusBadRequest)
+				return
+			}
+			since = t
+		}
+
+		set := make(map[int]bool)
+		for id, at := range u.updated {
+			if since.IsZero() || at.After(since) {
+				set[id] = true
+			}
+		}
+		writeJSON(w, http.StatusOK, s.visibleIDs(u, set))
+	case len(rest) == 0 && r.Method == http.MethodDelete,
+		len(rest) == 1 && rest[0] == "delete.json" && r.Method == http.MethodPost:
+		s.applyBulk(w, r, u, "updated_entries", func(id int) { delete(u.updated, id) })
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// handleRecentlyReadEntries serves /recently_read_entries.json
+func (s *Server) handleRecentlyReadEntries(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) != 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		ids := make([]int, 0, len(u.recentlyRead))
+		feeds := s.subscribedFeeds(u)
+		for _, id := range u.recentlyRead {
+			if rec, ok := s.entries[id]; ok && feeds[rec.FeedID] {
+				ids = append(ids, id)
+			}
+		}
+		writeJSON(w, http.StatusOK, ids)
+	case http.MethodPost:
+		s.applyBulk(w, r, u, "recently_read_entries", func(id int) {
+			// Most recent first, without duplicates
+			kept := []int{id}
+			for _, existing := range u.recentlyRead {
+				if existing != id {
+					kept = append(kept, existing)
+				}
+			}
+			u.recentlyRead = kept
+		})
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// applyBulk decodes a {"<key>": [ids]} body, applies fn to every entry the
+// user can access and answers with the IDs that were applied
+func (s *Server) applyBulk(w http.ResponseWriter, r *http.Request, u *account, key string, fn func(id int)) {
+	var req map[string][]int
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	ids, ok := req[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("missing %s parameter", key), http.StatusBadRequest)
+		return
+	}
+	if len(ids) > MaxBulkIDs {
+		http.Error(w, fmt.Sprintf("a maximum of %d entry_ids can be sent", MaxBulkIDs), http.StatusBadRequest)
+		return
+	}
+
+	feeds := s.subscribedFeeds(u)
+	applied := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		rec, found := s.entries[id]
+		if !found || !feeds[rec.FeedID] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		fn(id)
+		applied = append(applied, id)
+	}
+	if key != "recently_read_entries" {
+		sort.Ints(applied)
+	}
+
+	writeJSON(w, http.StatusOK, applied)
+}
@@ -0,0 +1,45 @@
+package feedbintest
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// handleIcons serves GET /icons.json with the icons of subscribed sites
+func (s *Server) handleIcons(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) != 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	hosts := make(map[string]bool)
+	for feedID := range s.subscribedFeeds(u) {
+		feed, ok := s.feeds[feedID]
+		if !ok {
+			continue
+		}
+		site := feed.SiteURL
+		if site == "" {
+			site = feed.FeedURL
+		}
+		if parsed, err := url.Parse(site); err == nil && parsed.Host != "" {
+			hosts[strings.ToLower(parsed.Host)] = true
+		}
+	}
+
+	icons := make([]Icon, 0)
+	for host := range hosts {
+		if iconURL, ok := s.icons[host]; ok {
+			icons = append(icons, Icon{Host: host, URL: iconURL})
+		}
+	}
+	sort.Slice(icons, func(i, j int) bool { return icons[i].Host < icons[j].Host })
+
+	writeJSON(w, http.StatusOK, icons)
+}
@@ -0,0 +1,137 @@
+package feedbintest
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// opmlOutline is the subset of an OPML outline the fake reads
+type opmlOutline struct {
+	Title    string        `xml:"title,attr"`
+	Text     string        `xml:"text,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+// handleImports serves /imports.json and /imports/:id.json. A new import
+// starts with every item pending; it is processed the first time it is read
+// back, subscribing to every feed the server knows about.
+func (s *Server) handleImports(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			imports := make([]Import, 0)
+			for _, imp := range s.imports {
+				if imp.owner == u.email {
+					summary := imp.Import
+					summary.ImportItems = nil
+					imports = append(imports, summary)
+				}
+			}
+			sort.Slice(imports, func(i, j int) bool { return imports[i].ID < imports[j].ID })
+			writeJSON(w, http.StatusOK, imports)
+		case http.MethodPost:
+			s.createImport(w, r, u)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok || len(rest) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+	imp, found := s.imports[id]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if imp.owner != u.email {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w)
+		return
+	}
+
+	if !imp.processed {
+		s.processImport(u, imp)
+	}
+	writeJSON(w, http.StatusOK, imp.Import)
+}
+
+func (s *Server) createImport(w http.ResponseWriter, r *http.Request, u *account) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var doc opmlDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		http.Error(w, "invalid OPML", http.StatusUnprocessableEntity)
+		return
+	}
+
+	imp := &importRecord{
+		Import: Import{
+			ID:          s.allocID("import"),
+			CreatedAt:   s.now().UTC(),
+			ImportItems: make([]ImportItem, 0),
+		},
+		owner: u.email,
+	}
+	collectImportItems(doc.Outlines, &imp.ImportItems)
+	s.imports[imp.ID] = imp
+
+	writeJSON(w, http.StatusOK, imp.Import)
+}
+
+// collectImportItems flattens nested OPML outlines into pending import items
+func collectImportItems(outlines []opmlOutline, items *[]ImportItem) {
+	for _, o := range outlines {
+		if o.XMLURL != "" {
+			title := o.Title
+			if title == "" {
+				title = o.Text
+			}
+			*items = append(*items, ImportItem{Title: title, FeedURL: o.XMLURL, Status: ImportStatusPending})
+		}
+		collectImportItems(o.Outlines, items)
+	}
+}
+
+// processImport resolves every pending item of an import
+func (s *Server) processImport(u *account, imp *importRecord) {
+	for i, item := range imp.ImportItems {
+		feeds := s.findFeeds(item.FeedURL)
+		if len(feeds) != 1 {
+			imp.ImportItems[i].Status = ImportStatusFailed
+			continue
+		}
+
+		subscribed := false
+		for _, sub := range s.subscriptions {
+			if sub.owner == u.email && sub.FeedID == feeds[0].ID {
+				subscribed = true
+				break
+			}
+		}
+		if !subscribed {
+			s.subscribe(u, feeds[0])
+		}
+		imp.ImportItems[i].Status = ImportStatusComplete
+	}
+
+	imp.processed = true
+	imp.Complete = true
+}
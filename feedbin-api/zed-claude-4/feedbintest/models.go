@@ -0,0 +1,124 @@
+package feedbintest
+
+import "time"
+
+// Feed represents a feed known to the fake server. Feeds exist independently
+// of users; a user sees a feed's entries once they subscribe to it.
+type Feed struct {
+	ID       int       `json:"id"`
+	Title    string    `json:"title"`
+	FeedURL  string    `json:"feed_url"`
+	SiteURL  string    `json:"site_url"`
+	JSONFeed *JSONFeed `json:"-"`
+}
+
+// JSONFeed contains the extra metadata returned for subscriptions in extended mode
+type JSONFeed struct {
+	Favicon     string `json:"favicon,omitempty"`
+	FeedURL     string `json:"feed_url,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Version     string `json:"version,omitempty"`
+	HomePageURL string `json:"home_page_url,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+// Subscription represents a feed subscription as returned by the API
+type Subscription struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	FeedID    int       `json:"feed_id"`
+	Title     string    `json:"title"`
+	FeedURL   string    `json:"feed_url"`
+	SiteURL   string    `json:"site_url"`
+	JSONFeed  *JSONFeed `json:"json_feed,omitempty"`
+}
+
+// Entry represents a feed entry as returned by the API
+type Entry struct {
+	ID                  int        `json:"id"`
+	FeedID              int        `json:"feed_id"`
+	Title               *string    `json:"title"`
+	URL                 string     `json:"url"`
+	ExtractedContentURL string     `json:"extracted_content_url"`
+	Author              *string    `json:"author"`
+	Content             *string    `json:"content"`
+	Summary             string     `json:"summary"`
+	Published           time.Time  `json:"published"`
+	CreatedAt           time.Time  `json:"created_at"`
+	Original            *Original  `json:"original,omitempty"`
+	Enclosure           *Enclosure `json:"enclosure,omitempty"`
+	ContentDiff         *string    `json:"content_diff,omitempty"`
+}
+
+// Original holds the original version of an entry that has since been updated
+type Original struct {
+	Author    *string   `json:"author"`
+	Content   *string   `json:"content"`
+	Title     *string   `json:"title"`
+	URL       string    `json:"url"`
+	EntryID   string    `json:"entry_id"`
+	Published time.Time `json:"published"`
+	Data      any       `json:"data"`
+}
+
+// Enclosure represents podcast/RSS enclosure data
+type Enclosure struct {
+	EnclosureURL    string `json:"enclosure_url"`
+	EnclosureType   string `json:"enclosure_type"`
+	EnclosureLength string `json:"enclosure_length,omitempty"`
+	ItunesDuration  string `json:"itunes_duration,omitempty"`
+	ItunesImage     string `json:"itunes_image,omitempty"`
+}
+
+// Tagging represents a feed tag assignment
+type Tagging struct {
+	ID     int    `json:"id"`
+	FeedID int    `json:"feed_id"`
+	Name   string `json:"name"`
+}
+
+// FeedChoice is one option of a 300 Multiple Choices response
+type FeedChoice struct {
+	FeedURL string `json:"feed_url"`
+	Title   string `json:"title"`
+}
+
+// SavedSearch represents a saved search
+type SavedSearch struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// Icon represents a feed icon
+type Icon struct {
+	Host string `json:"host"`
+	URL  string `json:"url"`
+}
+
+// Import represents an OPML import
+type Import struct {
+	ID          int          `json:"id"`
+	Complete    bool         `json:"complete"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ImportItems []ImportItem `json:"import_items,omitempty"`
+}
+
+// ImportItem represents an individual feed in an import
+type ImportItem struct {
+	Title   string `json:"title"`
+	FeedURL string `json:"feed_url"`
+	Status  string `json:"status"`
+}
+
+// Import item statuses
+const (
+	ImportStatusPending  = "pending"
+	ImportStatusComplete = "complete"
+	ImportStatusFailed   = "failed"
+)
+
+// String returns a pointer to s, for filling nullable Entry fields
+func String(s string) *string {
+	return &s
+}
@@ -0,0 +1,56 @@
+package feedbintest
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// handlePages serves POST /pages.json. Saved pages become entries of a
+// per-user pages feed that is always visible to its owner.
+func (s *Server) handlePages(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) != 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w)
+		return
+	}
+
+	var req struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" {
+		http.Error(w, "url is required", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if u.pagesFeedID == 0 {
+		feed := &Feed{ID: s.allocID("feed"), Title: "Pages", FeedURL: "pages://" + u.email}
+		s.feeds[feed.ID] = feed
+		u.pagesFeedID = feed.ID
+	}
+
+	title := req.Title
+	if title == "" {
+		title = parsed.Host
+	}
+	now := s.now().UTC()
+	rec := &entryRecord{Entry: Entry{
+		ID:        s.allocID("entry"),
+		FeedID:    u.pagesFeedID,
+		Title:     String(title),
+		URL:       req.URL,
+		Published: now,
+		CreatedAt: now,
+	}}
+	s.entries[rec.ID] = rec
+	u.unread[rec.ID] = true
+
+	writeJSON(w, http.StatusOK, rec.Entry)
+}
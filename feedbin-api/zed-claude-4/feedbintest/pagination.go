@@ -0,0 +1,74 @@
+package feedbintest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pageParams reads the page and per_page query parameters
+func pageParams(query url.Values) (page, perPage int, err error) {
+	page, perPage = 1, DefaultPerPage
+
+	if v := query.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid page parameter %q", v)
+		}
+	}
+	if v := query.Get("per_page"); v != "" {
+		perPage, err = strconv.Atoi(v)
+		if err != nil || perPage < 1 {
+			return 0, 0, fmt.Errorf("invalid per_page parameter %q", v)
+		}
+	}
+
+	return page, perPage, nil
+}
+
+// pageBounds returns the slice bounds of a page and the number of pages
+func pageBounds(total, page, perPage int) (start, end, lastPage int) {
+	lastPage = (total + perPage - 1) / perPage
+	if lastPage == 0 {
+		lastPage = 1
+	}
+
+	start = (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end = start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end, lastPage
+}
+
+// setPaginationHeaders writes the Link and X-Feedbin-Record-Count headers the
+// way Feedbin does for paginated collections
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, total, page, lastPage int) {
+	w.Header().Set("X-Feedbin-Record-Count", strconv.Itoa(total))
+
+	pageURL := func(p int) string {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(p))
+		return "http://" + r.Host + r.URL.Path + "?" + q.Encode()
+	}
+
+	var links []string
+	if page > 1 {
+		links = append(links,
+			fmt.Sprintf(`<%s>; rel="first"`, pageURL(1)),
+			fmt.Sprintf(`<%s>; rel="prev"`, pageURL(page-1)))
+	}
+	if page < lastPage {
+		links = append(links,
+			fmt.Sprintf(`<%s>; rel="next"`, pageURL(page+1)),
+			fmt.Sprintf(`<%s>; rel="last"`, pageURL(lastPage)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,179 @@
+package feedbintest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// handleSavedSearches serves /saved_searches.json and /saved_searches/:id.json
+func (s *Server) handleSavedSearches(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			searches := make([]SavedSearch, 0)
+			for _, ss := range s.savedSearches {
+				if ss.owner == u.email {
+					searches = append(searches, ss.SavedSearch)
+				}
+			}
+			sort.Slice(searches, func(i, j int) bool { return searches[i].ID < searches[j].ID })
+			writeJSON(w, http.StatusOK, searches)
+		case http.MethodPost:
+			s.createSavedSearch(w, r, u)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ss, found := s.savedSearches[id]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if ss.owner != u.email {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if len(rest) == 2 && rest[1] == "update.json" && r.Method == http.MethodPost {
+		s.updateSavedSearch(w, r, ss)
+		return
+	}
+	if len(rest) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.runSavedSearch(w, r, u, ss)
+	case http.MethodPatch:
+		s.updateSavedSearch(w, r, ss)
+	case http.MethodDelete:
+		delete(s.savedSearches, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) createSavedSearch(w http.ResponseWriter, r *http.Request, u *account) {
+	var req struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.Query == "" {
+		http.Error(w, "name and query are required", http.StatusUnprocessableEntity)
+		return
+	}
+
+	ss := &savedSearchRecord{
+		SavedSearch: SavedSearch{ID: s.allocID("saved_search"), Name: req.Name, Query: req.Query},
+		owner:       u.email,
+	}
+	s.savedSearches[ss.ID] = ss
+
+	w.Header().Set("Location", location(r, fmt.Sprintf("/saved_searches/%d.json", ss.ID)))
+	writeJSON(w, http.StatusCreated, ss.SavedSearch)
+}
+
+func (s *Server) updateSavedSearch(w http.ResponseWriter, r *http.Request, ss *savedSearchRecord) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	ss.Name = req.Name
+	writeJSON(w, http.StatusOK, ss.SavedSearch)
+}
+
+// runSavedSearch answers with the matching entry IDs, or the entries
+// themselves when include_entries=true, one page at a time
+func (s *Server) runSavedSearch(w http.ResponseWriter, r *http.Request, u *account, ss *savedSearchRecord) {
+	query := r.URL.Query()
+	page, perPage, err := pageParams(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feeds := s.subscribedFeeds(u)
+	var matched []*entryRecord
+	for _, rec := range s.entries {
+		if feeds[rec.FeedID] && matchesQuery(ss.Query, rec, u) {
+			matched = append(matched, rec)
+		}
+	}
+	sortEntries(matched)
+
+	start, end, lastPage := pageBounds(len(matched), page, perPage)
+	setPaginationHeaders(w, r, len(matched), page, lastPage)
+
+	if query.Get("include_entries") == "true" {
+		entries := make([]Entry, 0, end-start)
+		for _, rec := range matched[start:end] {
+			entries = append(entries, renderEntry(rec, renderOptions{}))
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+
+	ids := make([]int, 0, end-start)
+	for _, rec := range matched[start:end] {
+		ids = append(ids, rec.ID)
+	}
+	writeJSON(w, http.StatusOK, ids)
+}
+
+// matchesQuery implements a small subset of the Feedbin search syntax: every
+// bare term must appear in the title, author or content, and is:unread,
+// is:read, is:starred and feed_id: narrow the results further
+func matchesQuery(query string, rec *entryRecord, u *account) bool {
+	var text strings.Builder
+	for _, p := range []*string{rec.Title, rec.Author, rec.Content} {
+		if p != nil {
+			text.WriteString(strings.ToLower(*p))
+			text.WriteString(" ")
+		}
+	}
+	haystack := text.String()
+
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		switch {
+		case term == "is:unread":
+			if !u.unread[rec.ID] {
+				return false
+			}
+		case term == "is:read":
+			if u.unread[rec.ID] {
+				return false
+			}
+		case term == "is:starred":
+			if !u.starred[rec.ID] {
+				return false
+			}
+		case strings.HasPrefix(term, "feed_id:"):
+			if strings.TrimPrefix(term, "feed_id:") != fmt.Sprint(rec.FeedID) {
+				return false
+			}
+		default:
+			if !strings.Contains(haystack, strings.Trim(term, `"`)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,442 @@
+// Package feedbintest provides an in-process, stateful fake of the Feedbin
+// REST API v2 for use in tests.
+//
+// The fake keeps every account, subscription, entry and tagging in memory and
+// answers with the status codes and headers described in specs/content:
+//
+//	srv := feedbintest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddUser("user@example.com", "password")
+//	feed := srv.AddFeed(feedbintest.Feed{
+//		Title:   "Example",
+//		FeedURL: "https://example.com/feed.xml",
+//		SiteURL: "https://example.com/",
+//	})
+//	srv.AddEntry(feed.ID, feedbintest.Entry{Title: feedbintest.String("Hello")})
+//
+//	client := feedbin.NewClientWithHTTPClient("user@example.com", "password", srv.Client())
+//	client.SetBaseURL(srv.URL)
+//
+// Requests are accepted both with and without the "/v2" path prefix.
+package feedbintest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPerPage is the page size used when a request has no per_page parameter
+	DefaultPerPage = 100
+
+	// MaxEntryIDs is the maximum number of ids accepted by GET /entries.json
+	MaxEntryIDs = 100
+
+	// MaxBulkIDs is the maximum number of entry IDs accepted by bulk
+	// unread/starred requests
+	MaxBulkIDs = 1000
+
+	jsonContentType = "application/json; charset=utf-8"
+)
+
+// Server is a fake Feedbin API server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the running server, without a trailing slash
+	URL string
+
+	srv *httptest.Server
+	mu  sync.Mutex
+	now func() time.Time
+
+	users         map[string]*account
+	feeds         map[int]*Feed
+	entries       map[int]*entryRecord
+	subscriptions map[int]*subscriptionRecord
+	taggings      map[int]*taggingRecord
+	savedSearches map[int]*savedSearchRecord
+	imports       map[int]*importRecord
+	icons         map[string]string
+
+	nextID map[string]int
+}
+
+// account holds the per-user state of the fake
+type account struct {
+	email        string
+	password     string
+	unread       map[int]bool
+	starred      map[int]bool
+	updated      map[int]time.Time
+	recentlyRead []int
+	pagesFeedID  int
+}
+
+type entryRecord struct {
+	Entry
+	updatedAt time.Time
+}
+
+type subscriptionRecord struct {
+	Subscription
+	owner string
+}
+
+type taggingRecord struct {
+	Tagging
+	owner string
+}
+
+type savedSearchRecord struct {
+	SavedSearch
+	owner string
+}
+
+type importRecord struct {
+	Import
+	owner     string
+	processed bool
+}
+
+// NewServer starts and returns a new fake server. The caller should call
+// Close when finished to shut it down.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a fake server that is not yet listening. It can
+// be used as a plain http.Handler or started later with Start.
+func NewUnstartedServer() *Server {
+	return &Server{
+		now:           time.Now,
+		users:         make(map[string]*account),
+		feeds:         make(map[int]*Feed),
+		entries:       make(map[int]*entryRecord),
+		subscriptions: make(map[int]*subscriptionRecord),
+		taggings:      make(map[int]*taggingRecord),
+		savedSearches: make(map[int]*savedSearchRecord),
+		imports:       make(map[int]*importRecord),
+		icons:         make(map[string]string),
+		nextID:        make(map[string]int),
+	}
+}
+
+// Start starts the server on a local loopback address
+func (s *Server) Start() {
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	if s.srv != nil {
+		s.srv.Close()
+	}
+}
+
+// Client returns an HTTP client configured for making requests to the server
+func (s *Server) Client() *http.Client {
+	if s.srv == nil {
+		return http.DefaultClient
+	}
+	return s.srv.Client()
+}
+
+// SetClock replaces the time source used for created_at and updated timestamps
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// AddUser registers an account that can authenticate with the given credentials
+func (s *Server) AddUser(email, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[email] = &account{
+		email:    email,
+		password: password,
+		unread:   make(map[int]bool),
+		starred:  make(map[int]bool),
+		updated:  make(map[int]time.Time),
+	}
+}
+
+// AddFeed registers a feed that users can subscribe to and returns it with
+// its assigned ID. Feeds sharing a SiteURL make a subscription request for
+// that site answer 300 Multiple Choices.
+func (s *Server) AddFeed(feed Feed) Feed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feed.ID = s.allocID("feed")
+	f := feed
+	s.feeds[f.ID] = &f
+	return f
+}
+
+// AddEntry adds an entry to a feed and marks it unread for every subscriber.
+// ID and FeedID are assigned by the server; zero timestamps default to now.
+func (s *Server) AddEntry(feedID int, entry Entry) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.feeds[feedID]; !ok {
+		return Entry{}, fmt.Errorf("feedbintest: unknown feed %d", feedID)
+	}
+
+	now := s.now().UTC()
+	entry.ID = s.allocID("entry")
+	entry.FeedID = feedID
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	if entry.Published.IsZero() {
+		entry.Published = entry.CreatedAt
+	}
+	s.entries[entry.ID] = &entryRecord{Entry: entry}
+
+	for _, sub := range s.subscriptions {
+		if sub.FeedID == feedID {
+			s.users[sub.owner].unread[entry.ID] = true
+		}
+	}
+
+	return entry, nil
+}
+
+// UpdateEntry changes an existing entry the way a publisher edit would. The
+// previous version is kept as the entry's original and the entry is added to
+// the updated entries of every subscriber.
+func (s *Server) UpdateEntry(entryID int, update func(*Entry)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.entries[entryID]
+	if !ok {
+		return fmt.Errorf("feedbintest: unknown entry %d", entryID)
+	}
+
+	if rec.Original == nil {
+		rec.Original = &Original{
+			Author:    rec.Author,
+			Content:   rec.Content,
+			Title:     rec.Title,
+			URL:       rec.URL,
+			EntryID:   strconv.Itoa(rec.ID),
+			Published: rec.Published,
+		}
+	}
+
+	update(&rec.Entry)
+	rec.ID = entryID
+	rec.updatedAt = s.now().UTC()
+
+	for _, sub := range s.subscriptions {
+		if sub.FeedID == rec.FeedID {
+			s.users[sub.owner].updated[entryID] = rec.updatedAt
+		}
+	}
+
+	return nil
+}
+
+// AddIcon registers the favicon URL for a host
+func (s *Server) AddIcon(host, iconURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.icons[host] = iconURL
+}
+
+// UnreadEntryIDs returns the unread entry IDs of a user, for assertions
+func (s *Server) UnreadEntryIDs(email string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[email]; ok {
+		return s.visibleIDs(u, u.unread)
+	}
+	return nil
+}
+
+// StarredEntryIDs returns the starred entry IDs of a user, for assertions
+func (s *Server) StarredEntryIDs(email string) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[email]; ok {
+		return s.visibleIDs(u, u.starred)
+	}
+	return nil
+}
+
+// allocID returns the next ID for a kind of resource. Callers must hold s.mu.
+func (s *Server) allocID(kind string) int {
+	s.nextID[kind]++
+	return s.nextID[kind]
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.authenticate(r)
+	if u == nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Feedbin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !checkContentType(r) {
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch strings.TrimSuffix(parts[0], ".json") {
+	case "authentication":
+		w.WriteHeader(http.StatusOK)
+	case "subscriptions":
+		s.handleSubscriptions(w, r, u, parts[1:])
+	case "entries":
+		s.handleEntries(w, r, u, parts[1:])
+	case "feeds":
+		s.handleFeeds(w, r, u, parts[1:])
+	case "unread_entries":
+		s.handleUnreadEntries(w, r, u, parts[1:])
+	case "starred_entries":
+		s.handleStarredEntries(w, r, u, parts[1:])
+	case "updated_entries":
+		s.handleUpdatedEntries(w, r, u, parts[1:])
+	case "recently_read_entries":
+		s.handleRecentlyReadEntries(w, r, u, parts[1:])
+	case "taggings":
+		s.handleTaggings(w, r, u, parts[1:])
+	case "tags":
+		s.handleTags(w, r, u, parts[1:])
+	case "saved_searches":
+		s.handleSavedSearches(w, r, u, parts[1:])
+	case "imports":
+		s.handleImports(w, r, u, parts[1:])
+	case "pages":
+		s.handlePages(w, r, u, parts[1:])
+	case "icons":
+		s.handleIcons(w, r, u, parts[1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authenticate resolves the account for the request's basic auth credentials
+func (s *Server) authenticate(r *http.Request) *account {
+	email, password, ok := r.BasicAuth()
+	if !ok {
+		return nil
+	}
+	u, found := s.users[email]
+	if !found || u.password != password {
+		return nil
+	}
+	return u
+}
+
+// checkContentType enforces the JSON content type on requests with a body.
+// Imports are the exception and must be sent as text/xml.
+func checkContentType(r *http.Request) bool {
+	if r.ContentLength == 0 || r.Method == http.MethodGet {
+		return true
+	}
+
+	ct := strings.ToLower(strings.ReplaceAll(r.Header.Get("Content-Type"), " ", ""))
+	if strings.Contains(r.URL.Path, "imports") {
+		return strings.HasPrefix(ct, "text/xml")
+	}
+	return ct == "application/json;charset=utf-8"
+}
+
+// parseID parses a path segment such as "525.json" into its numeric ID
+func parseID(segment string) (int, bool) {
+	id, err := strconv.Atoi(strings.TrimSuffix(segment, ".json"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeBody decodes a JSON request body, answering 400 on failure
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// location builds an absolute URL on this server for a Location header
+func location(r *http.Request, path string) string {
+	prefix := ""
+	if strings.HasPrefix(r.URL.Path, "/v2/") {
+		prefix = "/v2"
+	}
+	return "http://" + r.Host + prefix + path
+}
+
+// methodNotAllowed answers 405 for unsupported methods on a known route
+func methodNotAllowed(w http.ResponseWriter) {
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+// subscribedFeeds returns the set of feed IDs the user subscribes to
+func (s *Server) subscribedFeeds(u *account) map[int]bool {
+	feeds := make(map[int]bool)
+	for _, sub := range s.subscriptions {
+		if sub.owner == u.email {
+			feeds[sub.FeedID] = true
+		}
+	}
+	if u.pagesFeedID != 0 {
+		feeds[u.pagesFeedID] = true
+	}
+	return feeds
+}
+
+// visibleIDs returns the sorted IDs in set that belong to feeds the user
+// can currently see
+func (s *Server) visibleIDs(u *account, set map[int]bool) []int {
+	feeds := s.subscribedFeeds(u)
+	ids := make([]int, 0, len(set))
+	for id, ok := range set {
+		if !ok {
+			continue
+		}
+		if rec, found := s.entries[id]; found && feeds[rec.FeedID] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
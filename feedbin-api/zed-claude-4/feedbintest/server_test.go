@@ -0,0 +1,191 @@
+package feedbintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testEmail    = "test@example.com"
+	testPassword = "password"
+)
+
+// do sends a request as the given user without following redirects
+func do(t *testing.T, srv *Server, email, method, path string, body interface{}) *http.Response {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, &buf)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.SetBasicAuth(email, testPassword)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(testEmail, testPassword)
+	srv.AddUser("other@example.com", testPassword)
+	return srv
+}
+
+func TestAuthentication(t *testing.T) {
+	srv := newTestServer(t)
+
+	if resp := do(t, srv, testEmail, "GET", "/v2/authentication.json", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if resp := do(t, srv, "nobody@example.com", "GET", "/authentication.json", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestCreateSubscriptionStatusCodes(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddFeed(Feed{Title: "Daring Fireball", FeedURL: "https://daringfireball.net/feeds/main", SiteURL: "https://daringfireball.net/"})
+	srv.AddFeed(Feed{Title: "The GitHub Blog", FeedURL: "https://github.com/blog.atom", SiteURL: "https://blog.github.com/"})
+	srv.AddFeed(Feed{Title: "The GitHub Blog (Broadcasts)", FeedURL: "https://github.com/blog/broadcasts.atom", SiteURL: "https://blog.github.com/"})
+
+	tests := []struct {
+		name       string
+		feedURL    string
+		wantStatus int
+	}{
+		{"created", "daringfireball.net", http.StatusCreated},
+		{"already subscribed", "https://daringfireball.net/feeds/main", http.StatusFound},
+		{"multiple choices", "https://blog.github.com", http.StatusMultipleChoices},
+		{"not found", "https://example.com/nothing.xml", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, srv, testEmail, "POST", "/subscriptions.json", map[string]string{"feed_url": tt.feedURL})
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			switch tt.wantStatus {
+			case http.StatusCreated, http.StatusFound:
+				if !strings.HasSuffix(resp.Header.Get("Location"), "/subscriptions/1.json") {
+					t.Errorf("Unexpected Location header %q", resp.Header.Get("Location"))
+				}
+			case http.StatusMultipleChoices:
+				var choices []FeedChoice
+				if err := json.NewDecoder(resp.Body).Decode(&choices); err != nil {
+					t.Fatalf("Failed to decode choices: %v", err)
+				}
+				if len(choices) != 2 {
+					t.Errorf("Expected 2 choices, got %d", len(choices))
+				}
+			}
+		})
+	}
+}
+
+func TestOtherUsersResourcesAreForbidden(t *testing.T) {
+	srv := newTestServer(t)
+	feed := srv.AddFeed(Feed{Title: "Example", FeedURL: "https://example.com/feed.xml"})
+	entry, err := srv.AddEntry(feed.ID, Entry{Title: String("Hello")})
+	if err != nil {
+		t.Fatalf("AddEntry() error = %v", err)
+	}
+
+	do(t, srv, testEmail, "POST", "/subscriptions.json", map[string]string{"feed_url": feed.FeedURL})
+	do(t, srv, testEmail, "POST", "/taggings.json", map[string]interface{}{"feed_id": feed.ID, "name": "Tech"})
+	do(t, srv, testEmail, "POST", "/saved_searches.json", map[string]string{"name": "Hello", "query": "hello"})
+
+	paths := []string{
+		"/subscriptions/1.json",
+		"/taggings/1.json",
+		"/saved_searches/1.json",
+		fmt.Sprintf("/entries/%d.json", entry.ID),
+		fmt.Sprintf("/feeds/%d/entries.json", feed.ID),
+	}
+	for _, path := range paths {
+		if resp := do(t, srv, "other@example.com", "GET", path, nil); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s: expected status 403, got %d", path, resp.StatusCode)
+		}
+		if resp := do(t, srv, testEmail, "GET", path, nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s: expected status 200 for owner, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestEntriesPaginationHeaders(t *testing.T) {
+	srv := newTestServer(t)
+	feed := srv.AddFeed(Feed{Title: "Example", FeedURL: "https://example.com/feed.xml"})
+	for i := 0; i < 5; i++ {
+		srv.AddEntry(feed.ID, Entry{Title: String(fmt.Sprintf("Entry %d", i))})
+	}
+	do(t, srv, testEmail, "POST", "/subscriptions.json", map[string]string{"feed_url": feed.FeedURL})
+
+	resp := do(t, srv, testEmail, "GET", "/entries.json?per_page=2&page=2", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Feedbin-Record-Count"); got != "5" {
+		t.Errorf("Expected record count 5, got %q", got)
+	}
+
+	link := resp.Header.Get("Link")
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if !strings.Contains(link, `rel="`+rel+`"`) {
+			t.Errorf("Link header %q is missing rel=%q", link, rel)
+		}
+	}
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatalf("Failed to decode entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+
+	resp = do(t, srv, testEmail, "GET", fmt.Sprintf("/feeds/%d/entries.json?per_page=2&page=4", feed.ID), nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 past the last feed page, got %d", resp.StatusCode)
+	}
+}
+
+func TestUnsupportedMediaType(t *testing.T) {
+	srv := newTestServer(t)
+
+	req, _ := http.NewRequest("POST", srv.URL+"/subscriptions.json", strings.NewReader(`{"feed_url":"example.com"}`))
+	req.SetBasicAuth(testEmail, testPassword)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415, got %d", resp.StatusCode)
+	}
+}
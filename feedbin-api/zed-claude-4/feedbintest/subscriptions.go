@@ -0,0 +1,229 @@
+package feedbintest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// handleSubscriptions serves /subscriptions.json and /subscriptions/:id.json
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listSubscriptions(w, r, u)
+		case http.MethodPost:
+			s.createSubscription(w, r, u)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	sub, status := s.ownedSubscription(u, id)
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+
+	// POST /subscriptions/:id/update.json is the PATCH alternative
+	if len(rest) == 2 && rest[1] == "update.json" && r.Method == http.MethodPost {
+		s.updateSubscription(w, r, sub)
+		return
+	}
+	if len(rest) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.renderSubscription(sub, r.URL.Query().Get("mode")))
+	case http.MethodPatch:
+		s.updateSubscription(w, r, sub)
+	case http.MethodDelete:
+		delete(s.subscriptions, sub.ID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+// ownedSubscription looks up a subscription and checks that u owns it
+func (s *Server) ownedSubscription(u *account, id int) (*subscriptionRecord, int) {
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	if sub.owner != u.email {
+		return nil, http.StatusForbidden
+	}
+	return sub, http.StatusOK
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, r *http.Request, u *account) {
+	query := r.URL.Query()
+
+	var since time.Time
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			http.Error(w, "invalid since parameter", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	subs := make([]Subscription, 0)
+	for _, sub := range s.subscriptions {
+		if sub.owner != u.email {
+			continue
+		}
+		if !since.IsZero() && !sub.CreatedAt.After(since) {
+			continue
+		}
+		subs = append(subs, s.renderSubscription(sub, query.Get("mode")))
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+
+	writeJSON(w, http.StatusOK, subs)
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request, u *account) {
+	var req struct {
+		FeedURL string `json:"feed_url"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	candidates := s.findFeeds(req.FeedURL)
+	switch len(candidates) {
+	case 0:
+		http.Error(w, "no feed found", http.StatusNotFound)
+		return
+	case 1:
+	default:
+		choices := make([]FeedChoice, len(candidates))
+		for i, f := range candidates {
+			choices[i] = FeedChoice{FeedURL: f.FeedURL, Title: f.Title}
+		}
+		writeJSON(w, http.StatusMultipleChoices, choices)
+		return
+	}
+
+	feed := candidates[0]
+	for _, sub := range s.subscriptions {
+		if sub.owner == u.email && sub.FeedID == feed.ID {
+			w.Header().Set("Location", location(r, fmt.Sprintf("/subscriptions/%d.json", sub.ID)))
+			writeJSON(w, http.StatusFound, s.renderSubscription(sub, ""))
+			return
+		}
+	}
+
+	sub := s.subscribe(u, feed)
+	w.Header().Set("Location", location(r, fmt.Sprintf("/subscriptions/%d.json", sub.ID)))
+	writeJSON(w, http.StatusCreated, s.renderSubscription(sub, ""))
+}
+
+// subscribe creates a subscription and marks the feed's entries unread
+func (s *Server) subscribe(u *account, feed *Feed) *subscriptionRecord {
+	sub := &subscriptionRecord{
+		Subscription: Subscription{
+			ID:        s.allocID("subscription"),
+			CreatedAt: s.now().UTC(),
+			FeedID:    feed.ID,
+			Title:     feed.Title,
+			FeedURL:   feed.FeedURL,
+			SiteURL:   feed.SiteURL,
+		},
+		owner: u.email,
+	}
+	s.subscriptions[sub.ID] = sub
+
+	for id, rec := range s.entries {
+		if rec.FeedID == feed.ID {
+			u.unread[id] = true
+		}
+	}
+
+	return sub
+}
+
+func (s *Server) updateSubscription(w http.ResponseWriter, r *http.Request, sub *subscriptionRecord) {
+	var req struct {
+		Title string `json:"title"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	sub.Title = req.Title
+	writeJSON(w, http.StatusOK, s.renderSubscription(sub, ""))
+}
+
+// renderSubscription returns the API representation of a subscription
+func (s *Server) renderSubscription(sub *subscriptionRecord, mode string) Subscription {
+	out := sub.Subscription
+	if mode == "extended" {
+		if feed, ok := s.feeds[sub.FeedID]; ok {
+			out.JSONFeed = feed.JSONFeed
+		}
+	}
+	return out
+}
+
+// findFeeds resolves a feed_url parameter to the matching feeds. An exact
+// feed URL wins; otherwise every feed published by the site is a candidate.
+func (s *Server) findFeeds(raw string) []*Feed {
+	target := normalizeURL(raw)
+
+	for _, f := range s.sortedFeeds() {
+		if normalizeURL(f.FeedURL) == target {
+			return []*Feed{f}
+		}
+	}
+
+	var matches []*Feed
+	for _, f := range s.sortedFeeds() {
+		if f.SiteURL != "" && normalizeURL(f.SiteURL) == target {
+			matches = append(matches, f)
+		}
+	}
+	return matches
+}
+
+func (s *Server) sortedFeeds() []*Feed {
+	feeds := make([]*Feed, 0, len(s.feeds))
+	for _, f := range s.feeds {
+		feeds = append(feeds, f)
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].ID < feeds[j].ID })
+	return feeds
+}
+
+// normalizeURL reduces a URL to host and path so that "daringfireball.net",
+// "http://daringfireball.net/" and "https://daringfireball.net" compare equal
+func normalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	result := strings.ToLower(u.Host) + strings.TrimSuffix(u.Path, "/")
+	if u.RawQuery != "" {
+		result += "?" + u.RawQuery
+	}
+	return result
+}
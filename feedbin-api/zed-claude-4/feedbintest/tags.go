@@ -0,0 +1,148 @@
+package feedbintest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// handleTaggings serves /taggings.json and /taggings/:id.json
+func (s *Server) handleTaggings(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.userTaggings(u))
+		case http.MethodPost:
+			s.createTagging(w, r, u)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	id, ok := parseID(rest[0])
+	if !ok || len(rest) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+	tagging, found := s.taggings[id]
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if tagging.owner != u.email {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, tagging.Tagging)
+	case http.MethodDelete:
+		delete(s.taggings, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) createTagging(w http.ResponseWriter, r *http.Request, u *account) {
+	var req struct {
+		FeedID int    `json:"feed_id"`
+		Name   string `json:"name"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.FeedID <= 0 || req.Name == "" {
+		http.Error(w, "feed_id and name are required", http.StatusUnprocessableEntity)
+		return
+	}
+	if !s.subscribedFeeds(u)[req.FeedID] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	for _, t := range s.taggings {
+		if t.owner == u.email && t.FeedID == req.FeedID && t.Name == req.Name {
+			w.Header().Set("Location", location(r, fmt.Sprintf("/taggings/%d.json", t.ID)))
+			writeJSON(w, http.StatusFound, t.Tagging)
+			return
+		}
+	}
+
+	t := &taggingRecord{
+		Tagging: Tagging{ID: s.allocID("tagging"), FeedID: req.FeedID, Name: req.Name},
+		owner:   u.email,
+	}
+	s.taggings[t.ID] = t
+
+	w.Header().Set("Location", location(r, fmt.Sprintf("/taggings/%d.json", t.ID)))
+	writeJSON(w, http.StatusCreated, t.Tagging)
+}
+
+// handleTags serves the tag rename and delete operations on /tags.json
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request, u *account, rest []string) {
+	if len(rest) != 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req struct {
+			OldName string `json:"old_name"`
+			NewName string `json:"new_name"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+
+		// Renaming onto an existing tag merges the two; drop the duplicates
+		existing := make(map[int]bool)
+		for _, t := range s.taggings {
+			if t.owner == u.email && t.Name == req.NewName {
+				existing[t.FeedID] = true
+			}
+		}
+		for id, t := range s.taggings {
+			if t.owner != u.email || t.Name != req.OldName {
+				continue
+			}
+			if existing[t.FeedID] {
+				delete(s.taggings, id)
+				continue
+			}
+			t.Name = req.NewName
+		}
+	case http.MethodDelete:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		for id, t := range s.taggings {
+			if t.owner == u.email && t.Name == req.Name {
+				delete(s.taggings, id)
+			}
+		}
+	default:
+		methodNotAllowed(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.userTaggings(u))
+}
+
+// userTaggings returns the user's taggings ordered by ID
+func (s *Server) userTaggings(u *account) []Tagging {
+	taggings := make([]Tagging, 0)
+	for _, t := range s.taggings {
+		if t.owner == u.email {
+			taggings = append(taggings, t.Tagging)
+		}
+	}
+	sort.Slice(taggings, func(i, j int) bool { return taggings[i].ID < taggings[j].ID })
+	return taggings
+}
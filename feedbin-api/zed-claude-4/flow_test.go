@@ -0,0 +1,162 @@
+package feedbin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/feedbin/feedbin-go/feedbintest"
+)
+
+func newFakeClient(t *testing.T) (*Client, *feedbintest.Server) {
+	t.Helper()
+
+	srv := feedbintest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser("test@example.com", "password")
+
+	client := NewClientWithHTTPClient("test@example.com", "password", srv.Client())
+	if err := client.SetBaseURL(srv.URL); err != nil {
+		t.Fatalf("SetBaseURL() error = %v", err)
+	}
+	return client, srv
+}
+
+func TestSubscribeTagReadStarFlow(t *testing.T) {
+	client, srv := newFakeClient(t)
+	ctx := context.Background()
+
+	feed := srv.AddFeed(feedbintest.Feed{
+		Title:   "Daring Fireball",
+		FeedURL: "https://daringfireball.net/feeds/main",
+		SiteURL: "https://daringfireball.net/",
+	})
+	var entryIDs []int
+	for _, title := range []string{"First", "Second", "Third"} {
+		entry, err := srv.AddEntry(feed.ID, feedbintest.Entry{Title: feedbintest.String(title)})
+		if err != nil {
+			t.Fatalf("AddEntry() error = %v", err)
+		}
+		entryIDs = append(entryIDs, entry.ID)
+	}
+
+	if err := client.Authenticate(ctx); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	sub, _, err := client.CreateSubscription(ctx, "daringfireball.net")
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	if sub.FeedID != feed.ID {
+		t.Errorf("Expected feed ID %d, got %d", feed.ID, sub.FeedID)
+	}
+
+	// Subscribing again follows the 302 to the existing subscription
+	again, _, err := client.CreateSubscription(ctx, feed.FeedURL)
+	if err != nil {
+		t.Fatalf("CreateSubscription() second call error = %v", err)
+	}
+	if again.ID != sub.ID {
+		t.Errorf("Expected existing subscription %d, got %d", sub.ID, again.ID)
+	}
+
+	if _, err := client.CreateTagging(ctx, sub.FeedID, "Apple"); err != nil {
+		t.Fatalf("CreateTagging() error = %v", err)
+	}
+	names, err := client.GetUniqueTagNames(ctx)
+	if err != nil {
+		t.Fatalf("GetUniqueTagNames() error = %v", err)
+	}
+	if len(names) != 1 || names[0] != "Apple" {
+		t.Errorf("Expected tag names [Apple], got %v", names)
+	}
+
+	unread, err := client.GetUnreadEntries(ctx)
+	if err != nil {
+		t.Fatalf("GetUnreadEntries() error = %v", err)
+	}
+	if len(unread) != len(entryIDs) {
+		t.Fatalf("Expected %d unread entries, got %d", len(entryIDs), len(unread))
+	}
+
+	read, err := client.MarkAsRead(ctx, entryIDs[:2])
+	if err != nil {
+		t.Fatalf("MarkAsRead() error = %v", err)
+	}
+	if len(read) != 2 {
+		t.Errorf("Expected 2 entries marked read, got %d", len(read))
+	}
+
+	if _, err := client.StarEntries(ctx, entryIDs[:1]); err != nil {
+		t.Fatalf("StarEntries() error = %v", err)
+	}
+
+	unreadEntries, _, err := client.GetEntries(ctx, &EntryOptions{Read: Bool(false)})
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(unreadEntries) != 1 || unreadEntries[0].ID != entryIDs[2] {
+		t.Errorf("Expected only entry %d to be unread, got %v", entryIDs[2], unreadEntries)
+	}
+
+	starred, err := client.GetStarredEntries(ctx)
+	if err != nil {
+		t.Fatalf("GetStarredEntries() error = %v", err)
+	}
+	if len(starred) != 1 || starred[0] != entryIDs[0] {
+		t.Errorf("Expected starred entries [%d], got %v", entryIDs[0], starred)
+	}
+}
+
+func TestFakeServerPagination(t *testing.T) {
+	client, srv := newFakeClient(t)
+	ctx := context.Background()
+
+	feed := srv.AddFeed(feedbintest.Feed{Title: "Example", FeedURL: "https://example.com/feed.xml"})
+	for i := 0; i < 5; i++ {
+		srv.AddEntry(feed.ID, feedbintest.Entry{})
+	}
+	if _, _, err := client.CreateSubscription(ctx, feed.FeedURL); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+
+	entries, pagination, err := client.GetFeedEntries(ctx, feed.ID, &EntryOptions{PerPage: Int(2)})
+	if err != nil {
+		t.Fatalf("GetFeedEntries() error = %v", err)
+	}
+	if pagination.Total != 5 {
+		t.Errorf("Expected total 5, got %d", pagination.Total)
+	}
+
+	seen := len(entries)
+	for pagination.HasNext() {
+		entries, pagination, err = client.GetEntriesFromURL(ctx, pagination.Next)
+		if err != nil {
+			t.Fatalf("GetEntriesFromURL() error = %v", err)
+		}
+		seen += len(entries)
+	}
+	if seen != 5 {
+		t.Errorf("Expected to page through 5 entries, got %d", seen)
+	}
+}
+
+func TestFakeServerForbidden(t *testing.T) {
+	client, srv := newFakeClient(t)
+	srv.AddUser("other@example.com", "password")
+
+	other := NewClientWithHTTPClient("other@example.com", "password", srv.Client())
+	other.SetBaseURL(srv.URL)
+
+	srv.AddFeed(feedbintest.Feed{Title: "Example", FeedURL: "https://example.com/feed.xml"})
+	sub, _, err := client.CreateSubscription(context.Background(), "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+
+	_, err = other.GetSubscription(context.Background(), sub.ID)
+	apiErr, ok := err.(*APIError)
+	if !ok || !apiErr.IsForbidden() {
+		t.Errorf("Expected forbidden APIError, got %v", err)
+	}
+}